@@ -30,7 +30,7 @@ var (
 
 // ISequencerInboxMetaData contains all meta data concerning the ISequencerInbox contract.
 var ISequencerInboxMetaData = &bind.MetaData{
-	ABI: "[{\"inputs\":[],\"name\":\"EmptyBatch\",\"type\":\"error\"},{\"inputs\":[],\"name\":\"ProofVerificationFailed\",\"type\":\"error\"},{\"inputs\":[],\"name\":\"TxBatchDataOverflow\",\"type\":\"error\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"batchNumber\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"startTxNumber\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"endTxNumber\",\"type\":\"uint256\"}],\"name\":\"TxBatchAppended\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256[]\",\"name\":\"contexts\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[]\",\"name\":\"txLengths\",\"type\":\"uint256[]\"},{\"internalType\":\"bytes\",\"name\":\"txBatch\",\"type\":\"bytes\"}],\"name\":\"appendTxBatch\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getInboxSize\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes\",\"name\":\"encodedTx\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"proof\",\"type\":\"bytes\"}],\"name\":\"verifyTxInclusion\",\"outputs\":[],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	ABI: "[{\"inputs\":[],\"name\":\"EmptyBatch\",\"type\":\"error\"},{\"inputs\":[],\"name\":\"ProofVerificationFailed\",\"type\":\"error\"},{\"inputs\":[],\"name\":\"TxBatchDataOverflow\",\"type\":\"error\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"batchNumber\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"startTxNumber\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"endTxNumber\",\"type\":\"uint256\"}],\"name\":\"TxBatchAppended\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"batchNumber\",\"type\":\"uint256\"}],\"name\":\"accumulators\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256[]\",\"name\":\"contexts\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[]\",\"name\":\"txLengths\",\"type\":\"uint256[]\"},{\"internalType\":\"bytes\",\"name\":\"txBatch\",\"type\":\"bytes\"}],\"name\":\"appendTxBatch\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getInboxSize\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes\",\"name\":\"encodedTx\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"proof\",\"type\":\"bytes\"}],\"name\":\"verifyTxInclusion\",\"outputs\":[],\"stateMutability\":\"view\",\"type\":\"function\"}]",
 }
 
 // ISequencerInboxABI is the input ABI used to generate the binding from.
@@ -179,6 +179,37 @@ func (_ISequencerInbox *ISequencerInboxTransactorRaw) Transact(opts *bind.Transa
 	return _ISequencerInbox.Contract.contract.Transact(opts, method, params...)
 }
 
+// Accumulators is a free data retrieval call binding the contract method 0x1f67994e.
+//
+// Solidity: function accumulators(uint256 batchNumber) view returns(bytes32)
+func (_ISequencerInbox *ISequencerInboxCaller) Accumulators(opts *bind.CallOpts, batchNumber *big.Int) ([32]byte, error) {
+	var out []interface{}
+	err := _ISequencerInbox.contract.Call(opts, &out, "accumulators", batchNumber)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// Accumulators is a free data retrieval call binding the contract method 0x1f67994e.
+//
+// Solidity: function accumulators(uint256 batchNumber) view returns(bytes32)
+func (_ISequencerInbox *ISequencerInboxSession) Accumulators(batchNumber *big.Int) ([32]byte, error) {
+	return _ISequencerInbox.Contract.Accumulators(&_ISequencerInbox.CallOpts, batchNumber)
+}
+
+// Accumulators is a free data retrieval call binding the contract method 0x1f67994e.
+//
+// Solidity: function accumulators(uint256 batchNumber) view returns(bytes32)
+func (_ISequencerInbox *ISequencerInboxCallerSession) Accumulators(batchNumber *big.Int) ([32]byte, error) {
+	return _ISequencerInbox.Contract.Accumulators(&_ISequencerInbox.CallOpts, batchNumber)
+}
+
 // GetInboxSize is a free data retrieval call binding the contract method 0x29869a7f.
 //
 // Solidity: function getInboxSize() view returns(uint256)
@@ -15,5 +15,7 @@
 package bindings
 
 //go:generate go run github.com/ethereum/go-ethereum/cmd/abigen --abi ../../../../contracts/abi/src/challenge/IChallenge.sol/ISymChallenge.json --type ISymChallenge --pkg bindings --out ISymChallenge.go
+// VerificationContextLibRawContext is declared in ISymChallenge.go; remove it from IAsymChallenge.go after generating.
+//go:generate go run github.com/ethereum/go-ethereum/cmd/abigen --abi ../../../../contracts/abi/src/challenge/IChallenge.sol/IAsymChallenge.json --type IAsymChallenge --pkg bindings --out IAsymChallenge.go
 //go:generate go run github.com/ethereum/go-ethereum/cmd/abigen --abi ../../../../contracts/abi/src/IRollup.sol/IRollup.json --pkg bindings --type IRollup --out IRollup.go
 //go:generate go run github.com/ethereum/go-ethereum/cmd/abigen --abi ../../../../contracts/abi/src/ISequencerInbox.sol/ISequencerInbox.json --pkg bindings --type ISequencerInbox --out ISequencerInbox.go
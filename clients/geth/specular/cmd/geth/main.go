@@ -220,6 +220,11 @@ var (
 		specularUtils.RollupRollupAddrFlag,
 		specularUtils.RollupRollupStakeAmount,
 		specularUtils.RollupL1RollupGenesisBlock,
+		specularUtils.RollupMaxBatchSizeFlag,
+		specularUtils.RollupMaxBatchGasFlag,
+		specularUtils.RollupL1ConfirmationsFlag,
+		specularUtils.RollupL1ResubmitTimeoutFlag,
+		specularUtils.RollupChallengeTypeFlag,
 	}
 	// <specular modification/>
 )
@@ -22,6 +22,8 @@
 package utils
 
 import (
+	"time"
+
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/eth"
@@ -34,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/specularl2/specular/clients/geth/specular/internal/ethapi"
 	"github.com/specularl2/specular/clients/geth/specular/proof"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
 	rollup "github.com/specularl2/specular/clients/geth/specular/rollup/services"
 	"github.com/urfave/cli/v2"
 )
@@ -86,6 +89,31 @@ var (
 		Usage: "Required staking amount",
 		Value: 1000000000000000000,
 	}
+	RollupMaxBatchSizeFlag = &cli.Uint64Flag{
+		Name:  "rollup.max-batch-size",
+		Usage: "Max calldata size in bytes of a batch sent to L1 sequencer inbox (0 for no limit)",
+		Value: 120000,
+	}
+	RollupMaxBatchGasFlag = &cli.Uint64Flag{
+		Name:  "rollup.max-batch-gas",
+		Usage: "Max estimated L1 gas of a batch sent to L1 sequencer inbox (0 for no limit)",
+		Value: 15000000,
+	}
+	RollupL1ConfirmationsFlag = &cli.Uint64Flag{
+		Name:  "rollup.l1-confirmations",
+		Usage: "Number of L1 confirmations to wait for on sent L1 transactions",
+		Value: 1,
+	}
+	RollupL1ResubmitTimeoutFlag = &cli.DurationFlag{
+		Name:  "rollup.l1-resubmit-timeout",
+		Usage: "Time to wait for an L1 transaction to be included before re-broadcasting it with bumped fees",
+		Value: 48 * time.Second,
+	}
+	RollupChallengeTypeFlag = &cli.StringFlag{
+		Name:  "rollup.challenge-type",
+		Usage: "Challenge protocol (sym or asym) assumed for L1 challenge contracts that do not report their type",
+		Value: "sym",
+	}
 	// <specular modification/>
 )
 
@@ -142,6 +170,10 @@ func MakeRollupConfig(ctx *cli.Context) *rollup.Config {
 	if sequencerAddr == (common.Address{}) {
 		utils.Fatalf("Failed to register the Rollup service: sequencer address not specified")
 	}
+	challengeType, err := client.ParseChallengeType(ctx.String(RollupChallengeTypeFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to register the Rollup service: %v", err)
+	}
 	cfg := &rollup.Config{
 		Node:                 node,
 		Coinbase:             coinbase,
@@ -153,6 +185,11 @@ func MakeRollupConfig(ctx *cli.Context) *rollup.Config {
 		RollupAddr:           common.HexToAddress(ctx.String(RollupRollupAddrFlag.Name)),
 		L1RollupGenesisBlock: ctx.Uint64(RollupL1RollupGenesisBlock.Name),
 		RollupStakeAmount:    ctx.Uint64(RollupRollupStakeAmount.Name),
+		MaxBatchSize:         ctx.Uint64(RollupMaxBatchSizeFlag.Name),
+		MaxBatchGas:          ctx.Uint64(RollupMaxBatchGasFlag.Name),
+		L1Confirmations:      ctx.Uint64(RollupL1ConfirmationsFlag.Name),
+		L1ResubmitTimeout:    ctx.Duration(RollupL1ResubmitTimeoutFlag.Name),
+		ChallengeType:        challengeType,
 	}
 	return cfg
 }
@@ -16,6 +16,7 @@ package proof
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -27,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
 )
 
 // Backend interface provides the common API services (that are provided by
@@ -48,6 +50,13 @@ type Backend interface {
 	StateAtTransaction(ctx context.Context, block *types.Block, txIndex int, reexec uint64) (core.Message, vm.BlockContext, *state.StateDB, error)
 }
 
+const (
+	// maxGenerateStatesBlocks is the maximum number of blocks whose states are returned by `GenerateStates`.
+	maxGenerateStatesBlocks = 64
+	// maxGenerateStatesSteps is the maximum number of states returned by `GenerateStates`.
+	maxGenerateStatesSteps = 1 << 20
+)
+
 // ProverAPI is the collection of Specular one-step proof APIs.
 type ProverAPI struct {
 	backend Backend
@@ -86,8 +95,66 @@ func createChainContext(backend Backend, ctx context.Context) core.ChainContext
 	return &chainContext{backend: backend, ctx: ctx}
 }
 
+// ProveTransaction returns the one-step proof of the step of transaction `hash`
+// starting from the state with hash `target`.
 func (api *ProverAPI) ProveTransaction(ctx context.Context, hash common.Hash, target common.Hash, config *ProverConfig) (hexutil.Bytes, error) {
-	return hexutil.Bytes{}, nil
+	tx, blockHash, _, index, err := api.backend.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %s not found", hash)
+	}
+	block, err := api.backend.BlockByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", blockHash)
+	}
+	startState, err := FindState(api.backend, ctx, block, index, target, config)
+	if err != nil {
+		return nil, err
+	}
+	osp, err := GenerateProof(api.backend, ctx, startState, config)
+	if err != nil {
+		return nil, err
+	}
+	return osp.Encode(), nil
+}
+
+// GenerateStates returns the execution states across blocks [blockStart, blockEnd).
+// The range is bounded to `maxGenerateStatesBlocks` blocks and `maxGenerateStatesSteps` states.
+func (api *ProverAPI) GenerateStates(ctx context.Context, blockStart, blockEnd uint64, config *ProverConfig) ([]json.RawMessage, error) {
+	if blockStart == 0 || blockEnd <= blockStart {
+		return nil, fmt.Errorf("invalid block range [%d, %d)", blockStart, blockEnd)
+	}
+	if blockEnd-blockStart > maxGenerateStatesBlocks {
+		return nil, fmt.Errorf("block range [%d, %d) exceeds %d blocks", blockStart, blockEnd, maxGenerateStatesBlocks)
+	}
+	var (
+		encoded   []json.RawMessage
+		encodeErr error
+	)
+	err := WalkStates(api.backend, ctx, blockStart, blockEnd, config, func(i uint64, s *ExecutionState) bool {
+		if i >= maxGenerateStatesSteps {
+			encodeErr = fmt.Errorf("block range [%d, %d) exceeds %d states", blockStart, blockEnd, maxGenerateStatesSteps)
+			return false
+		}
+		var state json.RawMessage
+		if state, encodeErr = s.MarshalJson(); encodeErr != nil {
+			return false
+		}
+		encoded = append(encoded, state)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if encodeErr != nil {
+		return nil, encodeErr
+	}
+	return encoded, nil
 }
 
 // APIs return the collection of RPC services the tracer package offers.
@@ -0,0 +1,221 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	testKey, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr     = crypto.PubkeyToAddress(testKey.PublicKey)
+	testContract = common.HexToAddress("0x3000000000000000000000000000000000000003")
+	// PUSH1 0x2a PUSH1 0 SSTORE PUSH1 1 PUSH1 0 MSTORE STOP
+	testCode = common.FromHex("0x602a60005560016000526000")
+)
+
+// Backend serving a local chain, replaying blocks like `eth.EthAPIBackend`.
+type testBackend struct {
+	db    ethdb.Database
+	chain *core.BlockChain
+}
+
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.chain.GetHeaderByHash(hash), nil
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.chain.GetBlockByHash(hash), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	return b.chain.GetBlockByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(b.db, txHash)
+	return tx, blockHash, blockNumber, index, nil
+}
+
+func (b *testBackend) RPCGasCap() uint64                { return 50_000_000 }
+func (b *testBackend) ChainConfig() *params.ChainConfig { return b.chain.Config() }
+func (b *testBackend) Engine() consensus.Engine         { return b.chain.Engine() }
+func (b *testBackend) ChainDb() ethdb.Database          { return b.db }
+
+func (b *testBackend) StateAtBlock(ctx context.Context, block *types.Block, reexec uint64, base *state.StateDB, checkLive, preferDisk bool) (*state.StateDB, error) {
+	return b.chain.StateAt(block.Root())
+}
+
+func (b *testBackend) StateAtTransaction(ctx context.Context, block *types.Block, txIndex int, reexec uint64) (core.Message, vm.BlockContext, *state.StateDB, error) {
+	parent := b.chain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	statedb, err := b.chain.StateAt(parent.Root())
+	if err != nil {
+		return nil, vm.BlockContext{}, nil, err
+	}
+	config := b.chain.Config()
+	signer := types.MakeSigner(config, block.Number())
+	blockCtx := core.NewEVMBlockContext(block.Header(), b.chain, nil)
+	for idx, tx := range block.Transactions() {
+		msg, _ := tx.AsMessage(signer, block.BaseFee())
+		if idx == txIndex {
+			return msg, blockCtx, statedb, nil
+		}
+		vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, config, vm.Config{})
+		statedb.Prepare(tx.Hash(), idx)
+		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas())); err != nil {
+			return nil, vm.BlockContext{}, nil, err
+		}
+		statedb.Finalise(config.IsEIP158(block.Number()))
+	}
+	return nil, vm.BlockContext{}, nil, fmt.Errorf("transaction index %d out of range", txIndex)
+}
+
+// Returns a backend over a chain whose block #1 calls `testContract` twice.
+func newTestBackend(t *testing.T) *testBackend {
+	config := params.TestChainConfig
+	genesis := &core.Genesis{
+		Config:   config,
+		GasLimit: params.GenesisGasLimit,
+		Alloc: core.GenesisAlloc{
+			testAddr:     {Balance: big.NewInt(params.Ether)},
+			testContract: {Code: testCode, Balance: common.Big0},
+		},
+	}
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	t.Cleanup(chain.Stop)
+	signer := types.LatestSigner(config)
+	blocks, _ := core.GenerateChain(config, chain.Genesis(), ethash.NewFaker(), db, 1, func(i int, gen *core.BlockGen) {
+		for nonce := uint64(0); nonce < 2; nonce++ {
+			tx := types.NewTx(&types.LegacyTx{
+				Nonce:    nonce,
+				GasPrice: big.NewInt(10 * params.GWei),
+				Gas:      100_000,
+				To:       &testContract,
+			})
+			signed, err := types.SignTx(tx, signer, testKey)
+			if err != nil {
+				t.Fatalf("failed to sign tx: %v", err)
+			}
+			gen.AddTx(signed)
+		}
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return &testBackend{db: db, chain: chain}
+}
+
+// Every state of a transaction must be found by its hash, and proven from it.
+func TestProveTransaction(t *testing.T) {
+	backend := newTestBackend(t)
+	api := NewAPI(backend)
+	ctx := context.Background()
+	states, err := GenerateStates(backend, ctx, 1, 2, nil)
+	if err != nil {
+		t.Fatalf("failed to generate states: %v", err)
+	}
+	block := backend.chain.GetBlockByNumber(1)
+	tx := block.Transactions()[1]
+	var proven int
+	var provenStart bool
+	for _, s := range states {
+		if s.TransactionIdx != 1 || s.Block.Hash() != block.Hash() {
+			continue
+		}
+		found, err := FindState(backend, ctx, block, 1, s.VMHash, nil)
+		if err != nil {
+			t.Fatalf("step %d: failed to find state: %v", s.StepIdx, err)
+		}
+		if found.StepIdx != s.StepIdx || found.TransactionIdx != 1 {
+			t.Fatalf("step %d: found tx %d, step %d", s.StepIdx, found.TransactionIdx, found.StepIdx)
+		}
+		// Step 0 (the inter-state before the tx) is proven by a `TransactionStartProof`.
+		osp, err := GenerateProof(backend, ctx, s, nil)
+		if err != nil {
+			t.Fatalf("step %d: failed to generate proof: %v", s.StepIdx, err)
+		}
+		encoded, err := api.ProveTransaction(ctx, tx.Hash(), s.VMHash, nil)
+		if err != nil {
+			t.Fatalf("step %d: failed to prove transaction: %v", s.StepIdx, err)
+		}
+		if !bytes.Equal(encoded, osp.Encode()) {
+			t.Fatalf("step %d: proof mismatch", s.StepIdx)
+		}
+		provenStart = provenStart || s.StepIdx == 0
+		proven++
+	}
+	if !provenStart || proven < 2 {
+		t.Fatalf("proved %d states of tx, including step 0: %v", proven, provenStart)
+	}
+}
+
+func TestProveTransactionErrors(t *testing.T) {
+	backend := newTestBackend(t)
+	api := NewAPI(backend)
+	ctx := context.Background()
+	block := backend.chain.GetBlockByNumber(1)
+	tx := block.Transactions()[0]
+
+	if _, err := FindState(backend, ctx, block, 0, common.HexToHash("0x01"), nil); err == nil {
+		t.Error("unknown state found")
+	}
+	if _, err := FindState(backend, ctx, block, 2, block.Root(), nil); err == nil {
+		t.Error("state found in out-of-range transaction")
+	}
+	if _, err := api.ProveTransaction(ctx, tx.Hash(), common.HexToHash("0x01"), nil); err == nil {
+		t.Error("proved unknown state")
+	}
+	if _, err := api.ProveTransaction(ctx, common.HexToHash("0x02"), block.Root(), nil); err == nil {
+		t.Error("proved unknown transaction")
+	}
+	for _, r := range [][2]uint64{{0, 1}, {1, 1}, {2, 1}} {
+		if _, err := api.GenerateStates(ctx, r[0], r[1], nil); err == nil {
+			t.Errorf("generated states of invalid range [%d, %d)", r[0], r[1])
+		}
+	}
+	// States end at the last block.
+	if _, err := api.GenerateStates(ctx, 1, 3, nil); err == nil {
+		t.Error("generated states beyond the chain head")
+	}
+	if _, err := api.GenerateStates(ctx, 1, 2+maxGenerateStatesBlocks, nil); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("generated states of more than %d blocks, err: %v", maxGenerateStatesBlocks, err)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// paddedTrace is an execution trace adapted to a challenge length committed by the other party.
+type paddedTrace struct {
+	trace  ExecutionTrace
+	length uint64
+}
+
+// PadTrace adapts `trace` to a challenge of `numSteps` steps, for when the other party of the challenge
+// committed to a different trace length: a longer trace is truncated, and a shorter one is padded by
+// repeating its end state, which no further step changes (see `GenerateProof`).
+// `numSteps` must be less than math.MaxUint64, so that the number of states fits in a uint64.
+func PadTrace(trace ExecutionTrace, numSteps uint64) ExecutionTrace {
+	if trace.Len() == numSteps+1 {
+		return trace
+	}
+	return &paddedTrace{trace: trace, length: numSteps + 1}
+}
+
+func (t *paddedTrace) Len() uint64 {
+	return t.length
+}
+
+func (t *paddedTrace) State(ctx context.Context, i uint64) (*ExecutionState, error) {
+	if i >= t.length {
+		return nil, fmt.Errorf("state index %d out of range [0, %d)", i, t.length)
+	}
+	return t.trace.State(ctx, t.index(i))
+}
+
+func (t *paddedTrace) Hash(ctx context.Context, i uint64) (common.Hash, error) {
+	if i >= t.length {
+		return common.Hash{}, fmt.Errorf("state index %d out of range [0, %d)", i, t.length)
+	}
+	return t.trace.Hash(ctx, t.index(i))
+}
+
+// Maps a state index of the padded trace to the underlying trace.
+func (t *paddedTrace) index(i uint64) uint64 {
+	if end := t.trace.Len() - 1; i > end {
+		return end
+	}
+	return i
+}
@@ -0,0 +1,116 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// Execution trace held in memory.
+type sliceTrace []*ExecutionState
+
+func (t sliceTrace) Len() uint64 { return uint64(len(t)) }
+
+func (t sliceTrace) State(ctx context.Context, i uint64) (*ExecutionState, error) {
+	if i >= t.Len() {
+		return nil, fmt.Errorf("state index %d out of range [0, %d)", i, t.Len())
+	}
+	return t[i], nil
+}
+
+func (t sliceTrace) Hash(ctx context.Context, i uint64) (common.Hash, error) {
+	s, err := t.State(ctx, i)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return s.Hash(), nil
+}
+
+// Returns a trace of `numSteps` steps, whose states hash to their index.
+func newSliceTrace(numSteps uint64) sliceTrace {
+	trace := make(sliceTrace, numSteps+1)
+	for i := range trace {
+		trace[i] = &ExecutionState{VMHash: common.BigToHash(new(big.Int).SetUint64(uint64(i)))}
+	}
+	return trace
+}
+
+func TestPadTrace(t *testing.T) {
+	ctx := context.Background()
+	trace := newSliceTrace(4)
+	tests := []struct {
+		name     string
+		numSteps uint64
+		// Index of the state of `trace` expected at each index of the padded trace
+		want []uint64
+	}{
+		{"same length", 4, []uint64{0, 1, 2, 3, 4}},
+		{"truncated", 2, []uint64{0, 1, 2}},
+		{"single step", 1, []uint64{0, 1}},
+		{"padded", 6, []uint64{0, 1, 2, 3, 4, 4, 4}},
+	}
+	for _, tt := range tests {
+		padded := PadTrace(trace, tt.numSteps)
+		if padded.Len() != uint64(len(tt.want)) {
+			t.Errorf("%s: have %d states, want %d", tt.name, padded.Len(), len(tt.want))
+			continue
+		}
+		for i, j := range tt.want {
+			hash, err := padded.Hash(ctx, uint64(i))
+			if err != nil {
+				t.Fatalf("%s: failed to get hash %d: %v", tt.name, i, err)
+			}
+			state, err := padded.State(ctx, uint64(i))
+			if err != nil {
+				t.Fatalf("%s: failed to get state %d: %v", tt.name, i, err)
+			}
+			if hash != trace[j].VMHash || state != trace[j] {
+				t.Errorf("%s: state %d is not state %d of the trace", tt.name, i, j)
+			}
+		}
+		if _, err := padded.Hash(ctx, padded.Len()); err == nil {
+			t.Errorf("%s: got hash past the end", tt.name)
+		}
+		if _, err := padded.State(ctx, padded.Len()); err == nil {
+			t.Errorf("%s: got state past the end", tt.name)
+		}
+	}
+	if _, ok := PadTrace(trace, 4).(sliceTrace); !ok {
+		t.Error("trace of the committed length wrapped")
+	}
+}
+
+// A challenge of the maximum length is padded without overflowing.
+func TestPadTraceMaxLength(t *testing.T) {
+	ctx := context.Background()
+	trace := newSliceTrace(2)
+	padded := PadTrace(trace, math.MaxUint64-1)
+	if padded.Len() != math.MaxUint64 {
+		t.Fatalf("have %d states, want %d", padded.Len(), uint64(math.MaxUint64))
+	}
+	hash, err := padded.Hash(ctx, math.MaxUint64-1)
+	if err != nil {
+		t.Fatalf("failed to get last hash: %v", err)
+	}
+	if hash != trace[2].VMHash {
+		t.Fatal("last state is not the end state of the trace")
+	}
+}
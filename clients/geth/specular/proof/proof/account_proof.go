@@ -0,0 +1,119 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// AccountProof proves an account in the world state trie.
+// Encoding: address (20 bytes) || nonce (uint64) || balance (uint256) || storage root (bytes32) ||
+// code hash (bytes32) || Merkle-Patricia proof (see `encodeNodes`).
+// The code hash is zero for accounts that do not exist.
+type AccountProof struct {
+	Address     common.Address
+	Nonce       uint64
+	Balance     *uint256.Int
+	StorageRoot common.Hash
+	CodeHash    common.Hash
+	Nodes       [][]byte
+}
+
+func NewAccountProof(statedb *state.StateDB, address common.Address) (*AccountProof, error) {
+	nodes, err := statedb.GetProof(address)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get account proof of %s, err: %w", address, err)
+	}
+	storageRoot := types.EmptyRootHash
+	if storageTrie := statedb.StorageTrie(address); storageTrie != nil {
+		storageRoot = storageTrie.Hash()
+	}
+	balance, _ := uint256.FromBig(statedb.GetBalance(address))
+	return &AccountProof{
+		Address:     address,
+		Nonce:       statedb.GetNonce(address),
+		Balance:     balance,
+		StorageRoot: storageRoot,
+		CodeHash:    statedb.GetCodeHash(address),
+		Nodes:       nodes,
+	}, nil
+}
+
+func (p *AccountProof) Encode() []byte {
+	encoded := make([]byte, 0, 20+8+32+32+32)
+	encoded = append(encoded, p.Address[:]...)
+	encoded = binary.BigEndian.AppendUint64(encoded, p.Nonce)
+	balance := p.Balance.Bytes32()
+	encoded = append(encoded, balance[:]...)
+	encoded = append(encoded, p.StorageRoot[:]...)
+	encoded = append(encoded, p.CodeHash[:]...)
+	return append(encoded, encodeNodes(p.Nodes)...)
+}
+
+// StorageProof proves a storage slot in an account's storage trie (proven by a preceding `AccountProof`).
+// Encoding: key (bytes32) || value (bytes32) || Merkle-Patricia proof (see `encodeNodes`).
+type StorageProof struct {
+	Key   common.Hash
+	Value common.Hash
+	Nodes [][]byte
+}
+
+func NewStorageProof(statedb *state.StateDB, address common.Address, key common.Hash) (*StorageProof, error) {
+	nodes, err := statedb.GetStorageProof(address, key)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get storage proof of %s at %s, err: %w", address, key, err)
+	}
+	return &StorageProof{
+		Key:   key,
+		Value: statedb.GetState(address, key),
+		Nodes: nodes,
+	}, nil
+}
+
+func (p *StorageProof) Encode() []byte {
+	encoded := make([]byte, 0, 32+32)
+	encoded = append(encoded, p.Key[:]...)
+	encoded = append(encoded, p.Value[:]...)
+	return append(encoded, encodeNodes(p.Nodes)...)
+}
+
+// CodeProof reveals the code of an account (proven by a preceding `AccountProof`),
+// so that the verifier can check it against the code hash and commit to it in a new call frame.
+// Encoding: size (uint64) || code.
+type CodeProof struct {
+	Code []byte
+}
+
+func (p *CodeProof) Encode() []byte {
+	encoded := binary.BigEndian.AppendUint64(nil, uint64(len(p.Code)))
+	return append(encoded, p.Code...)
+}
+
+// Encodes Merkle-Patricia trie nodes, root first, as:
+// number of nodes (uint64) || (node size (uint64) || RLP-encoded node) for each node.
+func encodeNodes(nodes [][]byte) []byte {
+	encoded := binary.BigEndian.AppendUint64(nil, uint64(len(nodes)))
+	for _, node := range nodes {
+		encoded = binary.BigEndian.AppendUint64(encoded, uint64(len(node)))
+		encoded = append(encoded, node...)
+	}
+	return encoded
+}
@@ -0,0 +1,113 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/specularl2/specular/clients/geth/specular/proof/state"
+)
+
+// MerkleProof proves a word of data committed by a `state.MerkleTree`.
+// Encoding: index (uint64) || word (bytes32) || number of siblings (uint8) || siblings (bytes32 each, leaves up).
+type MerkleProof struct {
+	Index    uint64
+	Word     common.Hash
+	Siblings []common.Hash
+}
+
+func (p *MerkleProof) Encode() []byte {
+	encoded := make([]byte, 0, 8+32+1+32*len(p.Siblings))
+	encoded = binary.BigEndian.AppendUint64(encoded, p.Index)
+	encoded = append(encoded, p.Word[:]...)
+	encoded = append(encoded, byte(len(p.Siblings)))
+	for _, sibling := range p.Siblings {
+		encoded = append(encoded, sibling[:]...)
+	}
+	return encoded
+}
+
+// DataProof proves the words of a byte range of memory, code, input or return data.
+// Only words within the committed data are proven; words past its end are zero.
+// Encoding: number of words (uint64) || `MerkleProof`s in increasing index order.
+//
+// For writes, each word is proven against the root obtained after writing the previous ones,
+// so that the verifier can apply them one by one.
+type DataProof struct {
+	Words []*MerkleProof
+}
+
+func (p *DataProof) Encode() []byte {
+	encoded := binary.BigEndian.AppendUint64(nil, uint64(len(p.Words)))
+	for _, word := range p.Words {
+		encoded = append(encoded, word.Encode()...)
+	}
+	return encoded
+}
+
+// NewDataReadProof proves the words of `tree` covering bytes [start, end).
+func NewDataReadProof(tree *state.MerkleTree, start, end uint64) *DataProof {
+	proof := &DataProof{}
+	first, last := wordRange(tree, start, end)
+	for index := first; index < last; index++ {
+		proof.Words = append(proof.Words, &MerkleProof{
+			Index:    index,
+			Word:     tree.Leaf(index),
+			Siblings: tree.Proof(index),
+		})
+	}
+	return proof
+}
+
+// NewDataWriteProof proves the words of `tree` covering bytes [start, end), and writes
+// byte `i` of the range with `value(i)`. `tree` is updated.
+func NewDataWriteProof(tree *state.MerkleTree, start, end uint64, value func(uint64) byte) *DataProof {
+	proof := &DataProof{}
+	first, last := wordRange(tree, start, end)
+	for index := first; index < last; index++ {
+		word := tree.Leaf(index)
+		proof.Words = append(proof.Words, &MerkleProof{
+			Index:    index,
+			Word:     word,
+			Siblings: tree.Proof(index),
+		})
+		for i := uint64(0); i < state.WordSize; i++ {
+			if offset := index*state.WordSize + i; offset >= start && offset < end {
+				word[i] = value(offset - start)
+			}
+		}
+		tree.Update(index, word)
+	}
+	return proof
+}
+
+// Returns the range of word indices of `tree` covering bytes [start, end).
+func wordRange(tree *state.MerkleTree, start, end uint64) (uint64, uint64) {
+	if start >= end {
+		return 0, 0
+	}
+	first, last := start/state.WordSize, state.NumWords(end)
+	if end > ^uint64(0)-state.WordSize {
+		last = end / state.WordSize
+	}
+	if last > tree.NumWords() {
+		last = tree.NumWords()
+	}
+	if first > last {
+		first = last
+	}
+	return first, last
+}
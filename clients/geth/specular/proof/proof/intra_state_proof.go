@@ -0,0 +1,27 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import "github.com/specularl2/specular/clients/geth/specular/proof/state"
+
+// IntraStateProof reveals the state before a step, or the state of the caller frame at a call.
+// Encoding: `state.IntraState.Encode` (`state.IntraStateSize` bytes).
+type IntraStateProof struct {
+	State *state.IntraState
+}
+
+func (p *IntraStateProof) Encode() []byte {
+	return p.State.Encode()
+}
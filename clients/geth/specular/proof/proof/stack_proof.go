@@ -0,0 +1,58 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+	"github.com/specularl2/specular/clients/geth/specular/proof/state"
+)
+
+// StackProof proves the top items of the stack consumed by a step.
+// Encoding: number of items (uint8) || hash of the rest of the stack (bytes32) || items (uint256 each, top first).
+type StackProof struct {
+	// Hash of the stack below the items
+	RemainingHash common.Hash
+	Items         []uint256.Int
+}
+
+// NewStackProof proves the top `n` items of `stack`.
+func NewStackProof(stack *vm.Stack, n int) *StackProof {
+	data := stack.Data()
+	if n > len(data) {
+		// Stack underflow, prove what is there.
+		n = len(data)
+	}
+	proof := &StackProof{
+		RemainingHash: state.StackHash(data[:len(data)-n]),
+		Items:         make([]uint256.Int, n),
+	}
+	for i := 0; i < n; i++ {
+		proof.Items[i] = data[len(data)-1-i]
+	}
+	return proof
+}
+
+func (p *StackProof) Encode() []byte {
+	encoded := make([]byte, 0, 1+32+32*len(p.Items))
+	encoded = append(encoded, byte(len(p.Items)))
+	encoded = append(encoded, p.RemainingHash[:]...)
+	for i := range p.Items {
+		item := p.Items[i].Bytes32()
+		encoded = append(encoded, item[:]...)
+	}
+	return encoded
+}
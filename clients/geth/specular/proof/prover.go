@@ -21,11 +21,13 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/specularl2/specular/clients/geth/specular/proof/proof"
 	"github.com/specularl2/specular/clients/geth/specular/proof/prover"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
 )
 
@@ -38,6 +40,8 @@ const (
 
 type ProverConfig struct {
 	Reexec *uint64
+	// Minimum number of steps between two checkpoints of a `StateIndex` (0 for the default)
+	CheckpointInterval uint64
 }
 
 type ExecutionState struct {
@@ -66,6 +70,16 @@ func (s *ExecutionState) Hash() common.Hash {
 	return s.VMHash
 }
 
+// ExecutionTrace is an indexed sequence of execution states, as generated by `GenerateStates`.
+type ExecutionTrace interface {
+	// Len returns the number of execution states.
+	Len() uint64
+	// State returns the `i`-th execution state.
+	State(ctx context.Context, i uint64) (*ExecutionState, error)
+	// Hash returns the VM hash of the `i`-th execution state.
+	Hash(ctx context.Context, i uint64) (common.Hash, error)
+}
+
 // This function generates execution states across blocks [startNum, endNum)
 // For example there are 2 transactions: a, b
 // The states are: inter-state before a, intra-states in a, inter-state before b (after a), intra-states in b, inter-state after b
@@ -76,86 +90,216 @@ func GenerateStates(
 	endNum uint64,
 	config *ProverConfig,
 ) ([]*ExecutionState, error) {
-	parent, err := backend.BlockByNumber(ctx, rpc.BlockNumber(startNum-1))
+	var states []*ExecutionState
+	err := WalkStates(backend, ctx, startNum, endNum, config, func(_ uint64, s *ExecutionState) bool {
+		states = append(states, s)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
+	return states, nil
+}
+
+// WalkStates passes the execution states across blocks [startNum, endNum) to `visit` in the order of
+// `GenerateStates`, without keeping them in memory. The walk stops early once `visit` returns false.
+func WalkStates(
+	backend Backend,
+	ctx context.Context,
+	startNum uint64,
+	endNum uint64,
+	config *ProverConfig,
+	visit func(index uint64, state *ExecutionState) bool,
+) error {
+	return walkStates(backend, ctx, rollupTypes.TraceCheckpoint{BlockNumber: startNum}, endNum, config, visit)
+}
+
+// walkStates re-executes blocks [from.BlockNumber, endNum) starting at the checkpoint `from`, passing every
+// execution state to `visit` in order. States are not retained, so memory use does not depend on the number
+// of steps. The walk stops early once `visit` returns false.
+func walkStates(
+	backend Backend,
+	ctx context.Context,
+	from rollupTypes.TraceCheckpoint,
+	endNum uint64,
+	config *ProverConfig,
+	visit func(index uint64, state *ExecutionState) bool,
+) error {
+	if from.BlockNumber == 0 || from.BlockNumber >= endNum {
+		return fmt.Errorf("invalid block range [%d, %d)", from.BlockNumber, endNum)
+	}
 	reexec := defaultProveReexec
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
 	}
-	statedb, err := backend.StateAtBlock(ctx, parent, reexec, nil, true, false)
+	block, err := blockByNumber(backend, ctx, from.BlockNumber)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	var (
-		states []*ExecutionState
-		block  *types.Block
-	)
-	for num := startNum; num < endNum; num++ {
-		block, err = backend.BlockByNumber(ctx, rpc.BlockNumber(num))
+	var statedb *state.StateDB
+	if from.TransactionIdx == 0 {
+		parent, err := blockByNumber(backend, ctx, from.BlockNumber-1)
+		if err != nil {
+			return err
+		}
+		statedb, err = backend.StateAtBlock(ctx, parent, reexec, nil, true, false)
+		if err != nil {
+			return err
+		}
+	} else {
+		_, _, statedb, err = backend.StateAtTransaction(ctx, block, int(from.TransactionIdx), reexec)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		if block == nil {
-			return nil, fmt.Errorf("block #%d not found", num)
+	}
+	index := from.Index
+	for num := from.BlockNumber; num < endNum; num++ {
+		if num != from.BlockNumber {
+			block, err = blockByNumber(backend, ctx, num)
+			if err != nil {
+				return err
+			}
 		}
+		deleteEmpty := backend.ChainConfig().IsEIP158(block.Number())
 		signer := types.MakeSigner(backend.ChainConfig(), block.Number())
 		blockCtx := core.NewEVMBlockContext(block.Header(), createChainContext(backend, ctx), nil)
+		txs := block.Transactions()
+		firstTx := 0
+		if num == from.BlockNumber {
+			firstTx = int(from.TransactionIdx)
+		}
 		// Trace all the transactions contained within
-		for i, tx := range block.Transactions() {
+		for i := firstTx; i < len(txs); i++ {
 			// Push inter-state hash
-			states = append(states, &ExecutionState{
-				VMHash:         statedb.IntermediateRoot(backend.ChainConfig().IsEIP158(block.Number())),
+			interState := &ExecutionState{
+				VMHash:         statedb.IntermediateRoot(deleteEmpty),
 				Block:          block,
 				TransactionIdx: uint64(i),
 				StepIdx:        0,
-			})
-			msg, _ := tx.AsMessage(signer, block.BaseFee())
+			}
+			if !visit(index, interState) {
+				return nil
+			}
+			index++
+			msg, _ := txs[i].AsMessage(signer, block.BaseFee())
 			txContext := core.NewEVMTxContext(msg)
-			prover := prover.NewStateGenerator()
+			var (
+				stepIdx = uint64(1)
+				stopped = false
+				txBlock = block
+				txIdx   = uint64(i)
+			)
+			generator := prover.NewStreamingStateGenerator(statedb, num, txIdx, deleteEmpty, func(s prover.GeneratedState) {
+				if stopped {
+					return
+				}
+				stopped = !visit(index, &ExecutionState{
+					VMHash:         s.VMHash,
+					Block:          txBlock,
+					TransactionIdx: txIdx,
+					StepIdx:        stepIdx,
+				})
+				index++
+				stepIdx++
+			})
 			// Run the transaction with tracing enabled.
-			vmenv := vm.NewEVM(blockCtx, txContext, statedb, backend.ChainConfig(), vm.Config{Debug: true, Tracer: prover, NoBaseFee: true})
+			vmenv := vm.NewEVM(blockCtx, txContext, statedb, backend.ChainConfig(), vm.Config{Debug: true, Tracer: generator, NoBaseFee: true})
 			// Call Prepare to clear out the statedb access list
-			statedb.Prepare(tx.Hash(), i)
+			statedb.Prepare(txs[i].Hash(), i)
 			_, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
 			if err != nil {
-				return nil, fmt.Errorf("tracing failed: %w", err)
+				return fmt.Errorf("tracing failed: %w", err)
 			}
-			generatedStates, err := prover.GetGeneratedStates()
-			if err != nil {
-				return nil, fmt.Errorf("tracing failed: %w", err)
-			}
-			for idx, s := range generatedStates {
-				states = append(states, &ExecutionState{
-					VMHash:         s.VMHash,
-					Block:          block,
-					TransactionIdx: uint64(i),
-					StepIdx:        uint64(idx + 1),
-				})
+			if stopped {
+				return nil
 			}
 		}
 		// Get next statedb if we are not at the last block
 		if num < endNum-1 {
 			statedb, err = backend.StateAtBlock(ctx, block, reexec, statedb, true, false)
 			if err != nil {
-				return nil, err
+				return err
 			}
 		}
 	}
-	states = append(states, &ExecutionState{
+	visit(index, &ExecutionState{
 		VMHash:         block.Root(),
 		Block:          block,
 		TransactionIdx: uint64(len(block.Transactions())),
 		StepIdx:        0,
 	})
-	return states, nil
+	return nil
+}
+
+func blockByNumber(backend Backend, ctx context.Context, num uint64) (*types.Block, error) {
+	block, err := backend.BlockByNumber(ctx, rpc.BlockNumber(num))
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", num)
+	}
+	return block, nil
+}
+
+// FindState returns the execution state with VM hash `target` in the transaction at `txIndex` in `block`.
+func FindState(
+	backend Backend,
+	ctx context.Context,
+	block *types.Block,
+	txIndex uint64,
+	target common.Hash,
+	config *ProverConfig,
+) (*ExecutionState, error) {
+	if txIndex >= uint64(len(block.Transactions())) {
+		return nil, fmt.Errorf("transaction index %d out of range", txIndex)
+	}
+	reexec := defaultProveReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	msg, vmctx, statedb, err := backend.StateAtTransaction(ctx, block, int(txIndex), reexec)
+	if err != nil {
+		return nil, err
+	}
+	state := &ExecutionState{VMHash: target, Block: block, TransactionIdx: txIndex}
+	deleteEmpty := backend.ChainConfig().IsEIP158(block.Number())
+	if statedb.Copy().IntermediateRoot(deleteEmpty) == target {
+		return state, nil
+	}
+	txContext := core.NewEVMTxContext(msg)
+	prover := prover.NewStateGenerator(statedb, block.NumberU64(), txIndex, deleteEmpty)
+	vmenv := vm.NewEVM(vmctx, txContext, statedb, backend.ChainConfig(), vm.Config{Debug: true, Tracer: prover, NoBaseFee: true})
+	statedb.Prepare(block.Transactions()[txIndex].Hash(), int(txIndex))
+	_, err = core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
+	if err != nil {
+		return nil, fmt.Errorf("tracing failed: %w", err)
+	}
+	generatedStates, err := prover.GetGeneratedStates()
+	if err != nil {
+		return nil, fmt.Errorf("tracing failed: %w", err)
+	}
+	for idx, s := range generatedStates {
+		if s.VMHash == target {
+			state.StepIdx = uint64(idx + 1)
+			return state, nil
+		}
+	}
+	return nil, fmt.Errorf("state %s not found in transaction %d of block #%d", target, txIndex, block.NumberU64())
 }
 
 func GenerateProof(backend Backend, ctx context.Context, startState *ExecutionState, config *ProverConfig) (*proof.OneStepProof, error) {
 	if startState.Block == nil {
 		return nil, fmt.Errorf("bad start state")
 	}
+	if startState.TransactionIdx == uint64(len(startState.Block.Transactions())) && startState.StepIdx == 0 {
+		// The end state of a trace, only stepped from in a padded trace (see `PadTrace`).
+		// The step leaves the state unchanged and needs no proof.
+		if startState.VMHash != startState.Block.Root() {
+			return nil, fmt.Errorf("bad start state")
+		}
+		return proof.EmptyProof(), nil
+	}
 	if startState.TransactionIdx >= uint64(len(startState.Block.Transactions())) {
 		return nil, fmt.Errorf("bad start state")
 	}
@@ -167,10 +311,23 @@ func GenerateProof(backend Backend, ctx context.Context, startState *ExecutionSt
 	if err != nil {
 		return nil, err
 	}
+	chainConfig := backend.ChainConfig()
+	deleteEmpty := chainConfig.IsEIP158(startState.Block.Number())
+	if startState.StepIdx == 0 {
+		// Proves the start of the transaction from the inter-state before it.
+		return prover.NewTransactionStartProof(startState.VMHash, statedb, msg, deleteEmpty)
+	}
 	txContext := core.NewEVMTxContext(msg)
-	prover := prover.NewProver(startState.VMHash, startState.StepIdx)
+	prover := prover.NewProver(
+		startState.VMHash,
+		startState.StepIdx,
+		statedb,
+		startState.Block.NumberU64(),
+		startState.TransactionIdx,
+		deleteEmpty,
+	)
 	// Run the transaction with tracing enabled.
-	vmenv := vm.NewEVM(vmctx, txContext, statedb, backend.ChainConfig(), vm.Config{Debug: true, Tracer: prover, NoBaseFee: true})
+	vmenv := vm.NewEVM(vmctx, txContext, statedb, chainConfig, vm.Config{Debug: true, Tracer: prover, NoBaseFee: true})
 	// Call Prepare to clear out the statedb access list
 	txHash := startState.Block.Transactions()[startState.TransactionIdx].Hash()
 	statedb.Prepare(txHash, int(startState.TransactionIdx))
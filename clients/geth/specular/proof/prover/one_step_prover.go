@@ -15,28 +15,49 @@
 package prover
 
 import (
+	"errors"
 	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	gethState "github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/specularl2/specular/clients/geth/specular/proof/proof"
+	"github.com/specularl2/specular/clients/geth/specular/proof/state"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
 )
 
-type PlaceHolderProof struct{}
-
-func (p *PlaceHolderProof) Encode() []byte {
-	return []byte{'p', 'r', 'o', 'o', 'f'}
-}
+var ErrStepNotReached = fmt.Errorf("target step not reached")
 
+// OneStepProver stops at step `step` of a transaction (the `step`-th `CaptureState`) and
+// proves its execution from the state with hash `target`. See `IVerifier.verifyOneStepProof`
+// for the layout of the proof, which the L1 `Verifier` contract does not decode yet (it rejects every proof).
 type OneStepProver struct {
 	// Config
 	target common.Hash
 	step   uint64
+
+	// Global
+	tracker *state.Tracker
+	env     *vm.EVM
+	counter uint64
+	// Proof of the step assuming it succeeds, until the step completes.
+	proof *proof.OneStepProof
+	// Proofs of the state, instruction and stack at the target step, for a fault while executing.
+	base    []proof.Proof
+	pending bool
+	done    bool
+	err     error
 }
 
-func NewProver(target common.Hash, step uint64) *OneStepProver {
-	return &OneStepProver{target: target, step: step}
+func NewProver(target common.Hash, step uint64, statedb *gethState.StateDB, blockNumber, txIndex uint64, deleteEmpty bool) *OneStepProver {
+	return &OneStepProver{
+		target:  target,
+		step:    step,
+		tracker: state.NewTracker(statedb, blockNumber, txIndex, deleteEmpty),
+	}
 }
 
 func (l *OneStepProver) CaptureTxStart(gasLimit uint64) {}
@@ -44,25 +65,396 @@ func (l *OneStepProver) CaptureTxStart(gasLimit uint64) {}
 func (l *OneStepProver) CaptureTxEnd(restGas uint64) {}
 
 func (l *OneStepProver) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	l.env = env
+	l.tracker.CaptureStart(env, from, to, create, input, value)
 }
 
 func (l *OneStepProver) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if l.done {
+		return
+	}
+	if l.pending {
+		// The target step completed without faulting.
+		l.finish()
+		return
+	}
+	l.counter++
+	st := l.tracker.CaptureState(pc, op, gas, scope, rData)
+	if l.counter < l.step {
+		return
+	}
+	if hash := st.Hash(); hash != l.target {
+		l.err = fmt.Errorf("State hash mismatch at step %d, expected %s, got %s", l.step, l.target, hash)
+		l.done = true
+		return
+	}
+	l.base = l.baseProofs(st, op, scope)
+	if err != nil {
+		// The step faults before executing (e.g. stack underflow, out of gas).
+		l.proof, l.err = l.faultProof()
+		l.done = true
+		return
+	}
+	l.proof, l.err = l.stepProof(op, scope)
+	if l.err != nil {
+		l.done = true
+		return
+	}
+	l.pending = true
 }
 
 func (l *OneStepProver) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if l.done {
+		return
+	}
+	if l.pending {
+		l.finish()
+		return
+	}
+	l.tracker.CaptureEnter(typ, from, to, input, value)
 }
 
 func (l *OneStepProver) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if l.done {
+		return
+	}
+	if l.pending {
+		l.finish()
+		return
+	}
+	l.tracker.CaptureExit()
 }
 
 func (l *OneStepProver) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	if l.done || !l.pending {
+		return
+	}
+	if errors.Is(err, vm.ErrExecutionReverted) {
+		// REVERT reports a fault, but is proven as a normal step.
+		l.finish()
+		return
+	}
+	// The step faults while executing (e.g. return data out of bounds), replace the proof.
+	// The stack and memory may have been modified.
+	l.proof, l.err = l.faultProof()
+	l.finish()
 }
 
 func (l *OneStepProver) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) {
+	if l.pending {
+		l.finish()
+	}
+}
+
+func (l *OneStepProver) finish() {
+	l.pending = false
+	l.done = true
 }
 
 func (l *OneStepProver) GetProof() (*proof.OneStepProof, error) {
-	proof := proof.EmptyProof()
-	proof.AddProof(&PlaceHolderProof{})
-	return proof, nil
+	if l.err != nil {
+		return nil, l.err
+	}
+	if l.proof == nil {
+		return nil, fmt.Errorf("Failed to prove step %d, err: %w", l.step, ErrStepNotReached)
+	}
+	return l.proof, nil
+}
+
+// Returns the proofs of the state, the executed instruction and the stack items it consumes.
+func (l *OneStepProver) baseProofs(st *state.IntraState, op vm.OpCode, scope *vm.ScopeContext) []proof.Proof {
+	frame := l.tracker.Frame()
+	return []proof.Proof{
+		&proof.IntraStateProof{State: st},
+		proof.NewDataReadProof(frame.CodeTree, st.Pc, st.Pc+1+pushSize(op)),
+		proof.NewStackProof(scope.Stack, stackPops(op)),
+	}
+}
+
+// Returns a new proof starting with the base proofs of the target step.
+func (l *OneStepProver) newProof() *proof.OneStepProof {
+	osp := proof.EmptyProof()
+	for _, p := range l.base {
+		osp.AddProof(p)
+	}
+	return osp
+}
+
+func (l *OneStepProver) faultProof() (*proof.OneStepProof, error) {
+	osp := l.newProof()
+	if err := l.addFrameEndProofs(osp, nil); err != nil {
+		return nil, err
+	}
+	return osp, nil
+}
+
+func (l *OneStepProver) stepProof(op vm.OpCode, scope *vm.ScopeContext) (*proof.OneStepProof, error) {
+	osp := l.newProof()
+	frame := l.tracker.Frame()
+	statedb := l.tracker.StateDB()
+	stack := scope.Stack
+	memory := scope.Memory.Data()
+	memoryTree := func() *state.MerkleTree { return state.NewMerkleTree(memory) }
+	// Proves a copy of `length` bytes of `data` at `dataOffset` to memory at `memOffset`.
+	copyProofs := func(data []byte, dataTree *state.MerkleTree, memOffset, dataOffset, length uint64) {
+		osp.AddProof(proof.NewDataReadProof(dataTree, dataOffset, saturatingAdd(dataOffset, length)))
+		osp.AddProof(proof.NewDataWriteProof(memoryTree(), memOffset, saturatingAdd(memOffset, length), func(i uint64) byte {
+			return dataByte(data, saturatingAdd(dataOffset, i))
+		}))
+	}
+	addAccountProof := func(address common.Address) error {
+		accountProof, err := proof.NewAccountProof(statedb, address)
+		if err != nil {
+			return err
+		}
+		osp.AddProof(accountProof)
+		return nil
+	}
+	addCodeProof := func(address common.Address) {
+		osp.AddProof(&proof.CodeProof{Code: statedb.GetCode(address)})
+	}
+
+	switch op {
+	case vm.CALLDATALOAD:
+		offset := stackUint64(stack, 0)
+		osp.AddProof(proof.NewDataReadProof(frame.InputTree, offset, saturatingAdd(offset, state.WordSize)))
+	case vm.CALLDATACOPY:
+		copyProofs(frame.Input, frame.InputTree, stackUint64(stack, 0), stackUint64(stack, 1), stackUint64(stack, 2))
+	case vm.CODECOPY:
+		copyProofs(frame.Code, frame.CodeTree, stackUint64(stack, 0), stackUint64(stack, 1), stackUint64(stack, 2))
+	case vm.RETURNDATACOPY:
+		returnData := l.tracker.ReturnData()
+		copyProofs(returnData, state.NewMerkleTree(returnData), stackUint64(stack, 0), stackUint64(stack, 1), stackUint64(stack, 2))
+	case vm.EXTCODECOPY:
+		address := stackAddress(stack, 0)
+		if err := addAccountProof(address); err != nil {
+			return nil, err
+		}
+		addCodeProof(address)
+		code := statedb.GetCode(address)
+		memOffset, codeOffset, length := stackUint64(stack, 1), stackUint64(stack, 2), stackUint64(stack, 3)
+		osp.AddProof(proof.NewDataWriteProof(memoryTree(), memOffset, saturatingAdd(memOffset, length), func(i uint64) byte {
+			return dataByte(code, saturatingAdd(codeOffset, i))
+		}))
+	case vm.MLOAD:
+		offset := stackUint64(stack, 0)
+		osp.AddProof(proof.NewDataReadProof(memoryTree(), offset, saturatingAdd(offset, state.WordSize)))
+	case vm.MSTORE:
+		offset, value := stackUint64(stack, 0), stack.Back(1).Bytes32()
+		osp.AddProof(proof.NewDataWriteProof(memoryTree(), offset, saturatingAdd(offset, state.WordSize), func(i uint64) byte {
+			return value[i]
+		}))
+	case vm.MSTORE8:
+		offset, value := stackUint64(stack, 0), byte(stack.Back(1).Uint64())
+		osp.AddProof(proof.NewDataWriteProof(memoryTree(), offset, saturatingAdd(offset, 1), func(uint64) byte {
+			return value
+		}))
+	case vm.KECCAK256, vm.LOG0, vm.LOG1, vm.LOG2, vm.LOG3, vm.LOG4, vm.RETURN, vm.REVERT:
+		offset := stackUint64(stack, 0)
+		osp.AddProof(proof.NewDataReadProof(memoryTree(), offset, saturatingAdd(offset, stackUint64(stack, 1))))
+	case vm.SLOAD, vm.SSTORE:
+		if err := addAccountProof(frame.ContractAddress); err != nil {
+			return nil, err
+		}
+		storageProof, err := proof.NewStorageProof(statedb, frame.ContractAddress, common.Hash(stack.Back(0).Bytes32()))
+		if err != nil {
+			return nil, err
+		}
+		osp.AddProof(storageProof)
+	case vm.BALANCE, vm.EXTCODEHASH:
+		if err := addAccountProof(stackAddress(stack, 0)); err != nil {
+			return nil, err
+		}
+	case vm.EXTCODESIZE:
+		address := stackAddress(stack, 0)
+		if err := addAccountProof(address); err != nil {
+			return nil, err
+		}
+		addCodeProof(address)
+	case vm.SELFBALANCE:
+		if err := addAccountProof(frame.ContractAddress); err != nil {
+			return nil, err
+		}
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		argsIndex := 3
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			argsIndex = 2
+		}
+		offset := stackUint64(stack, argsIndex)
+		osp.AddProof(proof.NewDataReadProof(memoryTree(), offset, saturatingAdd(offset, stackUint64(stack, argsIndex+1))))
+		target := stackAddress(stack, 1)
+		if err := addAccountProof(frame.ContractAddress); err != nil {
+			return nil, err
+		}
+		if err := addAccountProof(target); err != nil {
+			return nil, err
+		}
+		addCodeProof(target)
+	case vm.CREATE, vm.CREATE2:
+		offset, length := stackUint64(stack, 1), stackUint64(stack, 2)
+		osp.AddProof(proof.NewDataReadProof(memoryTree(), offset, saturatingAdd(offset, length)))
+		var address common.Address
+		if op == vm.CREATE {
+			address = crypto.CreateAddress(frame.ContractAddress, statedb.GetNonce(frame.ContractAddress))
+		} else {
+			initCode := memorySlice(memory, offset, length)
+			address = crypto.CreateAddress2(frame.ContractAddress, stack.Back(3).Bytes32(), crypto.Keccak256(initCode))
+		}
+		if err := addAccountProof(frame.ContractAddress); err != nil {
+			return nil, err
+		}
+		if err := addAccountProof(address); err != nil {
+			return nil, err
+		}
+	case vm.SELFDESTRUCT:
+		if err := addAccountProof(frame.ContractAddress); err != nil {
+			return nil, err
+		}
+		if err := addAccountProof(stackAddress(stack, 0)); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op {
+	case vm.STOP, vm.SELFDESTRUCT:
+		if err := l.addFrameEndProofs(osp, nil); err != nil {
+			return nil, err
+		}
+	case vm.RETURN, vm.REVERT:
+		output := memorySlice(memory, stackUint64(stack, 0), stackUint64(stack, 1))
+		if err := l.addFrameEndProofs(osp, output); err != nil {
+			return nil, err
+		}
+	}
+	return osp, nil
+}
+
+// Proves the return to the caller frame with `output`, or the end of the transaction at depth 1.
+func (l *OneStepProver) addFrameEndProofs(osp *proof.OneStepProof, output []byte) error {
+	frame := l.tracker.Frame()
+	if frame.CallerState != nil {
+		osp.AddProof(&proof.IntraStateProof{State: frame.CallerState})
+		if len(output) > 0 && frame.OutSize > 0 {
+			// The caller's memory was expanded to cover the output location, which is zero.
+			callerMemory := frame.CallerMemory.Data()[:frame.CallerState.MemorySize]
+			size := uint64(len(output))
+			if frame.OutSize < size {
+				size = frame.OutSize
+			}
+			osp.AddProof(proof.NewDataWriteProof(state.NewMerkleTree(callerMemory), frame.Out, saturatingAdd(frame.Out, size), func(i uint64) byte {
+				return output[i]
+			}))
+		}
+		return nil
+	}
+	// Refund of the sender and payment of the fees
+	statedb := l.tracker.StateDB()
+	for _, address := range []common.Address{l.env.TxContext.Origin, l.env.Context.Coinbase} {
+		accountProof, err := proof.NewAccountProof(statedb, address)
+		if err != nil {
+			return err
+		}
+		osp.AddProof(accountProof)
+	}
+	return nil
+}
+
+// NewTransactionStartProof proves the start of a transaction (step 0) from the world state with root `target`:
+// the accounts of the sender and of the recipient (or created contract), and the code of the recipient.
+func NewTransactionStartProof(target common.Hash, statedb *gethState.StateDB, msg core.Message, deleteEmpty bool) (*proof.OneStepProof, error) {
+	statedb = statedb.Copy()
+	if root := statedb.IntermediateRoot(deleteEmpty); root != target {
+		return nil, fmt.Errorf("State root mismatch at transaction start, expected %s, got %s", target, root)
+	}
+	recipient := crypto.CreateAddress(msg.From(), msg.Nonce())
+	if msg.To() != nil {
+		recipient = *msg.To()
+	}
+	osp := proof.EmptyProof()
+	for _, address := range []common.Address{msg.From(), recipient} {
+		accountProof, err := proof.NewAccountProof(statedb, address)
+		if err != nil {
+			return nil, err
+		}
+		osp.AddProof(accountProof)
+	}
+	osp.AddProof(&proof.CodeProof{Code: statedb.GetCode(recipient)})
+	return osp, nil
+}
+
+// Returns the number of immediate bytes of `op`.
+func pushSize(op vm.OpCode) uint64 {
+	if op.IsPush() {
+		return uint64(op-vm.PUSH1) + 1
+	}
+	return 0
+}
+
+// Returns the number of stack items consumed by `op`.
+func stackPops(op vm.OpCode) int {
+	switch {
+	case op >= vm.DUP1 && op <= vm.DUP16:
+		return int(op-vm.DUP1) + 1
+	case op >= vm.SWAP1 && op <= vm.SWAP16:
+		return int(op-vm.SWAP1) + 2
+	case op >= vm.LOG0 && op <= vm.LOG4:
+		return int(op-vm.LOG0) + 2
+	}
+	switch op {
+	case vm.ISZERO, vm.NOT, vm.BALANCE, vm.CALLDATALOAD, vm.EXTCODESIZE, vm.EXTCODEHASH, vm.BLOCKHASH,
+		vm.POP, vm.MLOAD, vm.SLOAD, vm.JUMP, vm.SELFDESTRUCT:
+		return 1
+	case vm.ADD, vm.MUL, vm.SUB, vm.DIV, vm.SDIV, vm.MOD, vm.SMOD, vm.EXP, vm.SIGNEXTEND,
+		vm.LT, vm.GT, vm.SLT, vm.SGT, vm.EQ, vm.AND, vm.OR, vm.XOR, vm.BYTE, vm.SHL, vm.SHR, vm.SAR,
+		vm.KECCAK256, vm.MSTORE, vm.MSTORE8, vm.SSTORE, vm.JUMPI, vm.RETURN, vm.REVERT:
+		return 2
+	case vm.ADDMOD, vm.MULMOD, vm.CALLDATACOPY, vm.CODECOPY, vm.RETURNDATACOPY, vm.CREATE:
+		return 3
+	case vm.EXTCODECOPY, vm.CREATE2:
+		return 4
+	case vm.DELEGATECALL, vm.STATICCALL:
+		return 6
+	case vm.CALL, vm.CALLCODE:
+		return 7
+	default:
+		return 0
+	}
+}
+
+// Returns the n-th stack item from the top as uint64, saturating.
+func stackUint64(stack *vm.Stack, n int) uint64 {
+	item := stack.Back(n)
+	if !item.IsUint64() {
+		return ^uint64(0)
+	}
+	return item.Uint64()
+}
+
+func stackAddress(stack *vm.Stack, n int) common.Address {
+	return common.Address(stack.Back(n).Bytes20())
+}
+
+func saturatingAdd(a, b uint64) uint64 {
+	if a+b < a {
+		return ^uint64(0)
+	}
+	return a + b
+}
+
+// Returns byte `i` of `data`, zero past its end.
+func dataByte(data []byte, i uint64) byte {
+	if i >= uint64(len(data)) {
+		return 0
+	}
+	return data[i]
+}
+
+// Returns `size` bytes of memory at `offset`, zero past its end.
+// The memory has been charged for (but not yet expanded to) the range.
+func memorySlice(memory []byte, offset, size uint64) []byte {
+	slice := make([]byte, size)
+	if offset < uint64(len(memory)) {
+		copy(slice, memory[offset:])
+	}
+	return slice
 }
@@ -0,0 +1,710 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prover
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethState "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
+	"github.com/specularl2/specular/clients/geth/specular/proof/proof"
+	"github.com/specularl2/specular/clients/geth/specular/proof/state"
+)
+
+var (
+	testSender   = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testCoinbase = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	testContract = common.HexToAddress("0x3000000000000000000000000000000000000003")
+	testCallee   = common.HexToAddress("0x4000000000000000000000000000000000000004")
+	testBalance  = big.NewInt(1_000_000_000)
+)
+
+const testGas = 1_000_000
+
+// Minimal assembler for test contracts.
+type program []byte
+
+func (p program) op(ops ...vm.OpCode) program {
+	for _, op := range ops {
+		p = append(p, byte(op))
+	}
+	return p
+}
+
+func (p program) push(v uint64) program {
+	b := new(big.Int).SetUint64(v).Bytes()
+	if len(b) == 0 {
+		b = []byte{0}
+	}
+	return p.pushBytes(b)
+}
+
+func (p program) pushBytes(b []byte) program {
+	p = append(p, byte(vm.PUSH1)+byte(len(b)-1))
+	return append(p, b...)
+}
+
+// Pushes the arguments of a CALL, last argument first.
+func (p program) call(address common.Address, argsOffset, argsSize, retOffset, retSize uint64) program {
+	return p.push(retSize).push(retOffset).push(argsSize).push(argsOffset).push(0).
+		pushBytes(address.Bytes()).push(0xffff).op(vm.CALL)
+}
+
+// stateCollector records the `state.IntraState`s of a transaction using a `state.Tracker`.
+type stateCollector struct {
+	tracker *state.Tracker
+	states  []*state.IntraState
+	ops     []vm.OpCode
+}
+
+func (c *stateCollector) CaptureTxStart(gasLimit uint64) {}
+
+func (c *stateCollector) CaptureTxEnd(restGas uint64) {}
+
+func (c *stateCollector) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	c.tracker.CaptureStart(env, from, to, create, input, value)
+}
+
+func (c *stateCollector) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	c.states = append(c.states, c.tracker.CaptureState(pc, op, gas, scope, rData))
+	c.ops = append(c.ops, op)
+}
+
+func (c *stateCollector) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	c.tracker.CaptureEnter(typ, from, to, input, value)
+}
+
+func (c *stateCollector) CaptureExit(output []byte, gasUsed uint64, err error) {
+	c.tracker.CaptureExit()
+}
+
+func (c *stateCollector) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (c *stateCollector) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) {}
+
+func newTestState(t *testing.T, code, calleeCode []byte) *gethState.StateDB {
+	statedb, err := gethState.New(common.Hash{}, gethState.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatalf("failed to create state: %v", err)
+	}
+	statedb.SetBalance(testSender, testBalance)
+	statedb.SetBalance(testCoinbase, big.NewInt(1))
+	statedb.SetCode(testContract, code)
+	statedb.SetBalance(testContract, big.NewInt(5))
+	statedb.SetState(testContract, common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(7)))
+	if calleeCode != nil {
+		statedb.SetCode(testCallee, calleeCode)
+	}
+	statedb.IntermediateRoot(true)
+	return statedb
+}
+
+// Calls the test contract with `input` on `statedb` traced by `tracer`.
+func runTest(statedb *gethState.StateDB, tracer vm.EVMLogger, input []byte) {
+	blockCtx := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Coinbase:    testCoinbase,
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(1),
+		Difficulty:  big.NewInt(0),
+		GasLimit:    testGas,
+		BaseFee:     big.NewInt(0),
+	}
+	txCtx := vm.TxContext{Origin: testSender, GasPrice: big.NewInt(0)}
+	env := vm.NewEVM(blockCtx, txCtx, statedb, params.TestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	env.Call(vm.AccountRef(testSender), testContract, input, testGas, new(big.Int))
+}
+
+// Proves the `occurrence`-th (from 0) step executing `op`, and returns its state and proof.
+func proveTestStep(t *testing.T, code, calleeCode, input []byte, op vm.OpCode, occurrence int) (*state.IntraState, []byte) {
+	statedb := newTestState(t, code, calleeCode)
+	collectorState := statedb.Copy()
+	collector := &stateCollector{tracker: state.NewTracker(collectorState, 1, 0, true)}
+	runTest(collectorState, collector, input)
+	step := -1
+	for i, executed := range collector.ops {
+		if executed == op {
+			if occurrence == 0 {
+				step = i
+				break
+			}
+			occurrence--
+		}
+	}
+	if step < 0 {
+		t.Fatalf("%v not executed", op)
+	}
+	st := collector.states[step]
+	prover := NewProver(st.Hash(), uint64(step+1), statedb, 1, 0, true)
+	runTest(statedb, prover, input)
+	osp, err := prover.GetProof()
+	if err != nil {
+		t.Fatalf("failed to prove %v: %v", op, err)
+	}
+	return st, osp.Encode()
+}
+
+// proofReader decodes proofs in the layout documented in `IVerifier.verifyOneStepProof`.
+type proofReader struct {
+	t    *testing.T
+	data []byte
+}
+
+func (r *proofReader) read(n int) []byte {
+	r.t.Helper()
+	if len(r.data) < n {
+		r.t.Fatalf("proof too short: need %d bytes, have %d", n, len(r.data))
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *proofReader) uint8() uint8   { return r.read(1)[0] }
+func (r *proofReader) uint16() uint16 { return binary.BigEndian.Uint16(r.read(2)) }
+func (r *proofReader) uint64() uint64 { return binary.BigEndian.Uint64(r.read(8)) }
+func (r *proofReader) hash() common.Hash {
+	return common.BytesToHash(r.read(32))
+}
+func (r *proofReader) address() common.Address {
+	return common.BytesToAddress(r.read(20))
+}
+func (r *proofReader) uint256() *uint256.Int {
+	return new(uint256.Int).SetBytes(r.read(32))
+}
+
+func (r *proofReader) end() {
+	r.t.Helper()
+	if len(r.data) != 0 {
+		r.t.Fatalf("%d trailing bytes in proof", len(r.data))
+	}
+}
+
+func (r *proofReader) intraState() *state.IntraState {
+	return &state.IntraState{
+		BlockNumber:     r.uint64(),
+		TxIndex:         r.uint64(),
+		Depth:           r.uint16(),
+		Gas:             r.uint64(),
+		Refund:          r.uint64(),
+		LastDepthHash:   r.hash(),
+		ContractAddress: r.address(),
+		Caller:          r.address(),
+		Value:           r.uint256(),
+		CallFlag:        state.CallFlag(r.uint8()),
+		Out:             r.uint64(),
+		OutSize:         r.uint64(),
+		Pc:              r.uint64(),
+		CodeRoot:        r.hash(),
+		CodeSize:        r.uint64(),
+		StackSize:       r.uint64(),
+		StackHash:       r.hash(),
+		MemorySize:      r.uint64(),
+		MemoryRoot:      r.hash(),
+		InputDataSize:   r.uint64(),
+		InputDataRoot:   r.hash(),
+		ReturnDataSize:  r.uint64(),
+		ReturnDataRoot:  r.hash(),
+		StateRoot:       r.hash(),
+	}
+}
+
+func (r *proofReader) dataProof() *proof.DataProof {
+	p := &proof.DataProof{Words: make([]*proof.MerkleProof, r.uint64())}
+	for i := range p.Words {
+		word := &proof.MerkleProof{Index: r.uint64(), Word: r.hash()}
+		word.Siblings = make([]common.Hash, r.uint8())
+		for j := range word.Siblings {
+			word.Siblings[j] = r.hash()
+		}
+		p.Words[i] = word
+	}
+	return p
+}
+
+func (r *proofReader) stackProof() *proof.StackProof {
+	p := &proof.StackProof{Items: make([]uint256.Int, r.uint8())}
+	p.RemainingHash = r.hash()
+	for i := range p.Items {
+		p.Items[i] = *r.uint256()
+	}
+	return p
+}
+
+func (r *proofReader) nodes() [][]byte {
+	nodes := make([][]byte, r.uint64())
+	for i := range nodes {
+		nodes[i] = r.read(int(r.uint64()))
+	}
+	return nodes
+}
+
+func (r *proofReader) accountProof() *proof.AccountProof {
+	return &proof.AccountProof{
+		Address:     r.address(),
+		Nonce:       r.uint64(),
+		Balance:     r.uint256(),
+		StorageRoot: r.hash(),
+		CodeHash:    r.hash(),
+		Nodes:       r.nodes(),
+	}
+}
+
+func (r *proofReader) storageProof() *proof.StorageProof {
+	return &proof.StorageProof{Key: r.hash(), Value: r.hash(), Nodes: r.nodes()}
+}
+
+func (r *proofReader) codeProof() *proof.CodeProof {
+	return &proof.CodeProof{Code: r.read(int(r.uint64()))}
+}
+
+// Checks the proof's state, instruction and stack items, and returns the stack items.
+func checkBaseProof(t *testing.T, r *proofReader, st *state.IntraState, code []byte, pops int) []uint256.Int {
+	t.Helper()
+	if decoded := r.intraState(); decoded.Hash() != st.Hash() {
+		t.Fatalf("intra-state mismatch: have %+v, want %+v", decoded, st)
+	}
+	words := checkDataRead(t, r.dataProof(), st.CodeRoot)
+	if len(words) == 0 || words[0].Index != st.Pc/state.WordSize {
+		t.Fatalf("instruction at pc %d not proven", st.Pc)
+	}
+	if op := words[0].Word[st.Pc%state.WordSize]; op != code[st.Pc] {
+		t.Fatalf("instruction mismatch: have %x, want %x", op, code[st.Pc])
+	}
+	stack := r.stackProof()
+	if len(stack.Items) != pops {
+		t.Fatalf("stack item count mismatch: have %d, want %d", len(stack.Items), pops)
+	}
+	hash := stack.RemainingHash
+	for i := len(stack.Items) - 1; i >= 0; i-- {
+		hash = state.PushStackHash(hash, &stack.Items[i])
+	}
+	if hash != st.StackHash {
+		t.Fatalf("stack hash mismatch: have %s, want %s", hash, st.StackHash)
+	}
+	return stack.Items
+}
+
+// Checks that all words are proven against `root`, and returns them.
+func checkDataRead(t *testing.T, p *proof.DataProof, root common.Hash) []*proof.MerkleProof {
+	t.Helper()
+	for _, word := range p.Words {
+		if computed := state.RootFromProof(word.Index, word.Word, word.Siblings); computed != root {
+			t.Fatalf("word %d root mismatch: have %s, want %s", word.Index, computed, root)
+		}
+	}
+	return p.Words
+}
+
+// Checks that the words of a write are proven one after the other starting from `root`,
+// applying `data` at `offset`, and returns the resulting root.
+func checkDataWrite(t *testing.T, p *proof.DataProof, root common.Hash, offset uint64, data []byte) common.Hash {
+	t.Helper()
+	for _, word := range p.Words {
+		if computed := state.RootFromProof(word.Index, word.Word, word.Siblings); computed != root {
+			t.Fatalf("word %d root mismatch: have %s, want %s", word.Index, computed, root)
+		}
+		written := word.Word
+		for i := uint64(0); i < state.WordSize; i++ {
+			if pos := word.Index*state.WordSize + i; pos >= offset && pos < offset+uint64(len(data)) {
+				written[i] = data[pos-offset]
+			}
+		}
+		root = state.RootFromProof(word.Index, written, word.Siblings)
+	}
+	return root
+}
+
+func proofDB(nodes [][]byte) *memorydb.Database {
+	db := memorydb.New()
+	for _, node := range nodes {
+		db.Put(crypto.Keccak256(node), node)
+	}
+	return db
+}
+
+// Checks an account proof against `root` and the account in `statedb`.
+func checkAccountProof(t *testing.T, p *proof.AccountProof, root common.Hash, address common.Address, statedb *gethState.StateDB) {
+	t.Helper()
+	if p.Address != address {
+		t.Fatalf("account address mismatch: have %s, want %s", p.Address, address)
+	}
+	value, err := trie.VerifyProof(root, crypto.Keccak256(address[:]), proofDB(p.Nodes))
+	if err != nil {
+		t.Fatalf("invalid account proof of %s: %v", address, err)
+	}
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(value, &account); err != nil {
+		t.Fatalf("failed to decode account %s: %v", address, err)
+	}
+	if p.Nonce != account.Nonce || p.Balance.ToBig().Cmp(account.Balance) != 0 ||
+		p.StorageRoot != account.Root || p.CodeHash != common.BytesToHash(account.CodeHash) {
+		t.Fatalf("account %s mismatch: have %+v, want %+v", address, p, account)
+	}
+	if balance := statedb.GetBalance(address); p.Balance.ToBig().Cmp(balance) != 0 {
+		t.Fatalf("account %s balance mismatch: have %v, want %v", address, p.Balance, balance)
+	}
+}
+
+func checkCodeProof(t *testing.T, p *proof.CodeProof, account *proof.AccountProof, code []byte) {
+	t.Helper()
+	if !bytes.Equal(p.Code, code) {
+		t.Fatalf("code mismatch: have %x, want %x", p.Code, code)
+	}
+	if hash := crypto.Keccak256Hash(p.Code); hash != account.CodeHash {
+		t.Fatalf("code hash mismatch: have %s, want %s", hash, account.CodeHash)
+	}
+}
+
+func word(v uint64) []byte {
+	return common.BigToHash(new(big.Int).SetUint64(v)).Bytes()
+}
+
+func TestOneStepProofArithmetic(t *testing.T) {
+	code := program{}.push(2).push(3).op(vm.ADD, vm.STOP)
+	st, encoded := proveTestStep(t, code, nil, nil, vm.ADD, 0)
+	r := &proofReader{t: t, data: encoded}
+	items := checkBaseProof(t, r, st, code, 2)
+	if items[0].Uint64() != 3 || items[1].Uint64() != 2 {
+		t.Fatalf("stack items mismatch: have %v", items)
+	}
+	r.end()
+}
+
+func TestOneStepProofPush(t *testing.T) {
+	// PUSH32 at pc 30 spans 2 code words.
+	code := program{}
+	for i := 0; i < 30; i++ {
+		code = code.op(vm.JUMPDEST)
+	}
+	code = code.pushBytes(bytes.Repeat([]byte{0xab}, 32)).op(vm.STOP)
+	st, encoded := proveTestStep(t, code, nil, nil, vm.PUSH32, 0)
+	r := &proofReader{t: t, data: encoded}
+	if decoded := r.intraState(); decoded.Hash() != st.Hash() {
+		t.Fatalf("intra-state mismatch")
+	}
+	if words := checkDataRead(t, r.dataProof(), st.CodeRoot); len(words) != 2 {
+		t.Fatalf("code word count mismatch: have %d, want 2", len(words))
+	}
+	if stack := r.stackProof(); len(stack.Items) != 0 {
+		t.Fatalf("stack item count mismatch: have %d, want 0", len(stack.Items))
+	}
+	r.end()
+}
+
+func TestOneStepProofMemory(t *testing.T) {
+	code := program{}.
+		push(0x11).push(0).op(vm.MSTORE).
+		push(0x22).push(0x10).op(vm.MSTORE).
+		push(0x10).op(vm.MLOAD).
+		push(0x33).push(0x3f).op(vm.MSTORE8).
+		push(0x20).push(0).op(vm.KECCAK256, vm.STOP)
+
+	t.Run("MSTORE", func(t *testing.T) {
+		st, encoded := proveTestStep(t, code, nil, nil, vm.MSTORE, 1)
+		r := &proofReader{t: t, data: encoded}
+		checkBaseProof(t, r, st, code, 2)
+		// Only the word within memory is proven, the next one is zero.
+		write := r.dataProof()
+		if len(write.Words) != 1 || !bytes.Equal(write.Words[0].Word[:], word(0x11)) {
+			t.Fatalf("memory write proof mismatch: %+v", write.Words)
+		}
+		checkDataWrite(t, write, st.MemoryRoot, 0x10, word(0x22))
+		r.end()
+	})
+	t.Run("MLOAD", func(t *testing.T) {
+		st, encoded := proveTestStep(t, code, nil, nil, vm.MLOAD, 0)
+		r := &proofReader{t: t, data: encoded}
+		checkBaseProof(t, r, st, code, 1)
+		if words := checkDataRead(t, r.dataProof(), st.MemoryRoot); len(words) != 2 {
+			t.Fatalf("memory word count mismatch: have %d, want 2", len(words))
+		}
+		r.end()
+	})
+	t.Run("MSTORE8", func(t *testing.T) {
+		st, encoded := proveTestStep(t, code, nil, nil, vm.MSTORE8, 0)
+		r := &proofReader{t: t, data: encoded}
+		checkBaseProof(t, r, st, code, 2)
+		write := r.dataProof()
+		if len(write.Words) != 1 || write.Words[0].Index != 1 {
+			t.Fatalf("memory write proof mismatch: %+v", write.Words)
+		}
+		checkDataWrite(t, write, st.MemoryRoot, 0x3f, []byte{0x33})
+		r.end()
+	})
+	t.Run("KECCAK256", func(t *testing.T) {
+		st, encoded := proveTestStep(t, code, nil, nil, vm.KECCAK256, 0)
+		r := &proofReader{t: t, data: encoded}
+		checkBaseProof(t, r, st, code, 2)
+		if words := checkDataRead(t, r.dataProof(), st.MemoryRoot); len(words) != 1 {
+			t.Fatalf("memory word count mismatch: have %d, want 1", len(words))
+		}
+		r.end()
+	})
+}
+
+func TestOneStepProofInput(t *testing.T) {
+	input := bytes.Repeat([]byte{0xcd}, 40)
+	code := program{}.
+		push(4).op(vm.CALLDATALOAD, vm.POP).
+		push(0).op(vm.MLOAD, vm.POP).
+		push(8).push(36).push(0x10).op(vm.CALLDATACOPY, vm.STOP)
+
+	t.Run("CALLDATALOAD", func(t *testing.T) {
+		st, encoded := proveTestStep(t, code, nil, input, vm.CALLDATALOAD, 0)
+		r := &proofReader{t: t, data: encoded}
+		checkBaseProof(t, r, st, code, 1)
+		if words := checkDataRead(t, r.dataProof(), st.InputDataRoot); len(words) != 2 {
+			t.Fatalf("input word count mismatch: have %d, want 2", len(words))
+		}
+		r.end()
+	})
+	t.Run("CALLDATACOPY", func(t *testing.T) {
+		st, encoded := proveTestStep(t, code, nil, input, vm.CALLDATACOPY, 0)
+		r := &proofReader{t: t, data: encoded}
+		checkBaseProof(t, r, st, code, 3)
+		// Bytes [36, 44) of the input, of which [40, 44) are zero.
+		if words := checkDataRead(t, r.dataProof(), st.InputDataRoot); len(words) != 1 || words[0].Index != 1 {
+			t.Fatalf("input read proof mismatch: %+v", words)
+		}
+		copied := append(bytes.Repeat([]byte{0xcd}, 4), make([]byte, 4)...)
+		checkDataWrite(t, r.dataProof(), st.MemoryRoot, 0x10, copied)
+		r.end()
+	})
+}
+
+func TestOneStepProofStorage(t *testing.T) {
+	code := program{}.push(1).op(vm.SLOAD).push(2).op(vm.SSTORE, vm.STOP)
+	for i, op := range []vm.OpCode{vm.SLOAD, vm.SSTORE} {
+		t.Run(op.String(), func(t *testing.T) {
+			st, encoded := proveTestStep(t, code, nil, nil, op, 0)
+			statedb := newTestState(t, code, nil)
+			r := &proofReader{t: t, data: encoded}
+			checkBaseProof(t, r, st, code, i+1)
+			account := r.accountProof()
+			checkAccountProof(t, account, st.StateRoot, testContract, statedb)
+			storage := r.storageProof()
+			key := common.BigToHash(big.NewInt(int64(i + 1)))
+			if storage.Key != key || storage.Value != statedb.GetState(testContract, key) {
+				t.Fatalf("storage proof mismatch: %+v", storage)
+			}
+			value, err := trie.VerifyProof(account.StorageRoot, crypto.Keccak256(key[:]), proofDB(storage.Nodes))
+			if err != nil {
+				t.Fatalf("invalid storage proof: %v", err)
+			}
+			var content []byte
+			if len(value) > 0 {
+				if err := rlp.DecodeBytes(value, &content); err != nil {
+					t.Fatalf("failed to decode storage value: %v", err)
+				}
+			}
+			if common.BytesToHash(content) != storage.Value {
+				t.Fatalf("storage value mismatch: have %x, want %s", content, storage.Value)
+			}
+			r.end()
+		})
+	}
+}
+
+func TestOneStepProofAccount(t *testing.T) {
+	code := program{}.
+		pushBytes(testSender.Bytes()).op(vm.BALANCE, vm.POP).
+		pushBytes(testContract.Bytes()).op(vm.EXTCODESIZE, vm.POP).
+		op(vm.SELFBALANCE, vm.STOP)
+
+	t.Run("BALANCE", func(t *testing.T) {
+		st, encoded := proveTestStep(t, code, nil, nil, vm.BALANCE, 0)
+		r := &proofReader{t: t, data: encoded}
+		checkBaseProof(t, r, st, code, 1)
+		checkAccountProof(t, r.accountProof(), st.StateRoot, testSender, newTestState(t, code, nil))
+		r.end()
+	})
+	t.Run("EXTCODESIZE", func(t *testing.T) {
+		st, encoded := proveTestStep(t, code, nil, nil, vm.EXTCODESIZE, 0)
+		r := &proofReader{t: t, data: encoded}
+		checkBaseProof(t, r, st, code, 1)
+		account := r.accountProof()
+		checkAccountProof(t, account, st.StateRoot, testContract, newTestState(t, code, nil))
+		checkCodeProof(t, r.codeProof(), account, code)
+		r.end()
+	})
+	t.Run("SELFBALANCE", func(t *testing.T) {
+		st, encoded := proveTestStep(t, code, nil, nil, vm.SELFBALANCE, 0)
+		r := &proofReader{t: t, data: encoded}
+		checkBaseProof(t, r, st, code, 0)
+		checkAccountProof(t, r.accountProof(), st.StateRoot, testContract, newTestState(t, code, nil))
+		r.end()
+	})
+}
+
+func TestOneStepProofCall(t *testing.T) {
+	// The callee returns 0xbeef, written over the arguments in the caller's memory.
+	calleeCode := program{}.push(0xbeef).push(0).op(vm.MSTORE).push(2).push(30).op(vm.RETURN)
+	code := program{}.push(0x1234).push(0).op(vm.MSTORE).call(testCallee, 0, 32, 0, 32).op(vm.STOP)
+
+	t.Run("CALL", func(t *testing.T) {
+		st, encoded := proveTestStep(t, code, calleeCode, nil, vm.CALL, 0)
+		statedb := newTestState(t, code, calleeCode)
+		r := &proofReader{t: t, data: encoded}
+		items := checkBaseProof(t, r, st, code, 7)
+		if common.Address(items[1].Bytes20()) != testCallee {
+			t.Fatalf("call target mismatch: have %x", items[1].Bytes20())
+		}
+		if words := checkDataRead(t, r.dataProof(), st.MemoryRoot); len(words) != 1 || !bytes.Equal(words[0].Word[:], word(0x1234)) {
+			t.Fatalf("call arguments proof mismatch: %+v", words)
+		}
+		checkAccountProof(t, r.accountProof(), st.StateRoot, testContract, statedb)
+		callee := r.accountProof()
+		checkAccountProof(t, callee, st.StateRoot, testCallee, statedb)
+		checkCodeProof(t, r.codeProof(), callee, calleeCode)
+		r.end()
+	})
+	t.Run("RETURN", func(t *testing.T) {
+		st, encoded := proveTestStep(t, code, calleeCode, nil, vm.RETURN, 0)
+		if st.Depth != 2 {
+			t.Fatalf("depth mismatch: have %d, want 2", st.Depth)
+		}
+		r := &proofReader{t: t, data: encoded}
+		checkBaseProof(t, r, st, calleeCode, 2)
+		if words := checkDataRead(t, r.dataProof(), st.MemoryRoot); len(words) != 1 {
+			t.Fatalf("output word count mismatch: have %d, want 1", len(words))
+		}
+		// Frame end: caller state and return data written to its memory
+		caller := r.intraState()
+		if caller.Hash() != st.LastDepthHash {
+			t.Fatalf("caller state mismatch: have %s, want %s", caller.Hash(), st.LastDepthHash)
+		}
+		write := r.dataProof()
+		if len(write.Words) != 1 || !bytes.Equal(write.Words[0].Word[:], word(0x1234)) {
+			t.Fatalf("caller memory write proof mismatch: %+v", write.Words)
+		}
+		root := checkDataWrite(t, write, caller.MemoryRoot, 0, []byte{0xbe, 0xef})
+		expected := append([]byte{0xbe, 0xef}, word(0x1234)[2:]...)
+		if want := state.MerkleRoot(expected); root != want {
+			t.Fatalf("caller memory root mismatch: have %s, want %s", root, want)
+		}
+		r.end()
+	})
+}
+
+func TestOneStepProofCreate(t *testing.T) {
+	initCode := program{}.push(0).push(0).op(vm.RETURN)
+	code := program{}.pushBytes(initCode).push(0).op(vm.MSTORE).
+		push(uint64(len(initCode))).push(uint64(32-len(initCode))).push(0).op(vm.CREATE, vm.STOP)
+	st, encoded := proveTestStep(t, code, nil, nil, vm.CREATE, 0)
+	statedb := newTestState(t, code, nil)
+	r := &proofReader{t: t, data: encoded}
+	checkBaseProof(t, r, st, code, 3)
+	if words := checkDataRead(t, r.dataProof(), st.MemoryRoot); len(words) != 1 {
+		t.Fatalf("init code word count mismatch: have %d, want 1", len(words))
+	}
+	checkAccountProof(t, r.accountProof(), st.StateRoot, testContract, statedb)
+	created := r.accountProof()
+	if want := crypto.CreateAddress(testContract, statedb.GetNonce(testContract)); created.Address != want {
+		t.Fatalf("created address mismatch: have %s, want %s", created.Address, want)
+	}
+	r.end()
+}
+
+func TestOneStepProofTransactionEnd(t *testing.T) {
+	code := program{}.op(vm.STOP)
+	st, encoded := proveTestStep(t, code, nil, nil, vm.STOP, 0)
+	statedb := newTestState(t, code, nil)
+	r := &proofReader{t: t, data: encoded}
+	checkBaseProof(t, r, st, code, 0)
+	checkAccountProof(t, r.accountProof(), st.StateRoot, testSender, statedb)
+	checkAccountProof(t, r.accountProof(), st.StateRoot, testCoinbase, statedb)
+	r.end()
+}
+
+func TestOneStepProofFault(t *testing.T) {
+	tests := []struct {
+		name string
+		code program
+		op   vm.OpCode
+		pops int
+	}{
+		// Stack underflow before executing, no stack items to prove
+		{"StackUnderflow", program{}.push(1).op(vm.ADD), vm.ADD, 1},
+		// Return data out of bounds while executing, no memory proofs
+		{"ReturnDataOutOfBounds", program{}.push(1).push(0).push(0).op(vm.RETURNDATACOPY), vm.RETURNDATACOPY, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, encoded := proveTestStep(t, tt.code, nil, nil, tt.op, 0)
+			statedb := newTestState(t, tt.code, nil)
+			r := &proofReader{t: t, data: encoded}
+			checkBaseProof(t, r, st, tt.code, tt.pops)
+			checkAccountProof(t, r.accountProof(), st.StateRoot, testSender, statedb)
+			checkAccountProof(t, r.accountProof(), st.StateRoot, testCoinbase, statedb)
+			r.end()
+		})
+	}
+}
+
+func TestOneStepProofMismatch(t *testing.T) {
+	code := program{}.push(2).push(3).op(vm.ADD, vm.STOP)
+	statedb := newTestState(t, code, nil)
+	prover := NewProver(common.HexToHash("0x01"), 3, statedb, 1, 0, true)
+	runTest(statedb, prover, nil)
+	if _, err := prover.GetProof(); err == nil {
+		t.Fatal("expected a state hash mismatch")
+	}
+
+	statedb = newTestState(t, code, nil)
+	prover = NewProver(common.HexToHash("0x01"), 100, statedb, 1, 0, true)
+	runTest(statedb, prover, nil)
+	if _, err := prover.GetProof(); !errors.Is(err, ErrStepNotReached) {
+		t.Fatalf("expected %v, got %v", ErrStepNotReached, err)
+	}
+}
+
+func TestTransactionStartProof(t *testing.T) {
+	code := program{}.op(vm.STOP)
+	statedb := newTestState(t, code, nil)
+	root := statedb.Copy().IntermediateRoot(true)
+	msg := types.NewMessage(testSender, &testContract, 0, new(big.Int), testGas, new(big.Int), new(big.Int), new(big.Int), nil, nil, false)
+	osp, err := NewTransactionStartProof(root, statedb, msg, true)
+	if err != nil {
+		t.Fatalf("failed to prove transaction start: %v", err)
+	}
+	r := &proofReader{t: t, data: osp.Encode()}
+	checkAccountProof(t, r.accountProof(), root, testSender, statedb)
+	contract := r.accountProof()
+	checkAccountProof(t, contract, root, testContract, statedb)
+	checkCodeProof(t, r.codeProof(), contract, code)
+	r.end()
+
+	if _, err := NewTransactionStartProof(common.Hash{}, statedb, msg, true); err == nil {
+		t.Fatal("expected a state root mismatch")
+	}
+}
@@ -19,7 +19,9 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	gethState "github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/specularl2/specular/clients/geth/specular/proof/state"
 )
 
 type GeneratedState struct {
@@ -27,13 +29,31 @@ type GeneratedState struct {
 	Gas    uint64
 }
 
+// StateGenerator generates the VM hash before each step of a transaction,
+// i.e. the hash of the `state.IntraState` the one-step proof verifier recomputes.
 type StateGenerator struct {
 	// Global
-	states []GeneratedState
+	tracker *state.Tracker
+	states  []GeneratedState
+	// If set, states are passed to `onState` instead of being kept.
+	onState func(GeneratedState)
 }
 
-func NewStateGenerator() *StateGenerator {
-	return &StateGenerator{}
+func NewStateGenerator(statedb *gethState.StateDB, blockNumber, txIndex uint64, deleteEmpty bool) *StateGenerator {
+	return &StateGenerator{tracker: state.NewTracker(statedb, blockNumber, txIndex, deleteEmpty)}
+}
+
+// NewStreamingStateGenerator creates a `StateGenerator` passing each state to `onState` as it is generated,
+// so that memory use does not grow with the length of the transaction.
+func NewStreamingStateGenerator(
+	statedb *gethState.StateDB,
+	blockNumber, txIndex uint64,
+	deleteEmpty bool,
+	onState func(GeneratedState),
+) *StateGenerator {
+	generator := NewStateGenerator(statedb, blockNumber, txIndex, deleteEmpty)
+	generator.onState = onState
+	return generator
 }
 
 func (l *StateGenerator) CaptureTxStart(gasLimit uint64) {}
@@ -41,16 +61,24 @@ func (l *StateGenerator) CaptureTxStart(gasLimit uint64) {}
 func (l *StateGenerator) CaptureTxEnd(restGas uint64) {}
 
 func (l *StateGenerator) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	l.tracker.CaptureStart(env, from, to, create, input, value)
 }
 
 func (l *StateGenerator) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
-	l.states = append(l.states, GeneratedState{common.Hash{}, gas})
+	st := l.tracker.CaptureState(pc, op, gas, scope, rData)
+	if l.onState != nil {
+		l.onState(GeneratedState{st.Hash(), gas})
+		return
+	}
+	l.states = append(l.states, GeneratedState{st.Hash(), gas})
 }
 
 func (l *StateGenerator) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	l.tracker.CaptureEnter(typ, from, to, input, value)
 }
 
 func (l *StateGenerator) CaptureExit(output []byte, gasUsed uint64, err error) {
+	l.tracker.CaptureExit()
 }
 
 func (l *StateGenerator) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
@@ -0,0 +1,62 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prover
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/specularl2/specular/clients/geth/specular/proof/state"
+)
+
+// The VM hashes generated for a challenge trace must be the states the one-step prover
+// proves from, or no step of the trace could be proven.
+func TestStateGeneratorMatchesProver(t *testing.T) {
+	// Reads input, writes storage and memory, and copies the return data of a nested call.
+	calleeCode := program{}.push(0xbeef).push(0).op(vm.MSTORE).push(2).push(30).op(vm.RETURN)
+	code := program{}.push(0).op(vm.CALLDATALOAD).push(1).op(vm.SSTORE).
+		push(0x1234).push(0).op(vm.MSTORE).call(testCallee, 0, 32, 0, 32).
+		op(vm.RETURNDATASIZE).push(0).push(0).op(vm.RETURNDATACOPY, vm.STOP)
+	input := word(0x42)
+	statedb := newTestState(t, code, calleeCode)
+
+	generatorState := statedb.Copy()
+	generator := NewStateGenerator(generatorState, 1, 0, true)
+	runTest(generatorState, generator, input)
+	states, err := generator.GetGeneratedStates()
+	if err != nil {
+		t.Fatalf("failed to generate states: %v", err)
+	}
+	collectorState := statedb.Copy()
+	collector := &stateCollector{tracker: state.NewTracker(collectorState, 1, 0, true)}
+	runTest(collectorState, collector, input)
+	if len(states) != len(collector.ops) {
+		t.Fatalf("have %d generated states, want one per executed step (%d)", len(states), len(collector.ops))
+	}
+	for i, generated := range states {
+		step := uint64(i + 1)
+		proverState := statedb.Copy()
+		prover := NewProver(generated.VMHash, step, proverState, 1, 0, true)
+		runTest(proverState, prover, input)
+		osp, err := prover.GetProof()
+		if err != nil {
+			t.Fatalf("step %d (%v): failed to prove from generated state: %v", step, collector.ops[i], err)
+		}
+		r := &proofReader{t: t, data: osp.Encode()}
+		if st := r.intraState(); st.Hash() != generated.VMHash {
+			t.Fatalf("step %d (%v): proven state %s, generated %s", step, collector.ops[i], st.Hash(), generated.VMHash)
+		}
+	}
+}
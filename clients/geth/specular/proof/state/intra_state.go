@@ -0,0 +1,126 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// CallFlag is the kind of call that created a call frame.
+type CallFlag uint8
+
+const (
+	CallFlagCall CallFlag = iota
+	CallFlagCallCode
+	CallFlagDelegateCall
+	CallFlagStaticCall
+	CallFlagCreate
+	CallFlagCreate2
+)
+
+func CallFlagFromOpCode(op vm.OpCode) CallFlag {
+	switch op {
+	case vm.CALLCODE:
+		return CallFlagCallCode
+	case vm.DELEGATECALL:
+		return CallFlagDelegateCall
+	case vm.STATICCALL:
+		return CallFlagStaticCall
+	case vm.CREATE:
+		return CallFlagCreate
+	case vm.CREATE2:
+		return CallFlagCreate2
+	default:
+		return CallFlagCall
+	}
+}
+
+// Size of an encoded IntraState in bytes.
+const IntraStateSize = 8 + 8 + 2 + 8 + 8 + 32 + 20 + 20 + 32 + 1 + 8 + 8 + 8 + 32 + 8 + 8 + 32 + 8 + 32 + 8 + 32 + 8 + 32 + 32
+
+// IntraState is the EVM state before executing a step of a transaction.
+// Its hash is the VM hash of the step reported during bisection.
+type IntraState struct {
+	BlockNumber uint64
+	TxIndex     uint64
+	// Call frame
+	Depth uint16
+	Gas   uint64
+	// Refund counter of the transaction
+	Refund uint64
+	// Hash of the caller frame's state at the call, zero at depth 1
+	LastDepthHash   common.Hash
+	ContractAddress common.Address
+	Caller          common.Address
+	Value           *uint256.Int
+	CallFlag        CallFlag
+	// Location of the return data in the caller's memory
+	Out     uint64
+	OutSize uint64
+	Pc      uint64
+	// Commitments to the frame's data, see `MerkleTree` and `StackHashes`
+	CodeRoot       common.Hash
+	CodeSize       uint64
+	StackSize      uint64
+	StackHash      common.Hash
+	MemorySize     uint64
+	MemoryRoot     common.Hash
+	InputDataSize  uint64
+	InputDataRoot  common.Hash
+	ReturnDataSize uint64
+	ReturnDataRoot common.Hash
+	// World state root
+	StateRoot common.Hash
+}
+
+// Encode returns the fixed-size big-endian encoding of the state, in field order.
+func (s *IntraState) Encode() []byte {
+	encoded := make([]byte, 0, IntraStateSize)
+	encoded = binary.BigEndian.AppendUint64(encoded, s.BlockNumber)
+	encoded = binary.BigEndian.AppendUint64(encoded, s.TxIndex)
+	encoded = binary.BigEndian.AppendUint16(encoded, s.Depth)
+	encoded = binary.BigEndian.AppendUint64(encoded, s.Gas)
+	encoded = binary.BigEndian.AppendUint64(encoded, s.Refund)
+	encoded = append(encoded, s.LastDepthHash[:]...)
+	encoded = append(encoded, s.ContractAddress[:]...)
+	encoded = append(encoded, s.Caller[:]...)
+	value := s.Value.Bytes32()
+	encoded = append(encoded, value[:]...)
+	encoded = append(encoded, byte(s.CallFlag))
+	encoded = binary.BigEndian.AppendUint64(encoded, s.Out)
+	encoded = binary.BigEndian.AppendUint64(encoded, s.OutSize)
+	encoded = binary.BigEndian.AppendUint64(encoded, s.Pc)
+	encoded = append(encoded, s.CodeRoot[:]...)
+	encoded = binary.BigEndian.AppendUint64(encoded, s.CodeSize)
+	encoded = binary.BigEndian.AppendUint64(encoded, s.StackSize)
+	encoded = append(encoded, s.StackHash[:]...)
+	encoded = binary.BigEndian.AppendUint64(encoded, s.MemorySize)
+	encoded = append(encoded, s.MemoryRoot[:]...)
+	encoded = binary.BigEndian.AppendUint64(encoded, s.InputDataSize)
+	encoded = append(encoded, s.InputDataRoot[:]...)
+	encoded = binary.BigEndian.AppendUint64(encoded, s.ReturnDataSize)
+	encoded = append(encoded, s.ReturnDataRoot[:]...)
+	encoded = append(encoded, s.StateRoot[:]...)
+	return encoded
+}
+
+func (s *IntraState) Hash() common.Hash {
+	return crypto.Keccak256Hash(s.Encode())
+}
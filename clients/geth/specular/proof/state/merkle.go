@@ -0,0 +1,122 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const WordSize = 32
+
+// MerkleTree commits to a byte array (memory, code, input or return data) split into 32-byte words.
+// Leaves are the words themselves (the last one zero-padded), padded with zero words to a power of two.
+// Inner nodes are keccak256(left || right). The root of empty data is the zero hash.
+type MerkleTree struct {
+	numWords uint64
+	// levels[0] are the leaves, the last level is the root.
+	levels [][]common.Hash
+}
+
+// Returns the number of words covering `size` bytes.
+func NumWords(size uint64) uint64 {
+	return (size + WordSize - 1) / WordSize
+}
+
+func NewMerkleTree(data []byte) *MerkleTree {
+	numWords := NumWords(uint64(len(data)))
+	if numWords == 0 {
+		return &MerkleTree{}
+	}
+	width := uint64(1)
+	for width < numWords {
+		width <<= 1
+	}
+	leaves := make([]common.Hash, width)
+	for i := uint64(0); i < numWords; i++ {
+		end := (i + 1) * WordSize
+		if end > uint64(len(data)) {
+			end = uint64(len(data))
+		}
+		copy(leaves[i][:], data[i*WordSize:end])
+	}
+	tree := &MerkleTree{numWords: numWords, levels: [][]common.Hash{leaves}}
+	for level := leaves; len(level) > 1; {
+		next := make([]common.Hash, len(level)/2)
+		for i := range next {
+			next[i] = hashNode(level[2*i], level[2*i+1])
+		}
+		tree.levels = append(tree.levels, next)
+		level = next
+	}
+	return tree
+}
+
+func MerkleRoot(data []byte) common.Hash {
+	return NewMerkleTree(data).Root()
+}
+
+func (t *MerkleTree) Root() common.Hash {
+	if t.numWords == 0 {
+		return common.Hash{}
+	}
+	return t.levels[len(t.levels)-1][0]
+}
+
+func (t *MerkleTree) NumWords() uint64 {
+	return t.numWords
+}
+
+// Returns the word at `index`.
+func (t *MerkleTree) Leaf(index uint64) common.Hash {
+	return t.levels[0][index]
+}
+
+// Returns the sibling path of the word at `index`, from the leaves up.
+func (t *MerkleTree) Proof(index uint64) []common.Hash {
+	siblings := make([]common.Hash, 0, len(t.levels)-1)
+	for _, level := range t.levels[:len(t.levels)-1] {
+		siblings = append(siblings, level[index^1])
+		index >>= 1
+	}
+	return siblings
+}
+
+// Sets the word at `index`, which must be within the tree.
+func (t *MerkleTree) Update(index uint64, word common.Hash) {
+	t.levels[0][index] = word
+	for level := 1; level < len(t.levels); level++ {
+		index >>= 1
+		t.levels[level][index] = hashNode(t.levels[level-1][2*index], t.levels[level-1][2*index+1])
+	}
+}
+
+// RootFromProof computes the root of a tree from a word at `index` and its sibling path.
+func RootFromProof(index uint64, word common.Hash, siblings []common.Hash) common.Hash {
+	node := word
+	for _, sibling := range siblings {
+		if index&1 == 0 {
+			node = hashNode(node, sibling)
+		} else {
+			node = hashNode(sibling, node)
+		}
+		index >>= 1
+	}
+	return node
+}
+
+func hashNode(left, right common.Hash) common.Hash {
+	return crypto.Keccak256Hash(left[:], right[:])
+}
@@ -0,0 +1,43 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// StackHashes returns the hashes of all the prefixes of a stack (bottom first), so that
+// hashes[i] commits to the bottom i items. The hash of the empty stack is the zero hash,
+// and pushing `item` onto a stack with hash `h` gives keccak256(h || item).
+func StackHashes(items []uint256.Int) []common.Hash {
+	hashes := make([]common.Hash, len(items)+1)
+	for i := range items {
+		hashes[i+1] = PushStackHash(hashes[i], &items[i])
+	}
+	return hashes
+}
+
+func StackHash(items []uint256.Int) common.Hash {
+	hashes := StackHashes(items)
+	return hashes[len(hashes)-1]
+}
+
+// PushStackHash returns the hash of the stack with hash `h` after pushing `item`.
+func PushStackHash(h common.Hash, item *uint256.Int) common.Hash {
+	word := item.Bytes32()
+	return crypto.Keccak256Hash(h[:], word[:])
+}
@@ -0,0 +1,287 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethState "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+)
+
+// Frame is a call frame followed by the `Tracker`.
+type Frame struct {
+	Depth           uint16
+	ContractAddress common.Address
+	Caller          common.Address
+	Value           *uint256.Int
+	CallFlag        CallFlag
+	Out             uint64
+	OutSize         uint64
+	Code            []byte
+	CodeTree        *MerkleTree
+	Input           []byte
+	InputTree       *MerkleTree
+	// State of the caller frame at the call, nil at depth 1.
+	CallerState *IntraState
+	// Memory of the caller frame, written with the return data when the frame ends.
+	CallerMemory *vm.Memory
+
+	// Memory commitment, recomputed when the memory is written or expanded.
+	memoryRoot  common.Hash
+	memorySize  uint64
+	memoryStale bool
+}
+
+func newFrame(code, input []byte) *Frame {
+	return &Frame{
+		Code:        code,
+		CodeTree:    NewMerkleTree(code),
+		Input:       input,
+		InputTree:   NewMerkleTree(input),
+		memoryStale: true,
+	}
+}
+
+// Tracker follows a transaction's execution through the EVM tracer hooks
+// (forwarded by the tracer using it) and builds the `IntraState` at each step.
+type Tracker struct {
+	statedb     *gethState.StateDB
+	blockNumber uint64
+	txIndex     uint64
+	deleteEmpty bool
+
+	env    *vm.EVM
+	frames []*Frame
+	// SELFDESTRUCT reports an enter/exit pair without a call frame.
+	selfDestructs int
+
+	// World state root, recomputed on a copy of the state after steps that may modify it.
+	stateRoot  common.Hash
+	stateCopy  *gethState.StateDB
+	stateDirty bool
+
+	returnData     []byte
+	returnDataRoot common.Hash
+
+	// State at the last step, and the return data location if it is a call.
+	lastState   *IntraState
+	lastScope   *vm.ScopeContext
+	lastOut     uint64
+	lastOutSize uint64
+}
+
+func NewTracker(statedb *gethState.StateDB, blockNumber, txIndex uint64, deleteEmpty bool) *Tracker {
+	return &Tracker{
+		statedb:     statedb,
+		blockNumber: blockNumber,
+		txIndex:     txIndex,
+		deleteEmpty: deleteEmpty,
+		stateDirty:  true,
+	}
+}
+
+func (t *Tracker) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, value *big.Int) {
+	t.env = env
+	var frame *Frame
+	if create {
+		frame = newFrame(input, nil)
+		frame.CallFlag = CallFlagCreate
+	} else {
+		frame = newFrame(env.StateDB.GetCode(to), input)
+		frame.CallFlag = CallFlagCall
+	}
+	frame.Depth = 1
+	frame.ContractAddress = to
+	frame.Caller = from
+	frame.Value = toUint256(value)
+	t.frames = []*Frame{frame}
+	t.stateDirty = true
+}
+
+// CaptureState returns the state before executing `op`.
+func (t *Tracker) CaptureState(pc uint64, op vm.OpCode, gas uint64, scope *vm.ScopeContext, rData []byte) *IntraState {
+	frame := t.Frame()
+	if t.stateDirty {
+		t.stateCopy = t.statedb.Copy()
+		t.stateRoot = t.stateCopy.IntermediateRoot(t.deleteEmpty)
+		t.stateDirty = false
+	}
+	if memorySize := uint64(scope.Memory.Len()); frame.memoryStale || memorySize != frame.memorySize {
+		frame.memoryRoot = MerkleRoot(scope.Memory.Data())
+		frame.memorySize = memorySize
+	}
+	if !bytes.Equal(rData, t.returnData) {
+		t.returnData = common.CopyBytes(rData)
+		t.returnDataRoot = MerkleRoot(rData)
+	}
+	stack := scope.Stack.Data()
+	state := &IntraState{
+		BlockNumber:     t.blockNumber,
+		TxIndex:         t.txIndex,
+		Depth:           frame.Depth,
+		Gas:             gas,
+		Refund:          t.env.StateDB.GetRefund(),
+		ContractAddress: frame.ContractAddress,
+		Caller:          frame.Caller,
+		Value:           frame.Value,
+		CallFlag:        frame.CallFlag,
+		Out:             frame.Out,
+		OutSize:         frame.OutSize,
+		Pc:              pc,
+		CodeRoot:        frame.CodeTree.Root(),
+		CodeSize:        uint64(len(frame.Code)),
+		StackSize:       uint64(len(stack)),
+		StackHash:       StackHash(stack),
+		MemorySize:      frame.memorySize,
+		MemoryRoot:      frame.memoryRoot,
+		InputDataSize:   uint64(len(frame.Input)),
+		InputDataRoot:   frame.InputTree.Root(),
+		ReturnDataSize:  uint64(len(t.returnData)),
+		ReturnDataRoot:  t.returnDataRoot,
+		StateRoot:       t.stateRoot,
+	}
+	if frame.CallerState != nil {
+		state.LastDepthHash = frame.CallerState.Hash()
+	}
+
+	// Bookkeeping for the effects of `op`
+	frame.memoryStale = WritesMemory(op)
+	if ModifiesState(op) {
+		t.stateDirty = true
+	}
+	t.lastState = state
+	t.lastScope = scope
+	t.lastOut, t.lastOutSize = 0, 0
+	switch op {
+	case vm.CALL, vm.CALLCODE:
+		t.lastOut, t.lastOutSize = stackUint64(scope.Stack, 5), stackUint64(scope.Stack, 6)
+	case vm.DELEGATECALL, vm.STATICCALL:
+		t.lastOut, t.lastOutSize = stackUint64(scope.Stack, 4), stackUint64(scope.Stack, 5)
+	}
+	return state
+}
+
+func (t *Tracker) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, value *big.Int) {
+	if typ == vm.SELFDESTRUCT {
+		t.selfDestructs++
+		return
+	}
+	caller := t.Frame()
+	var frame *Frame
+	switch typ {
+	case vm.CREATE, vm.CREATE2:
+		frame = newFrame(input, nil)
+		frame.ContractAddress = to
+		frame.Caller = from
+		frame.Value = toUint256(value)
+	case vm.CALLCODE:
+		frame = newFrame(t.env.StateDB.GetCode(to), input)
+		frame.ContractAddress = from
+		frame.Caller = from
+		frame.Value = toUint256(value)
+	case vm.DELEGATECALL:
+		frame = newFrame(t.env.StateDB.GetCode(to), input)
+		frame.ContractAddress = from
+		frame.Caller = caller.Caller
+		frame.Value = caller.Value
+	default:
+		frame = newFrame(t.env.StateDB.GetCode(to), input)
+		frame.ContractAddress = to
+		frame.Caller = from
+		frame.Value = toUint256(value)
+	}
+	// Zero for creates
+	frame.Out, frame.OutSize = t.lastOut, t.lastOutSize
+	frame.Depth = caller.Depth + 1
+	frame.CallFlag = CallFlagFromOpCode(typ)
+	frame.CallerState = t.lastState
+	if t.lastScope != nil {
+		frame.CallerMemory = t.lastScope.Memory
+	}
+	t.frames = append(t.frames, frame)
+	t.stateDirty = true
+}
+
+func (t *Tracker) CaptureExit() {
+	if t.selfDestructs > 0 {
+		t.selfDestructs--
+		return
+	}
+	t.frames = t.frames[:len(t.frames)-1]
+	// The frame may have been reverted.
+	t.stateDirty = true
+}
+
+// Frame returns the current call frame.
+func (t *Tracker) Frame() *Frame {
+	return t.frames[len(t.frames)-1]
+}
+
+// StateDB returns a copy of the world state at the root of the last captured state.
+// It must not be modified.
+func (t *Tracker) StateDB() *gethState.StateDB {
+	return t.stateCopy
+}
+
+// ReturnData returns the return data of the last captured state.
+func (t *Tracker) ReturnData() []byte {
+	return t.returnData
+}
+
+// WritesMemory returns whether `op` may write to the current frame's memory.
+// Calls write their return data to the caller's memory.
+func WritesMemory(op vm.OpCode) bool {
+	switch op {
+	case vm.MSTORE, vm.MSTORE8, vm.CALLDATACOPY, vm.CODECOPY, vm.EXTCODECOPY, vm.RETURNDATACOPY,
+		vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		return true
+	default:
+		return false
+	}
+}
+
+// ModifiesState returns whether `op` may modify the world state (in its own frame or through a call).
+func ModifiesState(op vm.OpCode) bool {
+	switch op {
+	case vm.SSTORE, vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL, vm.CREATE, vm.CREATE2, vm.SELFDESTRUCT:
+		return true
+	default:
+		return false
+	}
+}
+
+// Returns the n-th stack item from the top as uint64, saturating.
+func stackUint64(stack *vm.Stack, n int) uint64 {
+	if stack.Len() <= n {
+		return 0
+	}
+	item := stack.Back(n)
+	if !item.IsUint64() {
+		return ^uint64(0)
+	}
+	return item.Uint64()
+}
+
+func toUint256(value *big.Int) *uint256.Int {
+	if value == nil {
+		return new(uint256.Int)
+	}
+	v, _ := uint256.FromBig(value)
+	return v
+}
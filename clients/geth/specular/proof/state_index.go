@@ -0,0 +1,192 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+const (
+	// defaultCheckpointInterval is the initial minimum number of steps between two checkpoints.
+	defaultCheckpointInterval = uint64(1024)
+	// maxCheckpoints bounds the number of checkpoints kept by a StateIndex.
+	// Once exceeded, every other checkpoint is dropped and the interval doubled.
+	maxCheckpoints = 4096
+	// stateCacheSize is the number of recently looked up states kept by a StateIndex.
+	stateCacheSize = 16
+)
+
+// StateIndex answers lookups of the execution states across blocks [startNum, endNum) without
+// keeping every state. It keeps checkpoints at transaction boundaries roughly every `interval` steps
+// and re-executes from the closest checkpoint before the requested state.
+// The number of checkpoints is bounded, so its size does not depend on the size of the range;
+// the re-execution cost of a lookup is about one checkpoint interval plus one transaction.
+type StateIndex struct {
+	backend Backend
+	config  *ProverConfig
+	endNum  uint64
+	length  uint64
+
+	interval    uint64
+	checkpoints []rollupTypes.TraceCheckpoint // Sorted by index
+
+	cache      map[uint64]*ExecutionState
+	cacheOrder []uint64
+}
+
+// NewStateIndex indexes the execution states across blocks [startNum, endNum) in a single pass.
+// The states are ordered as in `GenerateStates`.
+func NewStateIndex(backend Backend, ctx context.Context, startNum, endNum uint64, config *ProverConfig) (*StateIndex, error) {
+	interval := defaultCheckpointInterval
+	if config != nil && config.CheckpointInterval != 0 {
+		interval = config.CheckpointInterval
+	}
+	index := &StateIndex{
+		backend:  backend,
+		config:   config,
+		endNum:   endNum,
+		interval: interval,
+		// The range can always be replayed from its start, even if it has no transactions.
+		checkpoints: []rollupTypes.TraceCheckpoint{{Index: 0, BlockNumber: startNum, TransactionIdx: 0}},
+		cache:       make(map[uint64]*ExecutionState),
+	}
+	err := WalkStates(backend, ctx, startNum, endNum, config, func(i uint64, s *ExecutionState) bool {
+		index.length = i + 1
+		if s.StepIdx == 0 && s.TransactionIdx < uint64(len(s.Block.Transactions())) {
+			index.addCheckpoint(rollupTypes.TraceCheckpoint{Index: i, BlockNumber: s.Block.NumberU64(), TransactionIdx: s.TransactionIdx})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// RestoreStateIndex reopens an index of the execution states up to block `endNum` (excluded) from its
+// persisted form (see `TraceIndex`), without re-executing the range.
+func RestoreStateIndex(backend Backend, endNum uint64, config *ProverConfig, stored *rollupTypes.TraceIndex) (*StateIndex, error) {
+	if stored.Length == 0 || stored.Interval == 0 || len(stored.Checkpoints) == 0 || stored.Checkpoints[0].Index != 0 {
+		return nil, fmt.Errorf("invalid state index of %d states and %d checkpoints", stored.Length, len(stored.Checkpoints))
+	}
+	for i, checkpoint := range stored.Checkpoints {
+		if checkpoint.Index >= stored.Length || checkpoint.BlockNumber >= endNum ||
+			(i > 0 && checkpoint.Index <= stored.Checkpoints[i-1].Index) {
+			return nil, fmt.Errorf("invalid checkpoint %d of state index", i)
+		}
+	}
+	return &StateIndex{
+		backend:     backend,
+		config:      config,
+		endNum:      endNum,
+		length:      stored.Length,
+		interval:    stored.Interval,
+		checkpoints: stored.Checkpoints,
+		cache:       make(map[uint64]*ExecutionState),
+	}, nil
+}
+
+// TraceIndex returns the persisted form of the index, which can be reopened with `RestoreStateIndex`.
+func (s *StateIndex) TraceIndex() *rollupTypes.TraceIndex {
+	return &rollupTypes.TraceIndex{
+		Length:      s.length,
+		Interval:    s.interval,
+		Checkpoints: s.checkpoints,
+	}
+}
+
+// Len returns the number of execution states in the index.
+func (s *StateIndex) Len() uint64 {
+	return s.length
+}
+
+// State returns the `i`-th execution state.
+func (s *StateIndex) State(ctx context.Context, i uint64) (*ExecutionState, error) {
+	if i >= s.length {
+		return nil, fmt.Errorf("state index %d out of range [0, %d)", i, s.length)
+	}
+	if state, ok := s.cache[i]; ok {
+		return state, nil
+	}
+	var found *ExecutionState
+	err := walkStates(s.backend, ctx, s.checkpointBefore(i), s.endNum, s.config, func(j uint64, state *ExecutionState) bool {
+		if j == i {
+			found = state
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("state %d not found", i)
+	}
+	s.cacheState(i, found)
+	return found, nil
+}
+
+// Hash returns the VM hash of the `i`-th execution state.
+func (s *StateIndex) Hash(ctx context.Context, i uint64) (common.Hash, error) {
+	state, err := s.State(ctx, i)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return state.Hash(), nil
+}
+
+func (s *StateIndex) addCheckpoint(checkpoint rollupTypes.TraceCheckpoint) {
+	if len(s.checkpoints) > 0 && checkpoint.Index-s.checkpoints[len(s.checkpoints)-1].Index < s.interval {
+		return
+	}
+	s.checkpoints = append(s.checkpoints, checkpoint)
+	if len(s.checkpoints) <= maxCheckpoints {
+		return
+	}
+	// Thin out: keep every other checkpoint (always including the first one).
+	thinned := s.checkpoints[:0]
+	for i := 0; i < len(s.checkpoints); i += 2 {
+		thinned = append(thinned, s.checkpoints[i])
+	}
+	s.checkpoints = thinned
+	s.interval *= 2
+}
+
+// checkpointBefore returns the last checkpoint at or before state `i`.
+func (s *StateIndex) checkpointBefore(i uint64) rollupTypes.TraceCheckpoint {
+	lo, hi := 0, len(s.checkpoints)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if s.checkpoints[mid].Index <= i {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return s.checkpoints[lo-1]
+}
+
+func (s *StateIndex) cacheState(i uint64, state *ExecutionState) {
+	if len(s.cacheOrder) >= stateCacheSize {
+		delete(s.cache, s.cacheOrder[0])
+		s.cacheOrder = s.cacheOrder[1:]
+	}
+	s.cache[i] = state
+	s.cacheOrder = append(s.cacheOrder, i)
+}
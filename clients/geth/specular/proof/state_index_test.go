@@ -0,0 +1,114 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"context"
+	"testing"
+
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+)
+
+func checkStateIndex(t *testing.T, index *StateIndex, want []*ExecutionState) {
+	t.Helper()
+	if index.Len() != uint64(len(want)) {
+		t.Fatalf("have %d states, want %d", index.Len(), len(want))
+	}
+	// Backwards, so that every lookup replays from its checkpoint.
+	for i := len(want) - 1; i >= 0; i-- {
+		w := want[i]
+		s, err := index.State(context.Background(), uint64(i))
+		if err != nil {
+			t.Fatalf("failed to look up state %d: %v", i, err)
+		}
+		if s.VMHash != w.VMHash || s.Block.Hash() != w.Block.Hash() || s.TransactionIdx != w.TransactionIdx || s.StepIdx != w.StepIdx {
+			t.Fatalf("state %d: have %+v, want %+v", i, s, w)
+		}
+	}
+	if _, err := index.State(context.Background(), index.Len()); err == nil {
+		t.Fatal("looked up state past the end of the index")
+	}
+}
+
+func TestStateIndex(t *testing.T) {
+	backend := newTestBackend(t)
+	ctx := context.Background()
+	want, err := GenerateStates(backend, ctx, 1, 2, nil)
+	if err != nil {
+		t.Fatalf("failed to generate states: %v", err)
+	}
+	// A checkpoint before each of the 2 txs of block #1, the second one replayed from its transaction.
+	index, err := NewStateIndex(backend, ctx, 1, 2, &ProverConfig{CheckpointInterval: 1})
+	if err != nil {
+		t.Fatalf("failed to index states: %v", err)
+	}
+	stored := index.TraceIndex()
+	if len(stored.Checkpoints) != 2 || stored.Checkpoints[1].TransactionIdx != 1 {
+		t.Fatalf("have checkpoints %+v, want one per transaction", stored.Checkpoints)
+	}
+	checkStateIndex(t, index, want)
+
+	restored, err := RestoreStateIndex(backend, 2, nil, stored)
+	if err != nil {
+		t.Fatalf("failed to restore index: %v", err)
+	}
+	checkStateIndex(t, restored, want)
+}
+
+func TestRestoreStateIndexInvalid(t *testing.T) {
+	backend := newTestBackend(t)
+	checkpoints := []rollupTypes.TraceCheckpoint{{Index: 0, BlockNumber: 1}, {Index: 5, BlockNumber: 1, TransactionIdx: 1}}
+	tests := []*rollupTypes.TraceIndex{
+		{Length: 10, Interval: 1},
+		{Length: 0, Interval: 1, Checkpoints: checkpoints},
+		{Length: 10, Interval: 0, Checkpoints: checkpoints},
+		// Checkpoint past the end of the trace
+		{Length: 5, Interval: 1, Checkpoints: checkpoints},
+		// Unsorted checkpoints
+		{Length: 10, Interval: 1, Checkpoints: []rollupTypes.TraceCheckpoint{checkpoints[0], checkpoints[1], checkpoints[1]}},
+		// No checkpoint at the start of the trace
+		{Length: 10, Interval: 1, Checkpoints: checkpoints[1:]},
+	}
+	for i, stored := range tests {
+		if _, err := RestoreStateIndex(backend, 2, nil, stored); err == nil {
+			t.Errorf("test %d: restored invalid index %+v", i, stored)
+		}
+	}
+}
+
+// The number of checkpoints is bounded by thinning them out, doubling the interval.
+func TestStateIndexThinning(t *testing.T) {
+	index := &StateIndex{interval: 1, checkpoints: []rollupTypes.TraceCheckpoint{{Index: 0}}}
+	for i := uint64(1); i <= 2*maxCheckpoints; i++ {
+		index.addCheckpoint(rollupTypes.TraceCheckpoint{Index: i})
+	}
+	if len(index.checkpoints) > maxCheckpoints {
+		t.Fatalf("have %d checkpoints, want at most %d", len(index.checkpoints), maxCheckpoints)
+	}
+	if index.checkpoints[0].Index != 0 {
+		t.Fatal("first checkpoint dropped")
+	}
+	for i := 1; i < len(index.checkpoints); i++ {
+		if gap := index.checkpoints[i].Index - index.checkpoints[i-1].Index; gap > index.interval {
+			t.Fatalf("checkpoints %d and %d are %d steps apart, interval %d", i-1, i, gap, index.interval)
+		}
+	}
+	if want := uint64(4); index.interval != want {
+		t.Fatalf("have interval %d, want %d", index.interval, want)
+	}
+	if have := index.checkpointBefore(11); have.Index != 8 {
+		t.Fatalf("have checkpoint %d before state 11, want 8", have.Index)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/specularl2/specular/clients/geth/specular/bindings"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// InboxTx is a transaction as accumulated by `SequencerInbox.appendTxBatch`.
+type InboxTx struct {
+	ContextHash common.Hash
+	DataHash    common.Hash
+}
+
+// TxContextHash returns keccak256(abi.encodePacked(sequencer, blockNumber, timestamp)),
+// the context hash of the txs of an L2 block in the sequencer inbox.
+func TxContextHash(sequencer common.Address, blockNumber, timestamp uint64) common.Hash {
+	encoded := make([]byte, 0, 20+32+32)
+	encoded = append(encoded, sequencer[:]...)
+	encoded = append(encoded, common.BigToHash(new(big.Int).SetUint64(blockNumber)).Bytes()...)
+	encoded = append(encoded, common.BigToHash(new(big.Int).SetUint64(timestamp)).Bytes()...)
+	return crypto.Keccak256Hash(encoded)
+}
+
+// AccumulateTx returns keccak256(abi.encodePacked(acc, txNumber, contextHash, dataHash)),
+// the inbox accumulator after appending `tx` as the `txNumber`-th tx.
+func AccumulateTx(acc common.Hash, txNumber uint64, tx InboxTx) common.Hash {
+	number := common.BigToHash(new(big.Int).SetUint64(txNumber))
+	return crypto.Keccak256Hash(acc[:], number[:], tx.ContextHash[:], tx.DataHash[:])
+}
+
+// NewInboxTxs returns the txs of `batch` as accumulated by the sequencer inbox.
+func NewInboxTxs(batch *rollupTypes.TxBatch, sequencer common.Address) ([]InboxTx, error) {
+	txs := make([]InboxTx, 0, len(batch.Txs))
+	for _, block := range batch.SplitToBlocks() {
+		contextHash := TxContextHash(sequencer, block.BlockNumber, block.Timestamp)
+		for _, tx := range block.Txs {
+			encoded, err := rlp.EncodeToBytes(tx)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to encode tx %s, err: %w", tx.Hash(), err)
+			}
+			txs = append(txs, InboxTx{ContextHash: contextHash, DataHash: crypto.Keccak256Hash(encoded)})
+		}
+	}
+	return txs, nil
+}
+
+// TxInclusionProof proves the inclusion of a tx in a batch of the sequencer inbox,
+// see `SequencerInbox.verifyTxInclusion`.
+// Encoding: tx context hash (bytes32) || batch number (uint256) || number of txs before the tx (uint256) ||
+// number of txs after the tx in the batch (uint256) || accumulator before the tx (bytes32) ||
+// (context hash (bytes32) || data hash (bytes32)) for each tx after the tx in the batch.
+type TxInclusionProof struct {
+	TxContextHash common.Hash
+	BatchNumber   uint64
+	NumTxsBefore  uint64
+	AccBefore     common.Hash
+	TxsAfter      []InboxTx
+}
+
+// NewTxInclusionProof proves the inclusion of `txs[index]` in batch `batchNumber`, which contains `txs` and
+// starts at tx number `startTxNumber`, with accumulator `prevAcc` before it (zero for the first batch).
+func NewTxInclusionProof(batchNumber, startTxNumber uint64, prevAcc common.Hash, txs []InboxTx, index int) (*TxInclusionProof, error) {
+	if index < 0 || index >= len(txs) {
+		return nil, fmt.Errorf("tx index %d out of range of batch #%d with %d txs", index, batchNumber, len(txs))
+	}
+	acc := prevAcc
+	for i := 0; i < index; i++ {
+		acc = AccumulateTx(acc, startTxNumber+uint64(i), txs[i])
+	}
+	return &TxInclusionProof{
+		TxContextHash: txs[index].ContextHash,
+		BatchNumber:   batchNumber,
+		NumTxsBefore:  startTxNumber + uint64(index),
+		AccBefore:     acc,
+		TxsAfter:      txs[index+1:],
+	}, nil
+}
+
+// Accumulator returns the accumulator at the end of the batch if the proven tx is `encodedTx`.
+// It is the value the sequencer inbox checks against.
+func (p *TxInclusionProof) Accumulator(encodedTx []byte) common.Hash {
+	acc := AccumulateTx(p.AccBefore, p.NumTxsBefore, InboxTx{ContextHash: p.TxContextHash, DataHash: crypto.Keccak256Hash(encodedTx)})
+	for i, tx := range p.TxsAfter {
+		acc = AccumulateTx(acc, p.NumTxsBefore+1+uint64(i), tx)
+	}
+	return acc
+}
+
+func (p *TxInclusionProof) Encode() []byte {
+	encoded := make([]byte, 0, 32*5+64*len(p.TxsAfter))
+	encoded = append(encoded, p.TxContextHash[:]...)
+	encoded = appendUint256(encoded, p.BatchNumber)
+	encoded = appendUint256(encoded, p.NumTxsBefore)
+	encoded = appendUint256(encoded, uint64(len(p.TxsAfter)))
+	encoded = append(encoded, p.AccBefore[:]...)
+	for _, tx := range p.TxsAfter {
+		encoded = append(encoded, tx.ContextHash[:]...)
+		encoded = append(encoded, tx.DataHash[:]...)
+	}
+	return encoded
+}
+
+func appendUint256(encoded []byte, v uint64) []byte {
+	encoded = append(encoded, make([]byte, 24)...)
+	return binary.BigEndian.AppendUint64(encoded, v)
+}
+
+// NewRawContext returns the verification context of the tx executed from `state`.
+// The L2 block coinbase is the sequencer, so that the context hashes to the tx's context hash in the inbox.
+func NewRawContext(state *ExecutionState) (bindings.VerificationContextLibRawContext, error) {
+	if state.Block == nil || state.TransactionIdx >= uint64(len(state.Block.Transactions())) {
+		return bindings.VerificationContextLibRawContext{}, fmt.Errorf("bad state: no transaction executed")
+	}
+	tx := state.Block.Transactions()[state.TransactionIdx]
+	encoded, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return bindings.VerificationContextLibRawContext{}, fmt.Errorf("Failed to encode tx %s, err: %w", tx.Hash(), err)
+	}
+	return bindings.VerificationContextLibRawContext{
+		EncodedTx:        encoded,
+		L2BlockCoinbase:  state.Block.Coinbase(),
+		L2BlockNumber:    state.Block.Number(),
+		L2BlockTimestamp: new(big.Int).SetUint64(state.Block.Time()),
+	}, nil
+}
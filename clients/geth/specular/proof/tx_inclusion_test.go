@@ -0,0 +1,95 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Golden vectors shared with `SequencerInboxTest.test_verifyTxInclusion_goldenVector` in contracts/test/SequencerInbox.t.sol:
+// batch #0 has txs 0x01, 0x0203 in block (1, 100), batch #1 has tx 0xc0ffee in block (2, 200) and
+// txs 0xdeadbeef, 0xaa in block (3, 300), all sequenced by `goldenSequencer`.
+var (
+	goldenSequencer    = common.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	goldenContextHash  = common.HexToHash("0x57f7eec821c8381233b0a3284d5d62a00e5dcdd1d2804858f3f42c5c64dbe01b")
+	goldenAccumulator0 = common.HexToHash("0xd7ac53ab717aa10baa8d028fb79ad48f36d3a232bf74411bbda6d1aa533d803c")
+	goldenAccumulator1 = common.HexToHash("0x1a7be89f002b9690ba8013163943a14c63acbe15f26ba0cf1586d87afb7e8322")
+	// Proof of inclusion of 0xdeadbeef in batch #1
+	goldenProof = common.FromHex(
+		"0x05c1ae3e8ccf719f7b0ce88b878969b50451d934da33d02de0697ef82fdf289c" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"0000000000000000000000000000000000000000000000000000000000000003" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"0f3a131e12848475fabb719e8608bb7a22b9bec0387b4e01f6f905abc1e93da9" +
+			"05c1ae3e8ccf719f7b0ce88b878969b50451d934da33d02de0697ef82fdf289c" +
+			"db81b4d58595fbbbb592d3661a34cdca14d7ab379441400cbfa1b78bc447c365",
+	)
+)
+
+func goldenInboxTxs(blockNumber, timestamp uint64, data ...[]byte) []InboxTx {
+	contextHash := TxContextHash(goldenSequencer, blockNumber, timestamp)
+	txs := make([]InboxTx, len(data))
+	for i, d := range data {
+		txs[i] = InboxTx{ContextHash: contextHash, DataHash: crypto.Keccak256Hash(d)}
+	}
+	return txs
+}
+
+func TestTxContextHash(t *testing.T) {
+	if hash := TxContextHash(goldenSequencer, 1, 100); hash != goldenContextHash {
+		t.Fatalf("context hash mismatch: have %s, want %s", hash, goldenContextHash)
+	}
+}
+
+func TestTxInclusionProof(t *testing.T) {
+	batch0 := goldenInboxTxs(1, 100, []byte{0x01}, []byte{0x02, 0x03})
+	batch1 := append(
+		goldenInboxTxs(2, 200, []byte{0xc0, 0xff, 0xee}),
+		goldenInboxTxs(3, 300, []byte{0xde, 0xad, 0xbe, 0xef}, []byte{0xaa})...,
+	)
+
+	// The accumulator of batch #0 is that of a proof of its last tx.
+	proof0, err := NewTxInclusionProof(0, 0, common.Hash{}, batch0, 1)
+	if err != nil {
+		t.Fatalf("failed to build proof: %v", err)
+	}
+	if acc := proof0.Accumulator([]byte{0x02, 0x03}); acc != goldenAccumulator0 {
+		t.Fatalf("accumulator mismatch: have %s, want %s", acc, goldenAccumulator0)
+	}
+
+	proof1, err := NewTxInclusionProof(1, uint64(len(batch0)), goldenAccumulator0, batch1, 1)
+	if err != nil {
+		t.Fatalf("failed to build proof: %v", err)
+	}
+	if encoded := proof1.Encode(); !bytes.Equal(encoded, goldenProof) {
+		t.Fatalf("proof mismatch:\nhave %x\nwant %x", encoded, goldenProof)
+	}
+	if acc := proof1.Accumulator([]byte{0xde, 0xad, 0xbe, 0xef}); acc != goldenAccumulator1 {
+		t.Fatalf("accumulator mismatch: have %s, want %s", acc, goldenAccumulator1)
+	}
+	if acc := proof1.Accumulator([]byte{0xde, 0xad}); acc == goldenAccumulator1 {
+		t.Fatal("accumulator of a different tx matches")
+	}
+
+	for _, index := range []int{-1, len(batch1)} {
+		if _, err := NewTxInclusionProof(1, 2, goldenAccumulator0, batch1, index); err == nil {
+			t.Fatalf("expected an error for index %d", index)
+		}
+	}
+}
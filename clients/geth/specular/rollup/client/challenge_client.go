@@ -0,0 +1,346 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/specularl2/specular/clients/geth/specular/bindings"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// ChallengeType is the challenge protocol implemented by a challenge contract (`IChallenge.ChallengeType`).
+type ChallengeType uint8
+
+const (
+	SymChallengeType  ChallengeType = iota // ISymChallenge.sol
+	AsymChallengeType                      // IAsymChallenge.sol
+)
+
+func ParseChallengeType(s string) (ChallengeType, error) {
+	switch s {
+	case "sym":
+		return SymChallengeType, nil
+	case "asym":
+		return AsymChallengeType, nil
+	default:
+		return 0, fmt.Errorf("unknown challenge type %q", s)
+	}
+}
+
+func (t ChallengeType) String() string {
+	switch t {
+	case SymChallengeType:
+		return "sym"
+	case AsymChallengeType:
+		return "asym"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
+// ChallengeClient interacts with a single challenge contract (IChallenge.sol).
+// It is either a `SymChallengeClient` or an `AsymChallengeClient`, depending on its `Type`.
+type ChallengeClient interface {
+	Address() common.Address
+	Type() ChallengeType
+	InitializeChallengeLength(numSteps *big.Int) (*TxResult, error)
+	CurrentChallengeResponder() (common.Address, error)
+	CurrentChallengeResponderTimeLeft() (*big.Int, error)
+	TimeoutChallenge() (*TxResult, error)
+}
+
+// SymChallengeClient interacts with a symmetric challenge contract (ISymChallenge in IChallenge.sol).
+type SymChallengeClient interface {
+	ChallengeClient
+	BisectExecution(
+		bisection [][32]byte,
+		challengedSegmentIndex *big.Int,
+		prevBisection [][32]byte,
+		prevChallengedSegmentStart *big.Int,
+		prevChallengedSegmentLength *big.Int,
+	) (*TxResult, error)
+	VerifyOneStepProof(
+		proof []byte,
+		txInclusionProof []byte,
+		verificationRawCtx bindings.VerificationContextLibRawContext,
+		challengedStepIndex *big.Int,
+		prevBisection [][32]byte,
+		prevChallengedSegmentStart *big.Int,
+		prevChallengedSegmentLength *big.Int,
+	) (*TxResult, error)
+	FilterBisected(opts *bind.FilterOpts) (*bindings.ISymChallengeBisectedIterator, error)
+	FilterChallengeCompleted(opts *bind.FilterOpts) (*bindings.ISymChallengeCompletedIterator, error)
+	DecodeBisectExecutionInput(tx *types.Transaction) ([]interface{}, error)
+}
+
+// AsymChallengeClient interacts with an asymmetric challenge contract (IAsymChallenge in IChallenge.sol).
+type AsymChallengeClient interface {
+	ChallengeClient
+	BisectExecution(bisection [][32]byte) (*TxResult, error)
+	ChallengeExecution(challengedSegmentIndex *big.Int, prevBisection [][32]byte) (*TxResult, error)
+	VerifyOneStepProof(
+		proof []byte,
+		txInclusionProof []byte,
+		verificationRawCtx bindings.VerificationContextLibRawContext,
+		challengedStepIndex *big.Int,
+		prevBisection [][32]byte,
+	) (*TxResult, error)
+	FilterBisected(opts *bind.FilterOpts) (*bindings.IAsymChallengeBisectedIterator, error)
+	FilterSegmentChallenged(opts *bind.FilterOpts) (*bindings.IAsymChallengeSegmentChallengedIterator, error)
+	FilterChallengeCompleted(opts *bind.FilterOpts) (*bindings.IAsymChallengeCompletedIterator, error)
+	DecodeBisectExecutionInput(tx *types.Transaction) ([]interface{}, error)
+}
+
+// Creates a client of the challenge contract at `challengeAddress`, for the protocol it implements (see `GetChallengeType`).
+func (c *EthBridgeClient) NewChallengeClient(ctx context.Context, challengeAddress common.Address) (ChallengeClient, error) {
+	challengeType, err := c.GetChallengeType(ctx, challengeAddress)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch challengeType {
+	case SymChallengeType:
+		challenge, err := bindings.NewISymChallenge(challengeAddress, c.client)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to initialize challenge contract, err: %w", err)
+		}
+		return &EthSymChallengeClient{
+			ethChallengeClient: ethChallengeClient{bridge: c, address: challengeAddress, abi: c.challengeAbi},
+			challenge: &bindings.ISymChallengeSession{
+				Contract:     challenge,
+				CallOpts:     bind.CallOpts{Pending: true, Context: ctx},
+				TransactOpts: *c.transactOpts,
+			},
+		}, nil
+	case AsymChallengeType:
+		challenge, err := bindings.NewIAsymChallenge(challengeAddress, c.client)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to initialize challenge contract, err: %w", err)
+		}
+		return &EthAsymChallengeClient{
+			ethChallengeClient: ethChallengeClient{bridge: c, address: challengeAddress, abi: c.asymChallengeAbi},
+			challenge: &bindings.IAsymChallengeSession{
+				Contract:     challenge,
+				CallOpts:     bind.CallOpts{Pending: true, Context: ctx},
+				TransactOpts: *c.transactOpts,
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("Unsupported challenge type %s", challengeType)
+	}
+}
+
+// Gets the challenge protocol implemented by the challenge contract at `challengeAddress`.
+// Contracts that do not report their type (`IChallenge.challengeType`) are assumed to implement
+// the configured default protocol.
+func (c *EthBridgeClient) GetChallengeType(ctx context.Context, challengeAddress common.Address) (ChallengeType, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// `challengeType` is declared in `IChallenge`, so any challenge binding can call it.
+	caller, err := bindings.NewISymChallengeCaller(challengeAddress, c.client)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to initialize challenge contract, err: %w", err)
+	}
+	challengeType, err := caller.ChallengeType(&bind.CallOpts{Pending: true, Context: ctx})
+	if err != nil {
+		log.Warn(
+			"Challenge contract does not report its type, assuming default",
+			"address", challengeAddress, "type", c.challengeType, "err", err,
+		)
+		return c.challengeType, nil
+	}
+	if ChallengeType(challengeType) > AsymChallengeType {
+		return 0, fmt.Errorf("Unknown challenge type %d (address=%s)", challengeType, challengeAddress)
+	}
+	return ChallengeType(challengeType), nil
+}
+
+// Shared by the clients of both challenge protocols.
+// Shares the connection and the tx manager of the `EthBridgeClient` it was created from.
+type ethChallengeClient struct {
+	bridge  *EthBridgeClient
+	address common.Address
+	abi     *abi.ABI
+	// Lock, conservatively on all functions.
+	// Not held while waiting for transactions.
+	mu sync.Mutex
+}
+
+func (c *ethChallengeClient) Address() common.Address {
+	return c.address
+}
+
+func (c *ethChallengeClient) transact(method string, args ...interface{}) (*TxResult, error) {
+	return c.bridge.transact(c.address, c.abi, nil, method, args...)
+}
+
+func (c *ethChallengeClient) InitializeChallengeLength(numSteps *big.Int) (*TxResult, error) {
+	return c.transact("initializeChallengeLength", numSteps)
+}
+
+func (c *ethChallengeClient) TimeoutChallenge() (*TxResult, error) {
+	return c.transact("timeout")
+}
+
+// Decodes the input of a `bisectExecution` call, failing for calls to other methods.
+func (c *ethChallengeClient) DecodeBisectExecutionInput(tx *types.Transaction) ([]interface{}, error) {
+	method := c.abi.Methods["bisectExecution"]
+	if len(tx.Data()) < 4 || !bytes.Equal(tx.Data()[:4], method.ID) {
+		return nil, fmt.Errorf("Not a bisectExecution call (tx=%s)", tx.Hash())
+	}
+	return method.Inputs.Unpack(tx.Data()[4:])
+}
+
+// Thread-safe client of a symmetric challenge contract.
+type EthSymChallengeClient struct {
+	ethChallengeClient
+	challenge *bindings.ISymChallengeSession
+}
+
+func (c *EthSymChallengeClient) Type() ChallengeType {
+	return SymChallengeType
+}
+
+func (c *EthSymChallengeClient) CurrentChallengeResponder() (common.Address, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.challenge.CurrentResponder()
+}
+
+func (c *EthSymChallengeClient) CurrentChallengeResponderTimeLeft() (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.challenge.CurrentResponderTimeLeft()
+}
+
+func (c *EthSymChallengeClient) BisectExecution(
+	bisection [][32]byte,
+	challengedSegmentIndex *big.Int,
+	prevBisection [][32]byte,
+	prevChallengedSegmentStart *big.Int,
+	prevChallengedSegmentLength *big.Int,
+) (*TxResult, error) {
+	return c.transact(
+		"bisectExecution",
+		bisection,
+		challengedSegmentIndex,
+		prevBisection,
+		prevChallengedSegmentStart,
+		prevChallengedSegmentLength,
+	)
+}
+
+func (c *EthSymChallengeClient) VerifyOneStepProof(
+	proof []byte,
+	txInclusionProof []byte,
+	verificationRawCtx bindings.VerificationContextLibRawContext,
+	challengedStepIndex *big.Int,
+	prevBisection [][32]byte,
+	prevChallengedSegmentStart *big.Int,
+	prevChallengedSegmentLength *big.Int,
+) (*TxResult, error) {
+	return c.transact(
+		"verifyOneStepProof",
+		proof,
+		txInclusionProof,
+		verificationRawCtx,
+		challengedStepIndex,
+		prevBisection,
+		prevChallengedSegmentStart,
+		prevChallengedSegmentLength,
+	)
+}
+
+func (c *EthSymChallengeClient) FilterBisected(opts *bind.FilterOpts) (*bindings.ISymChallengeBisectedIterator, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.challenge.Contract.FilterBisected(opts)
+}
+
+func (c *EthSymChallengeClient) FilterChallengeCompleted(
+	opts *bind.FilterOpts,
+) (*bindings.ISymChallengeCompletedIterator, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.challenge.Contract.FilterCompleted(opts)
+}
+
+// Thread-safe client of an asymmetric challenge contract.
+type EthAsymChallengeClient struct {
+	ethChallengeClient
+	challenge *bindings.IAsymChallengeSession
+}
+
+func (c *EthAsymChallengeClient) Type() ChallengeType {
+	return AsymChallengeType
+}
+
+func (c *EthAsymChallengeClient) CurrentChallengeResponder() (common.Address, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.challenge.CurrentResponder()
+}
+
+func (c *EthAsymChallengeClient) CurrentChallengeResponderTimeLeft() (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.challenge.CurrentResponderTimeLeft()
+}
+
+func (c *EthAsymChallengeClient) BisectExecution(bisection [][32]byte) (*TxResult, error) {
+	return c.transact("bisectExecution", bisection)
+}
+
+func (c *EthAsymChallengeClient) ChallengeExecution(
+	challengedSegmentIndex *big.Int,
+	prevBisection [][32]byte,
+) (*TxResult, error) {
+	return c.transact("challengeExecution", challengedSegmentIndex, prevBisection)
+}
+
+func (c *EthAsymChallengeClient) VerifyOneStepProof(
+	proof []byte,
+	txInclusionProof []byte,
+	verificationRawCtx bindings.VerificationContextLibRawContext,
+	challengedStepIndex *big.Int,
+	prevBisection [][32]byte,
+) (*TxResult, error) {
+	return c.transact(
+		"verifyOneStepProof",
+		proof,
+		txInclusionProof,
+		verificationRawCtx,
+		challengedStepIndex,
+		prevBisection,
+	)
+}
+
+func (c *EthAsymChallengeClient) FilterBisected(opts *bind.FilterOpts) (*bindings.IAsymChallengeBisectedIterator, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.challenge.Contract.FilterBisected(opts)
+}
+
+func (c *EthAsymChallengeClient) FilterSegmentChallenged(
+	opts *bind.FilterOpts,
+) (*bindings.IAsymChallengeSegmentChallengedIterator, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.challenge.Contract.FilterSegmentChallenged(opts)
+}
+
+func (c *EthAsymChallengeClient) FilterChallengeCompleted(
+	opts *bind.FilterOpts,
+) (*bindings.IAsymChallengeCompletedIterator, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.challenge.Contract.FilterCompleted(opts)
+}
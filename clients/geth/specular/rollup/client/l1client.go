@@ -20,7 +20,9 @@ const syncRange uint64 = 10000
 
 type L1BridgeClient interface {
 	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
 	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
 	ResubscribeErrNewHead(ctx context.Context, sink chan<- *types.Header) (event.Subscription, error)
 	SubscribeNewHeadByPolling(
 		ctx context.Context,
@@ -30,19 +32,22 @@ type L1BridgeClient interface {
 		requestTimeout time.Duration,
 	) event.Subscription
 	Close()
+	// Sender returns the address our L1 txs are sent from.
+	Sender() common.Address
 	// ISequencerInbox.sol
-	AppendTxBatch(contexts []*big.Int, txLengths []*big.Int, txBatch []byte) (*types.Transaction, error)
+	AppendTxBatch(contexts []*big.Int, txLengths []*big.Int, txBatch []byte) (*TxResult, error)
 	WatchTxBatchAppended(opts *bind.WatchOpts, sink chan<- *bindings.ISequencerInboxTxBatchAppended) (event.Subscription, error)
 	FilterTxBatchAppendedEvents(opts *bind.FilterOpts) (*bindings.ISequencerInboxTxBatchAppendedIterator, error)
 	DecodeAppendTxBatchInput(tx *types.Transaction) ([]interface{}, error)
+	GetAccumulator(batchNumber uint64) (common.Hash, error)
 	// IRollup.sol
 	Stake(amount *big.Int) error
 	GetStaker() (bindings.IRollupStaker, error)
-	AdvanceStake(assertionID *big.Int) (*types.Transaction, error)
-	CreateAssertion(vmHash [32]byte, inboxSize *big.Int) (*types.Transaction, error)
-	ChallengeAssertion(players [2]common.Address, assertionIDs [2]*big.Int) (*types.Transaction, error)
-	ConfirmFirstUnresolvedAssertion() (*types.Transaction, error)
-	RejectFirstUnresolvedAssertion(stakerAddress common.Address) (*types.Transaction, error)
+	AdvanceStake(assertionID *big.Int) (*TxResult, error)
+	CreateAssertion(vmHash [32]byte, inboxSize *big.Int) (*TxResult, error)
+	ChallengeAssertion(players [2]common.Address, assertionIDs [2]*big.Int) (*TxResult, error)
+	ConfirmFirstUnresolvedAssertion() (*TxResult, error)
+	RejectFirstUnresolvedAssertion(stakerAddress common.Address) (*TxResult, error)
 	GetLastValidatedAssertionID(opts *bind.FilterOpts) (*big.Int, error)
 	GetAssertion(assertionID *big.Int) (bindings.IRollupAssertion, error)
 	WatchAssertionCreated(opts *bind.WatchOpts, sink chan<- *bindings.IRollupAssertionCreated) (event.Subscription, error)
@@ -55,50 +60,34 @@ type L1BridgeClient interface {
 	FilterAssertionRejected(opts *bind.FilterOpts) (*bindings.IRollupAssertionRejectedIterator, error)
 	GetGenesisAssertionCreated(opts *bind.FilterOpts) (*bindings.IRollupAssertionCreated, error)
 	// IChallenge.sol
-	InitNewChallengeSession(ctx context.Context, challengeAddress common.Address) error
-	InitializeChallengeLength(numSteps *big.Int) (*types.Transaction, error)
-	CurrentChallengeResponder() (common.Address, error)
-	CurrentChallengeResponderTimeLeft() (*big.Int, error)
-	TimeoutChallenge() (*types.Transaction, error)
-	BisectExecution(
-		bisection [][32]byte,
-		challengedSegmentIndex *big.Int,
-		prevBisection [][32]byte,
-		prevChallengedSegmentStart *big.Int,
-		prevChallengedSegmentLength *big.Int,
-	) (*types.Transaction, error)
-	VerifyOneStepProof(
-		proof []byte,
-		txInclusionProof []byte,
-		verificationRawCtx bindings.VerificationContextLibRawContext,
-		challengedStepIndex *big.Int,
-		prevBisection [][32]byte,
-		prevChallengedSegmentStart *big.Int,
-		prevChallengedSegmentLength *big.Int,
-	) (*types.Transaction, error)
-	WatchBisected(opts *bind.WatchOpts, sink chan<- *bindings.ISymChallengeBisected) (event.Subscription, error)
-	WatchChallengeCompleted(opts *bind.WatchOpts, sink chan<- *bindings.ISymChallengeCompleted) (event.Subscription, error)
-	FilterBisected(opts *bind.FilterOpts) (*bindings.ISymChallengeBisectedIterator, error)
-	FilterChallengeCompleted(opts *bind.FilterOpts) (*bindings.ISymChallengeCompletedIterator, error)
-	DecodeBisectExecutionInput(tx *types.Transaction) ([]interface{}, error)
+	GetChallengeType(ctx context.Context, challengeAddress common.Address) (ChallengeType, error)
+	NewChallengeClient(ctx context.Context, challengeAddress common.Address) (ChallengeClient, error)
 }
 
 // Basically a thread-safe shim for `ethclient.Client` and `bindings`.
+// Transactions are sent through `txMgr`.
 // TODO: acquire lock in all methods to support concurrent access
 type EthBridgeClient struct {
 	client       *EthClient
 	transactOpts *bind.TransactOpts
+	txMgr        *TxManager
 	// Lock, conservatively on all functions.
+	// Not held while waiting for transactions.
 	mu sync.Mutex
 	// ISequencerInbox.sol
-	inboxAbi *abi.ABI
-	inbox    *bindings.ISequencerInboxSession
+	sequencerInboxAddr common.Address
+	inboxAbi           *abi.ABI
+	inbox              *bindings.ISequencerInboxSession
 	// IRollup.sol
-	rollup *bindings.IRollupSession
+	rollupAddr common.Address
+	rollupAbi  *abi.ABI
+	rollup     *bindings.IRollupSession
 	// IChallenge.sol
-	// `challenge` initialized separately through `InitNewChallengeSession`
-	challengeAbi *abi.ABI
-	challenge    *bindings.ISymChallengeSession
+	// Challenge contracts are accessed through `NewChallengeClient`
+	challengeAbi     *abi.ABI
+	asymChallengeAbi *abi.ABI
+	// Challenge protocol assumed for challenge contracts not reporting their type
+	challengeType ChallengeType
 }
 
 func NewEthBridgeClient(
@@ -108,6 +97,8 @@ func NewEthBridgeClient(
 	sequencerInboxAddress common.Address,
 	rollupAddress common.Address,
 	auth *bind.TransactOpts,
+	txMgrCfg TxManagerConfig,
+	challengeType ChallengeType,
 ) (*EthBridgeClient, error) {
 	client, err := dialWithRetry(ctx, l1Endpoint, 3)
 	if err != nil {
@@ -115,10 +106,9 @@ func NewEthBridgeClient(
 	}
 	callOpts := bind.CallOpts{Pending: true, Context: ctx}
 	transactOpts := bind.TransactOpts{
-		From:     auth.From,
-		Signer:   auth.Signer,
-		GasPrice: big.NewInt(800000000),
-		Context:  ctx,
+		From:    auth.From,
+		Signer:  auth.Signer,
+		Context: ctx,
 	}
 	inbox, err := bindings.NewISequencerInbox(sequencerInboxAddress, client)
 	if err != nil {
@@ -143,18 +133,39 @@ func NewEthBridgeClient(
 		return nil, fmt.Errorf("Failed to get ISequencerInbox ABI, err: %w", err)
 	}
 
+	rollupAbi, err := bindings.IRollupMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get IRollup ABI, err: %w", err)
+	}
+
 	challengeAbi, err := bindings.ISymChallengeMetaData.GetAbi()
 	if err != nil {
 		return nil, err
 	}
 
+	asymChallengeAbi, err := bindings.IAsymChallengeMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get IAsymChallenge ABI, err: %w", err)
+	}
+
+	txMgr, err := NewTxManager(ctx, client, auth, txMgrCfg, inboxAbi, rollupAbi, challengeAbi, asymChallengeAbi)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create tx manager, err: %w", err)
+	}
+
 	return &EthBridgeClient{
-		client:       client,
-		transactOpts: &transactOpts,
-		inboxAbi:     inboxAbi,
-		inbox:        inboxSession,
-		rollup:       rollupSession,
-		challengeAbi: challengeAbi,
+		client:             client,
+		transactOpts:       &transactOpts,
+		txMgr:              txMgr,
+		sequencerInboxAddr: sequencerInboxAddress,
+		inboxAbi:           inboxAbi,
+		inbox:              inboxSession,
+		rollupAddr:         rollupAddress,
+		rollupAbi:          rollupAbi,
+		rollup:             rollupSession,
+		challengeAbi:       challengeAbi,
+		asymChallengeAbi:   asymChallengeAbi,
+		challengeType:      challengeType,
 	}, nil
 }
 
@@ -164,12 +175,24 @@ func (c *EthBridgeClient) TransactionByHash(ctx context.Context, hash common.Has
 	return c.client.TransactionByHash(ctx, hash)
 }
 
+func (c *EthBridgeClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client.TransactionReceipt(ctx, hash)
+}
+
 func (c *EthBridgeClient) BlockNumber(ctx context.Context) (uint64, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	return c.client.BlockNumber(ctx)
 }
 
+func (c *EthBridgeClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client.HeaderByNumber(ctx, number)
+}
+
 func (c *EthBridgeClient) ResubscribeErrNewHead(ctx context.Context, headCh chan<- *types.Header) (event.Subscription, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -195,7 +218,7 @@ func (c *EthBridgeClient) SubscribeNewHeadByPolling(
 	return event.NewSubscription(func(unsub <-chan struct{}) error {
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
-		lastHeaderNumber := big.NewInt(-1)
+		var lastHeaderHash common.Hash
 		for {
 			select {
 			case <-ticker.C:
@@ -206,12 +229,14 @@ func (c *EthBridgeClient) SubscribeNewHeadByPolling(
 					log.Warn("Failed to poll for latest L1 block header", "err", err)
 					continue
 				}
-				if header.Number.Cmp(lastHeaderNumber) <= 0 {
-					log.Warn("Polled header is not new", "number", header.Number, "newest", lastHeaderNumber)
+				// Headers that are not newer are still sent if they differ from the last one,
+				// since they indicate a reorg.
+				if header.Hash() == lastHeaderHash {
+					log.Warn("Polled header is not new", "number", header.Number, "hash", lastHeaderHash)
 					continue
 				}
 				headCh <- header
-				lastHeaderNumber = header.Number
+				lastHeaderHash = header.Hash()
 			case <-ctx.Done():
 				return ctx.Err()
 			case <-unsub:
@@ -227,10 +252,24 @@ func (c *EthBridgeClient) Close() {
 	c.client.Close()
 }
 
-func (c *EthBridgeClient) AppendTxBatch(contexts []*big.Int, txLengths []*big.Int, txBatch []byte) (*types.Transaction, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.inbox.AppendTxBatch(contexts, txLengths, txBatch)
+// Sends a call to `method` of the contract at `to` through the tx manager,
+// and waits for its result.
+func (c *EthBridgeClient) transact(
+	to common.Address,
+	contractAbi *abi.ABI,
+	value *big.Int,
+	method string,
+	args ...interface{},
+) (*TxResult, error) {
+	data, err := contractAbi.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to pack `%s` call, err: %w", method, err)
+	}
+	return c.txMgr.Send(c.transactOpts.Context, TxCandidate{To: to, Data: data, Value: value})
+}
+
+func (c *EthBridgeClient) AppendTxBatch(contexts []*big.Int, txLengths []*big.Int, txBatch []byte) (*TxResult, error) {
+	return c.transact(c.sequencerInboxAddr, c.inboxAbi, nil, "appendTxBatch", contexts, txLengths, txBatch)
 }
 
 func (c *EthBridgeClient) WatchTxBatchAppended(
@@ -250,12 +289,23 @@ func (c *EthBridgeClient) FilterTxBatchAppendedEvents(
 	return c.inbox.Contract.FilterTxBatchAppended(opts)
 }
 
+func (c *EthBridgeClient) Sender() common.Address {
+	return c.transactOpts.From
+}
+
 func (c *EthBridgeClient) DecodeAppendTxBatchInput(tx *types.Transaction) ([]interface{}, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	return c.inboxAbi.Methods["appendTxBatch"].Inputs.Unpack(tx.Data()[4:])
 }
 
+// Gets the accumulator of all txs up to the end of batch `batchNumber`.
+func (c *EthBridgeClient) GetAccumulator(batchNumber uint64) (common.Hash, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inbox.Accumulators(new(big.Int).SetUint64(batchNumber))
+}
+
 func (c *EthBridgeClient) GetStaker() (bindings.IRollupStaker, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -263,12 +313,8 @@ func (c *EthBridgeClient) GetStaker() (bindings.IRollupStaker, error) {
 }
 
 func (c *EthBridgeClient) Stake(amount *big.Int) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
 	log.Info("Staking...")
-	opts := c.transactOpts
-	opts.Value = amount
-	_, err := c.rollup.Contract.Stake(opts)
+	_, err := c.transact(c.rollupAddr, c.rollupAbi, amount, "stake")
 	if err != nil {
 		return fmt.Errorf("Failed to stake, err: %w", err)
 	}
@@ -276,34 +322,24 @@ func (c *EthBridgeClient) Stake(amount *big.Int) error {
 	return nil
 }
 
-func (c *EthBridgeClient) AdvanceStake(assertionID *big.Int) (*types.Transaction, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.rollup.AdvanceStake(assertionID)
+func (c *EthBridgeClient) AdvanceStake(assertionID *big.Int) (*TxResult, error) {
+	return c.transact(c.rollupAddr, c.rollupAbi, nil, "advanceStake", assertionID)
 }
 
-func (c *EthBridgeClient) CreateAssertion(vmHash [32]byte, inboxSize *big.Int) (*types.Transaction, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.rollup.CreateAssertion(vmHash, inboxSize)
+func (c *EthBridgeClient) CreateAssertion(vmHash [32]byte, inboxSize *big.Int) (*TxResult, error) {
+	return c.transact(c.rollupAddr, c.rollupAbi, nil, "createAssertion", vmHash, inboxSize)
 }
 
-func (c *EthBridgeClient) ChallengeAssertion(players [2]common.Address, assertionIDs [2]*big.Int) (*types.Transaction, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.rollup.ChallengeAssertion(players, assertionIDs)
+func (c *EthBridgeClient) ChallengeAssertion(players [2]common.Address, assertionIDs [2]*big.Int) (*TxResult, error) {
+	return c.transact(c.rollupAddr, c.rollupAbi, nil, "challengeAssertion", players, assertionIDs)
 }
 
-func (c *EthBridgeClient) ConfirmFirstUnresolvedAssertion() (*types.Transaction, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.rollup.ConfirmFirstUnresolvedAssertion()
+func (c *EthBridgeClient) ConfirmFirstUnresolvedAssertion() (*TxResult, error) {
+	return c.transact(c.rollupAddr, c.rollupAbi, nil, "confirmFirstUnresolvedAssertion")
 }
 
-func (c *EthBridgeClient) RejectFirstUnresolvedAssertion(stakerAddress common.Address) (*types.Transaction, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.rollup.RejectFirstUnresolvedAssertion(stakerAddress)
+func (c *EthBridgeClient) RejectFirstUnresolvedAssertion(stakerAddress common.Address) (*TxResult, error) {
+	return c.transact(c.rollupAddr, c.rollupAbi, nil, "rejectFirstUnresolvedAssertion", stakerAddress)
 }
 
 // Returns the last assertion ID that was validated *by us*.
@@ -421,120 +457,3 @@ func (c *EthBridgeClient) GetGenesisAssertionCreated(opts *bind.FilterOpts) (*bi
 	}
 	return nil, fmt.Errorf("No genesis `AssertionCreated` event found")
 }
-
-func (c *EthBridgeClient) InitNewChallengeSession(ctx context.Context, challengeAddress common.Address) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	challenge, err := bindings.NewISymChallenge(challengeAddress, c.client)
-	if err != nil {
-		return fmt.Errorf("Failed to initialize challenge contract, err: %w", err)
-	}
-	c.challenge = &bindings.ISymChallengeSession{
-		Contract:     challenge,
-		CallOpts:     bind.CallOpts{Pending: true, Context: ctx},
-		TransactOpts: *c.transactOpts,
-	}
-	return nil
-}
-
-func (c *EthBridgeClient) InitializeChallengeLength(numSteps *big.Int) (*types.Transaction, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.challenge.InitializeChallengeLength(numSteps)
-}
-
-func (c *EthBridgeClient) CurrentChallengeResponder() (common.Address, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.challenge.CurrentResponder()
-}
-
-func (c *EthBridgeClient) CurrentChallengeResponderTimeLeft() (*big.Int, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.challenge.CurrentResponderTimeLeft()
-}
-
-func (c *EthBridgeClient) TimeoutChallenge() (*types.Transaction, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.challenge.Timeout()
-}
-
-func (c *EthBridgeClient) BisectExecution(
-	bisection [][32]byte,
-	challengedSegmentIndex *big.Int,
-	prevBisection [][32]byte,
-	prevChallengedSegmentStart *big.Int,
-	prevChallengedSegmentLength *big.Int,
-) (*types.Transaction, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.challenge.BisectExecution(
-		bisection,
-		challengedSegmentIndex,
-		prevBisection,
-		prevChallengedSegmentStart,
-		prevChallengedSegmentLength,
-	)
-}
-
-func (c *EthBridgeClient) VerifyOneStepProof(
-	proof []byte,
-	txInclusionProof []byte,
-	verificationRawCtx bindings.VerificationContextLibRawContext,
-	challengedStepIndex *big.Int,
-	prevBisection [][32]byte,
-	prevChallengedSegmentStart *big.Int,
-	prevChallengedSegmentLength *big.Int,
-) (*types.Transaction, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.challenge.VerifyOneStepProof(
-		proof,
-		txInclusionProof,
-		verificationRawCtx,
-		challengedStepIndex,
-		prevBisection,
-		prevChallengedSegmentStart,
-		prevChallengedSegmentLength,
-	)
-}
-
-func (c *EthBridgeClient) WatchBisected(
-	opts *bind.WatchOpts,
-	sink chan<- *bindings.ISymChallengeBisected,
-) (event.Subscription, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.challenge.Contract.WatchBisected(opts, sink)
-}
-
-func (c *EthBridgeClient) WatchChallengeCompleted(
-	opts *bind.WatchOpts,
-	sink chan<- *bindings.ISymChallengeCompleted,
-) (event.Subscription, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.challenge.Contract.WatchCompleted(opts, sink)
-}
-
-func (c *EthBridgeClient) FilterBisected(opts *bind.FilterOpts) (*bindings.ISymChallengeBisectedIterator, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.challenge.Contract.FilterBisected(opts)
-}
-
-func (c *EthBridgeClient) FilterChallengeCompleted(
-	opts *bind.FilterOpts,
-) (*bindings.ISymChallengeCompletedIterator, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.challenge.Contract.FilterCompleted(opts)
-}
-
-func (c *EthBridgeClient) DecodeBisectExecutionInput(tx *types.Transaction) ([]interface{}, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.challengeAbi.Methods["bisectExecution"].Inputs.Unpack(tx.Data()[4:])
-}
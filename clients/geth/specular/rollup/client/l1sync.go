@@ -2,20 +2,60 @@ package client
 
 import (
 	"context"
+	"math/big"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/utils"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/log"
 )
 
+// Number of recent L1 headers tracked for reorg detection.
+const reorgTrackingDepth uint64 = 256
+
+// Delay before checking a polled header for reorgs again, after a failed check.
+var reorgRetryDelay = 2 * time.Second
+
+// L1Reorg is emitted when the L1 syncer observes a reorg of the L1 chain.
+type L1Reorg struct {
+	// Last tracked header that is still canonical after the reorg, or nil if the reorg is
+	// deeper than the tracked history. Subscribers must then find the L1 blocks they derived
+	// from that are still canonical themselves.
+	CommonAncestor *types.Header
+	// New L1 head that revealed the reorg.
+	NewHead *types.Header
+}
+
+// Returns the deeper of reorgs `a` and `b`, either of which may be nil,
+// e.g. to handle a reorg observed while another one is still being handled.
+func DeeperReorg(a, b *L1Reorg) *L1Reorg {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.CommonAncestor == nil {
+		return a
+	}
+	if b.CommonAncestor == nil || b.CommonAncestor.Number.Cmp(a.CommonAncestor.Number) < 0 {
+		return b
+	}
+	return a
+}
+
 type L1Syncer struct {
 	Latest    *types.Header
 	Finalized *types.Header
 
 	LatestHeaderBroker    *utils.Broker[*types.Header]
 	FinalizedHeaderBroker *utils.Broker[*types.Header]
+	ReorgBroker           *utils.Broker[*L1Reorg]
+
+	l1Client L1BridgeClient
+	// Recent canonical L1 headers, by number (only accessed by the latest header goroutine).
+	head    *types.Header
+	headers map[uint64]*types.Header
 
 	wg sync.WaitGroup
 }
@@ -25,9 +65,20 @@ func NewL1Syncer(ctx context.Context, l1Client L1BridgeClient) *L1Syncer {
 	syncer := L1Syncer{
 		LatestHeaderBroker:    utils.NewBroker[*types.Header](),
 		FinalizedHeaderBroker: utils.NewBroker[*types.Header](),
+		ReorgBroker:           utils.NewBroker[*L1Reorg](),
+		l1Client:              l1Client,
+		headers:               make(map[uint64]*types.Header),
 	}
-	syncer.wg.Add(2)
-	latestSub := l1Client.SubscribeNewHeadByPolling(ctx, syncer.LatestHeaderBroker.PubCh, Latest, 10*time.Second, 10*time.Second)
+	syncer.wg.Add(4)
+	// Latest headers are checked for reorgs before being broadcast. Reorgs and headers are
+	// broadcast by separate brokers, so subscribers may receive the headers of the new chain
+	// (or events mapped from them) before the reorg notification, and must handle both orders.
+	polledCh := make(chan *types.Header, 1)
+	latestSub := l1Client.SubscribeNewHeadByPolling(ctx, polledCh, Latest, 10*time.Second, 10*time.Second)
+	go func() {
+		defer syncer.wg.Done()
+		syncer.trackLatest(ctx, polledCh)
+	}()
 	go func() {
 		defer syncer.wg.Done()
 		err := syncer.LatestHeaderBroker.Start(ctx, latestSub)
@@ -35,6 +86,18 @@ func NewL1Syncer(ctx context.Context, l1Client L1BridgeClient) *L1Syncer {
 			log.Error("Failed running latest head broker", "err", err)
 		}
 	}()
+	// The reorg broker has no source subscription of its own.
+	reorgSub := event.NewSubscription(func(unsub <-chan struct{}) error {
+		<-unsub
+		return nil
+	})
+	go func() {
+		defer syncer.wg.Done()
+		err := syncer.ReorgBroker.Start(ctx, reorgSub)
+		if err != nil {
+			log.Error("Failed running reorg broker", "err", err)
+		}
+	}()
 	finalizedSub := l1Client.SubscribeNewHeadByPolling(ctx, syncer.FinalizedHeaderBroker.PubCh, Finalized, 10*time.Second, 10*time.Second)
 	go func() {
 		defer syncer.wg.Done()
@@ -66,3 +129,116 @@ func (s *L1Syncer) onFinalized(ctx context.Context, header *types.Header) error
 	s.Finalized = header
 	return nil
 }
+
+// Checks polled latest headers for reorgs, then publishes them.
+func (s *L1Syncer) trackLatest(ctx context.Context, polledCh <-chan *types.Header) {
+	for {
+		select {
+		case header := <-polledCh:
+			reorg, err := s.checkReorg(ctx, header)
+			if err != nil {
+				return
+			}
+			if reorg != nil {
+				if reorg.CommonAncestor == nil {
+					log.Warn("L1 reorg deeper than tracked history detected", "new head", header.Number, "hash", header.Hash())
+				} else {
+					log.Warn(
+						"L1 reorg detected",
+						"common ancestor", reorg.CommonAncestor.Number,
+						"new head", header.Number,
+						"hash", header.Hash(),
+					)
+				}
+				s.ReorgBroker.Publish(reorg)
+			}
+			s.track(header)
+			s.LatestHeaderBroker.Publish(header)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Checks `header` for a reorg, retrying until the check succeeds: a header published
+// without a check could hide a reorg for good.
+// Only returns an error if `ctx` is done.
+func (s *L1Syncer) checkReorg(ctx context.Context, header *types.Header) (*L1Reorg, error) {
+	for {
+		reorg, err := s.detectReorg(ctx, header)
+		if err == nil {
+			return reorg, nil
+		}
+		log.Error("Failed to check for L1 reorg, retrying", "number", header.Number, "err", err)
+		select {
+		case <-time.After(reorgRetryDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Checks whether `header` is on the same chain as the last tracked head.
+// Returns a reorg notification if not, without a common ancestor if none of the
+// tracked headers is still canonical.
+func (s *L1Syncer) detectReorg(ctx context.Context, header *types.Header) (*L1Reorg, error) {
+	if s.head == nil {
+		return nil, nil
+	}
+	number := header.Number.Uint64()
+	headNumber := s.head.Number.Uint64()
+	if number == headNumber+1 && header.ParentHash == s.head.Hash() {
+		return nil, nil
+	}
+	if number > headNumber {
+		// Some headers were not polled; check that our head is still canonical.
+		canonical, err := s.l1Client.HeaderByNumber(ctx, s.head.Number)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get L1 header #%d, err: %w", headNumber, err)
+		}
+		if canonical.Hash() == s.head.Hash() {
+			return nil, nil
+		}
+	}
+	// Reorg: walk back through tracked headers to find the common ancestor.
+	n := headNumber
+	if number <= headNumber {
+		n = number - 1
+	}
+	for ; ; n-- {
+		tracked, ok := s.headers[n]
+		if !ok {
+			// All tracked headers were reorged out.
+			s.headers = make(map[uint64]*types.Header)
+			return &L1Reorg{NewHead: header}, nil
+		}
+		canonical, err := s.l1Client.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get L1 header #%d, err: %w", n, err)
+		}
+		if canonical.Hash() == tracked.Hash() {
+			for m := range s.headers {
+				if m > n {
+					delete(s.headers, m)
+				}
+			}
+			return &L1Reorg{CommonAncestor: tracked, NewHead: header}, nil
+		}
+		if n == 0 {
+			s.headers = make(map[uint64]*types.Header)
+			return &L1Reorg{NewHead: header}, nil
+		}
+	}
+}
+
+// Tracks `header` as the new head, dropping headers reorged out or beyond the tracking depth.
+func (s *L1Syncer) track(header *types.Header) {
+	number := header.Number.Uint64()
+	for n := range s.headers {
+		if n >= number || n+reorgTrackingDepth < number {
+			delete(s.headers, n)
+		}
+	}
+	s.headers[number] = header
+	s.head = header
+}
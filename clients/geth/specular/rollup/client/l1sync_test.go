@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// L1 client serving headers of a canonical chain, failing the first `failures` requests.
+type testHeaderClient struct {
+	L1BridgeClient
+	canonical map[uint64]*types.Header
+	failures  int
+}
+
+func (c *testHeaderClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if c.failures > 0 {
+		c.failures--
+		return nil, errors.New("connection refused")
+	}
+	header, ok := c.canonical[number.Uint64()]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return header, nil
+}
+
+// Returns headers [from, to] on top of `parent`, forked by `fork`.
+func makeHeaders(parent *types.Header, from, to uint64, fork byte) []*types.Header {
+	var headers []*types.Header
+	for n := from; n <= to; n++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(n), Extra: []byte{fork}}
+		if parent != nil {
+			header.ParentHash = parent.Hash()
+		}
+		headers = append(headers, header)
+		parent = header
+	}
+	return headers
+}
+
+// Returns a syncer having tracked `headers`, on an L1 whose canonical chain is `canonical`.
+func newTestSyncer(headers, canonical []*types.Header) (*L1Syncer, *testHeaderClient) {
+	client := &testHeaderClient{canonical: make(map[uint64]*types.Header)}
+	for _, header := range canonical {
+		client.canonical[header.Number.Uint64()] = header
+	}
+	syncer := &L1Syncer{l1Client: client, headers: make(map[uint64]*types.Header)}
+	for _, header := range headers {
+		syncer.track(header)
+	}
+	return syncer, client
+}
+
+func TestDetectReorg(t *testing.T) {
+	chain := makeHeaders(nil, 0, 10, 0)
+	// Fork of `chain` from block #7.
+	fork := append(append([]*types.Header{}, chain[:7]...), makeHeaders(chain[6], 7, 12, 1)...)
+	tests := []struct {
+		name     string
+		header   *types.Header
+		ancestor *types.Header // nil if no reorg
+	}{
+		{"next header", makeHeaders(chain[10], 11, 11, 0)[0], nil},
+		{"headers skipped", makeHeaders(chain[10], 11, 13, 0)[2], nil},
+		{"new chain at same height", fork[10], chain[6]},
+		{"new chain at lower height", fork[8], chain[6]},
+		{"new chain, headers skipped", fork[12], chain[6]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canonical := chain
+			if tt.ancestor != nil {
+				canonical = fork
+			}
+			syncer, _ := newTestSyncer(chain, canonical)
+			reorg, err := syncer.detectReorg(context.Background(), tt.header)
+			if err != nil {
+				t.Fatalf("failed to detect reorg: %v", err)
+			}
+			if tt.ancestor == nil {
+				if reorg != nil {
+					t.Fatalf("unexpected reorg to #%d", reorg.CommonAncestor.Number)
+				}
+				return
+			}
+			if reorg == nil {
+				t.Fatal("reorg not detected")
+			}
+			if reorg.CommonAncestor.Hash() != tt.ancestor.Hash() {
+				t.Fatalf("have common ancestor #%d, want #%d", reorg.CommonAncestor.Number, tt.ancestor.Number)
+			}
+			// Reorged-out headers are no longer tracked.
+			for n := range syncer.headers {
+				if n > tt.ancestor.Number.Uint64() {
+					t.Errorf("header #%d still tracked", n)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectReorgTooDeep(t *testing.T) {
+	chain := makeHeaders(nil, 0, 10, 0)
+	fork := makeHeaders(nil, 0, 11, 1)
+	// Only track the last headers of `chain`.
+	syncer, _ := newTestSyncer(chain[8:], fork)
+	reorg, err := syncer.detectReorg(context.Background(), fork[11])
+	if err != nil {
+		t.Fatalf("failed to detect reorg: %v", err)
+	}
+	if reorg == nil || reorg.CommonAncestor != nil {
+		t.Fatalf("have reorg %v, want reorg without common ancestor", reorg)
+	}
+	if len(syncer.headers) != 0 {
+		t.Fatalf("%d reorged-out headers still tracked", len(syncer.headers))
+	}
+}
+
+func TestDeeperReorg(t *testing.T) {
+	chain := makeHeaders(nil, 0, 10, 0)
+	shallow := &L1Reorg{CommonAncestor: chain[8]}
+	deep := &L1Reorg{CommonAncestor: chain[4]}
+	tooDeep := &L1Reorg{}
+	tests := []struct {
+		a, b, want *L1Reorg
+	}{
+		{nil, nil, nil},
+		{shallow, nil, shallow},
+		{nil, shallow, shallow},
+		{shallow, deep, deep},
+		{deep, shallow, deep},
+		{deep, tooDeep, tooDeep},
+		{tooDeep, deep, tooDeep},
+	}
+	for i, tt := range tests {
+		if have := DeeperReorg(tt.a, tt.b); have != tt.want {
+			t.Errorf("test %d: have reorg %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+// A failed check must be retried rather than skipped, or the reorg would be missed.
+func TestCheckReorgRetries(t *testing.T) {
+	defer func(delay time.Duration) { reorgRetryDelay = delay }(reorgRetryDelay)
+	reorgRetryDelay = time.Millisecond
+
+	chain := makeHeaders(nil, 0, 10, 0)
+	fork := append(append([]*types.Header{}, chain[:5]...), makeHeaders(chain[4], 5, 11, 1)...)
+	syncer, client := newTestSyncer(chain, fork)
+	client.failures = 3
+	reorg, err := syncer.checkReorg(context.Background(), fork[11])
+	if err != nil {
+		t.Fatalf("failed to check reorg: %v", err)
+	}
+	if reorg == nil || reorg.CommonAncestor.Hash() != chain[4].Hash() {
+		t.Fatalf("have reorg %v, want reorg to #4", reorg)
+	}
+	if client.failures != 0 {
+		t.Fatalf("%d failures left", client.failures)
+	}
+
+	// Stops retrying once the context is done.
+	syncer, client = newTestSyncer(chain, fork)
+	client.failures = 1 << 30
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := syncer.checkReorg(ctx, fork[11]); !errors.Is(err, context.Canceled) {
+		t.Fatalf("have error %v, want %v", err, context.Canceled)
+	}
+}
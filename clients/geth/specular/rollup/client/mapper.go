@@ -35,6 +35,11 @@ func toMapperFn[T any, U Iterable](
 	var last *types.Header
 	return func(ctx context.Context, header *types.Header) ([]T, error) {
 		end := header.Number.Uint64()
+		// On a reorg to a lower or equal height, re-filter the new head block.
+		// Deeper reorgs must be handled by the subscriber (see `L1Syncer.ReorgBroker`).
+		if end < start {
+			start = end
+		}
 		opts := &bind.FilterOpts{
 			Start:   start,
 			End:     &end,
@@ -0,0 +1,454 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+const (
+	defaultResubmissionTimeout  = 48 * time.Second
+	defaultReceiptQueryInterval = 6 * time.Second
+	// Fee increase when re-broadcasting a transaction.
+	// L1 nodes reject replacements that bump fees by less than 10%.
+	priceBumpPercent = 10
+)
+
+var ErrTxDropped = fmt.Errorf("transaction dropped")
+
+// TxStatus is the final status of a transaction sent through the `TxManager`.
+type TxStatus uint8
+
+const (
+	// Included with a successful receipt.
+	TxIncluded TxStatus = iota
+	// Included with a failed receipt.
+	TxReverted
+	// Nonce used by a transaction not sent by the manager.
+	TxDropped
+)
+
+func (s TxStatus) String() string {
+	switch s {
+	case TxIncluded:
+		return "included"
+	case TxReverted:
+		return "reverted"
+	case TxDropped:
+		return "dropped"
+	default:
+		return "unknown"
+	}
+}
+
+// TxResult is the outcome of a transaction sent through the `TxManager`.
+type TxResult struct {
+	Status TxStatus
+	// Transaction included, or the last one broadcast if dropped.
+	Tx *types.Transaction
+	// Receipt of the included transaction, nil if dropped.
+	Receipt *types.Receipt
+	// Decoded revert, set if reverted.
+	Revert *RevertError
+}
+
+// Err returns nil if the transaction succeeded, a `*RevertError` if it reverted, and `ErrTxDropped` if dropped.
+func (r *TxResult) Err() error {
+	switch r.Status {
+	case TxIncluded:
+		return nil
+	case TxReverted:
+		return r.Revert
+	default:
+		return ErrTxDropped
+	}
+}
+
+// RevertError is a transaction revert, either on L1 or during gas estimation,
+// decoded from the revert data using the contract ABIs known to the `TxManager`.
+type RevertError struct {
+	// Custom error name, "Error" for `require` and `revert` with a reason, empty if unknown.
+	Name string
+	Args []interface{}
+	// Raw revert data.
+	Data []byte
+}
+
+func (e *RevertError) Error() string {
+	switch {
+	case e.Name == "Error" && len(e.Args) == 1:
+		return fmt.Sprintf("execution reverted: %v", e.Args[0])
+	case e.Name != "":
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = fmt.Sprint(arg)
+		}
+		return fmt.Sprintf("execution reverted: %s(%s)", e.Name, strings.Join(args, ", "))
+	case len(e.Data) > 0:
+		return fmt.Sprintf("execution reverted: %s", hexutil.Encode(e.Data))
+	default:
+		return "execution reverted"
+	}
+}
+
+// TxCandidate is a transaction to be priced, signed and sent by the `TxManager`.
+type TxCandidate struct {
+	To    common.Address
+	Data  []byte
+	Value *big.Int
+	// Estimated if zero.
+	GasLimit uint64
+}
+
+// TxClient is the L1 RPC client used by the `TxManager`, implemented by `EthClient`.
+type TxClient interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+type TxManagerConfig struct {
+	// Number of L1 blocks (including the including block) before a transaction is considered final.
+	NumConfirmations uint64
+	// Time to wait for inclusion before re-broadcasting a transaction with bumped fees.
+	ResubmissionTimeout time.Duration
+	// Interval between receipt queries.
+	ReceiptQueryInterval time.Duration
+}
+
+// TxManager sends L1 transactions from a single account.
+// It tracks the account nonce locally, prices transactions with EIP-1559 fees,
+// re-broadcasts them with bumped fees until included, and waits for confirmations.
+type TxManager struct {
+	cfg     TxManagerConfig
+	client  TxClient
+	chainID *big.Int
+	from    common.Address
+	signer  bind.SignerFn
+	// ABIs used to decode custom errors in reverts.
+	abis []*abi.ABI
+
+	// Guards `nonce`, and serializes sends so that nonces are used in order.
+	mu sync.Mutex
+	// Next nonce to use, nil if it must be fetched from L1.
+	nonce *uint64
+}
+
+func NewTxManager(
+	ctx context.Context,
+	client TxClient,
+	auth *bind.TransactOpts,
+	cfg TxManagerConfig,
+	abis ...*abi.ABI,
+) (*TxManager, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get L1 chain ID, err: %w", err)
+	}
+	if cfg.NumConfirmations == 0 {
+		cfg.NumConfirmations = 1
+	}
+	if cfg.ResubmissionTimeout == 0 {
+		cfg.ResubmissionTimeout = defaultResubmissionTimeout
+	}
+	if cfg.ReceiptQueryInterval == 0 {
+		cfg.ReceiptQueryInterval = defaultReceiptQueryInterval
+	}
+	return &TxManager{
+		cfg:     cfg,
+		client:  client,
+		chainID: chainID,
+		from:    auth.From,
+		signer:  auth.Signer,
+		abis:    abis,
+	}, nil
+}
+
+// Send sends a transaction and blocks until it is included with `NumConfirmations` confirmations, or dropped.
+// It is re-broadcast with bumped fees every `ResubmissionTimeout` while not included.
+// A result is returned for every transaction broadcast, along with its error (see `TxResult.Err`).
+// Reverts during gas estimation are returned as `*RevertError` without a result.
+func (m *TxManager) Send(ctx context.Context, candidate TxCandidate) (*TxResult, error) {
+	tx, err := m.publish(ctx, candidate)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Sent L1 transaction", "hash", tx.Hash(), "nonce", tx.Nonce(), "gas", tx.Gas(), "fee cap", tx.GasFeeCap())
+	result, err := m.waitForResult(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("L1 transaction finalized", "hash", result.Tx.Hash(), "status", result.Status)
+	return result, result.Err()
+}
+
+// Prices, signs and broadcasts a new transaction.
+func (m *TxManager) publish(ctx context.Context, candidate TxCandidate) (*types.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tipCap, feeCap, err := m.suggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gasLimit := candidate.GasLimit
+	if gasLimit == 0 {
+		gasLimit, err = m.client.EstimateGas(ctx, ethereum.CallMsg{
+			From:      m.from,
+			To:        &candidate.To,
+			GasFeeCap: feeCap,
+			GasTipCap: tipCap,
+			Value:     candidate.Value,
+			Data:      candidate.Data,
+		})
+		if err != nil {
+			if revertErr := m.decodeRevertError(err); revertErr != nil {
+				return nil, revertErr
+			}
+			return nil, fmt.Errorf("Failed to estimate gas, err: %w", err)
+		}
+	}
+	if m.nonce == nil {
+		nonce, err := m.client.PendingNonceAt(ctx, m.from)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get nonce, err: %w", err)
+		}
+		m.nonce = &nonce
+	}
+	tx, err := m.signer(m.from, types.NewTx(&types.DynamicFeeTx{
+		ChainID:   m.chainID,
+		Nonce:     *m.nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       gasLimit,
+		To:        &candidate.To,
+		Value:     candidate.Value,
+		Data:      candidate.Data,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to sign transaction, err: %w", err)
+	}
+	if err := m.client.SendTransaction(ctx, tx); err != nil {
+		// The local nonce may be out of sync (e.g. the account was used elsewhere); refetch it.
+		m.nonce = nil
+		return nil, fmt.Errorf("Failed to send transaction, err: %w", err)
+	}
+	*m.nonce++
+	return tx, nil
+}
+
+// Returns the L1 node's suggested tip, and a fee cap allowing the base fee to double.
+func (m *TxManager) suggestFees(ctx context.Context) (*big.Int, *big.Int, error) {
+	tipCap, err := m.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to get suggested tip, err: %w", err)
+	}
+	head, err := m.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to get L1 head, err: %w", err)
+	}
+	if head.BaseFee == nil {
+		return nil, nil, fmt.Errorf("L1 does not support EIP-1559")
+	}
+	feeCap := new(big.Int).Mul(head.BaseFee, common.Big2)
+	feeCap.Add(feeCap, tipCap)
+	return tipCap, feeCap, nil
+}
+
+// Waits for one of the broadcast versions of `tx` to be confirmed, re-broadcasting it with bumped fees meanwhile.
+func (m *TxManager) waitForResult(ctx context.Context, tx *types.Transaction) (*TxResult, error) {
+	// All versions of the transaction broadcast, any of them may be included.
+	sent := []*types.Transaction{tx}
+	resubmitTimer := time.NewTimer(m.cfg.ResubmissionTimeout)
+	defer resubmitTimer.Stop()
+	ticker := time.NewTicker(m.cfg.ReceiptQueryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			result, err := m.queryResult(ctx, sent)
+			if err != nil {
+				log.Warn("Failed to query L1 transaction status", "hash", tx.Hash(), "err", err)
+				continue
+			}
+			if result != nil {
+				return result, nil
+			}
+		case <-resubmitTimer.C:
+			bumped, err := m.rebroadcast(ctx, sent[len(sent)-1])
+			if err != nil {
+				log.Warn("Failed to re-broadcast L1 transaction", "nonce", tx.Nonce(), "err", err)
+			} else {
+				log.Info("Re-broadcast L1 transaction", "hash", bumped.Hash(), "nonce", bumped.Nonce(), "fee cap", bumped.GasFeeCap())
+				sent = append(sent, bumped)
+			}
+			resubmitTimer.Reset(m.cfg.ResubmissionTimeout)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Returns the result of the transaction if final, nil if still pending.
+func (m *TxManager) queryResult(ctx context.Context, sent []*types.Transaction) (*TxResult, error) {
+	head, err := m.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range sent {
+		receipt, err := m.client.TransactionReceipt(ctx, tx.Hash())
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if receipt.BlockNumber.Uint64()+m.cfg.NumConfirmations > head+1 {
+			// Included, not confirmed yet.
+			return nil, nil
+		}
+		result := &TxResult{Status: TxIncluded, Tx: tx, Receipt: receipt}
+		if receipt.Status == types.ReceiptStatusFailed {
+			result.Status = TxReverted
+			result.Revert = m.replayRevert(ctx, tx, receipt.BlockNumber)
+		}
+		return result, nil
+	}
+	// None of our transactions is included as of `head`. If the nonce was used nonetheless, it was dropped.
+	nonce, err := m.client.NonceAt(ctx, m.from, new(big.Int).SetUint64(head))
+	if err != nil {
+		return nil, err
+	}
+	last := sent[len(sent)-1]
+	if nonce > last.Nonce() {
+		return &TxResult{Status: TxDropped, Tx: last}, nil
+	}
+	return nil, nil
+}
+
+// Re-signs and broadcasts `tx` with fees bumped by at least `priceBumpPercent`, or to the current suggestion if higher.
+func (m *TxManager) rebroadcast(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	tipCap, feeCap, err := m.suggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tipCap = bigMax(tipCap, bumpFee(tx.GasTipCap()))
+	feeCap = bigMax(feeCap, bumpFee(tx.GasFeeCap()))
+	if feeCap.Cmp(tipCap) < 0 {
+		feeCap = new(big.Int).Set(tipCap)
+	}
+	bumped, err := m.signer(m.from, types.NewTx(&types.DynamicFeeTx{
+		ChainID:   m.chainID,
+		Nonce:     tx.Nonce(),
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       tx.Gas(),
+		To:        tx.To(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to sign transaction, err: %w", err)
+	}
+	if err := m.client.SendTransaction(ctx, bumped); err != nil {
+		return nil, fmt.Errorf("Failed to send transaction, err: %w", err)
+	}
+	return bumped, nil
+}
+
+// Replays a reverted transaction on the state of its block to recover the revert data.
+func (m *TxManager) replayRevert(ctx context.Context, tx *types.Transaction, blockNumber *big.Int) *RevertError {
+	_, err := m.client.CallContract(ctx, ethereum.CallMsg{
+		From:  m.from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}, blockNumber)
+	if revertErr := m.decodeRevertError(err); revertErr != nil {
+		return revertErr
+	}
+	if err != nil {
+		log.Warn("Failed to recover revert data", "hash", tx.Hash(), "err", err)
+	}
+	// The replay may succeed if the revert depended on transactions earlier in the block.
+	return &RevertError{}
+}
+
+// Decodes the revert data of an L1 RPC error, nil if `err` has no revert data.
+func (m *TxManager) decodeRevertError(err error) *RevertError {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil
+	}
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil {
+		return nil
+	}
+	return m.decodeRevertData(data)
+}
+
+func (m *TxManager) decodeRevertData(data []byte) *RevertError {
+	revertErr := &RevertError{Data: data}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		revertErr.Name = "Error"
+		revertErr.Args = []interface{}{reason}
+		return revertErr
+	}
+	if len(data) < 4 {
+		return revertErr
+	}
+	for _, contractAbi := range m.abis {
+		for _, abiErr := range contractAbi.Errors {
+			if !bytes.Equal(abiErr.ID[:4], data[:4]) {
+				continue
+			}
+			args, err := abiErr.Inputs.Unpack(data[4:])
+			if err != nil {
+				continue
+			}
+			revertErr.Name = abiErr.Name
+			revertErr.Args = args
+			return revertErr
+		}
+	}
+	return revertErr
+}
+
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+priceBumpPercent))
+	bumped.Div(bumped, big.NewInt(100))
+	// Round up so that the bump is strictly above the threshold.
+	return bumped.Add(bumped, common.Big1)
+}
+
+func bigMax(x, y *big.Int) *big.Int {
+	if x.Cmp(y) < 0 {
+		return y
+	}
+	return x
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var testTxChainID = big.NewInt(1337)
+
+// Revert error carrying revert data, as returned by L1 nodes.
+type testDataError struct{ data []byte }
+
+func (e *testDataError) Error() string          { return "execution reverted" }
+func (e *testDataError) ErrorData() interface{} { return hexutil.Encode(e.data) }
+
+// L1 client mining one block per `BlockNumber` query, with a fixed base fee and tip.
+type testTxClient struct {
+	head         uint64
+	nonce        uint64 // Nonce of the sender as of `head`
+	pendingNonce uint64
+	baseFee      *big.Int
+	tipCap       *big.Int
+	sendFailures int // Number of broadcasts to fail
+	sent         []*types.Transaction
+	receipts     map[common.Hash]*types.Receipt
+	revertData   []byte // Returned when replaying reverted txs
+	// Called on each successful broadcast
+	onSend func(c *testTxClient, tx *types.Transaction)
+}
+
+func newTestTxClient() *testTxClient {
+	return &testTxClient{
+		baseFee:  big.NewInt(params.GWei),
+		tipCap:   big.NewInt(params.GWei),
+		receipts: make(map[common.Hash]*types.Receipt),
+	}
+}
+
+// Includes `tx` in the next block.
+func (c *testTxClient) include(tx *types.Transaction, status uint64) {
+	c.receipts[tx.Hash()] = &types.Receipt{Status: status, TxHash: tx.Hash(), BlockNumber: new(big.Int).SetUint64(c.head + 1)}
+	c.nonce = tx.Nonce() + 1
+}
+
+func (c *testTxClient) ChainID(context.Context) (*big.Int, error) { return testTxChainID, nil }
+
+func (c *testTxClient) BlockNumber(context.Context) (uint64, error) {
+	c.head++
+	return c.head, nil
+}
+
+func (c *testTxClient) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(c.head), BaseFee: c.baseFee}, nil
+}
+
+func (c *testTxClient) SuggestGasTipCap(context.Context) (*big.Int, error) { return c.tipCap, nil }
+
+func (c *testTxClient) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return 100000, nil
+}
+
+func (c *testTxClient) CallContract(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error) {
+	return nil, &testDataError{c.revertData}
+}
+
+func (c *testTxClient) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return c.pendingNonce, nil
+}
+
+func (c *testTxClient) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	return c.nonce, nil
+}
+
+func (c *testTxClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if c.sendFailures > 0 {
+		c.sendFailures--
+		return errors.New("nonce too low")
+	}
+	c.sent = append(c.sent, tx)
+	if c.onSend != nil {
+		c.onSend(c, tx)
+	}
+	return nil
+}
+
+func (c *testTxClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if receipt, ok := c.receipts[hash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func newTestTxManager(t *testing.T, client *testTxClient, numConfirmations uint64) *TxManager {
+	key, _ := crypto.GenerateKey()
+	auth, err := bind.NewKeyedTransactorWithChainID(key, testTxChainID)
+	if err != nil {
+		t.Fatalf("failed to create transactor: %v", err)
+	}
+	cfg := TxManagerConfig{
+		NumConfirmations:     numConfirmations,
+		ResubmissionTimeout:  20 * time.Millisecond,
+		ReceiptQueryInterval: time.Millisecond,
+	}
+	m, err := NewTxManager(context.Background(), client, auth, cfg)
+	if err != nil {
+		t.Fatalf("failed to create tx manager: %v", err)
+	}
+	return m
+}
+
+func sendTestTx(t *testing.T, m *TxManager) (*TxResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return m.Send(ctx, TxCandidate{To: common.HexToAddress("0x1000"), Data: []byte{0x01}})
+}
+
+func TestSendWaitsForConfirmations(t *testing.T) {
+	client := newTestTxClient()
+	client.pendingNonce = 7
+	var includedAt uint64
+	client.onSend = func(c *testTxClient, tx *types.Transaction) {
+		c.include(tx, types.ReceiptStatusSuccessful)
+		includedAt = c.head + 1
+	}
+	m := newTestTxManager(t, client, 3)
+	for i := uint64(0); i < 2; i++ {
+		result, err := sendTestTx(t, m)
+		if err != nil {
+			t.Fatalf("failed to send tx %d: %v", i, err)
+		}
+		if result.Status != TxIncluded || result.Tx.Hash() != client.sent[i].Hash() {
+			t.Fatalf("tx %d: have status %v, tx %s", i, result.Status, result.Tx.Hash())
+		}
+		// Nonces are tracked locally after the first one.
+		if nonce := result.Tx.Nonce(); nonce != 7+i {
+			t.Errorf("tx %d: have nonce %d, want %d", i, nonce, 7+i)
+		}
+		if client.head < includedAt+2 {
+			t.Errorf("tx %d: returned at head #%d, included at #%d", i, client.head, includedAt)
+		}
+	}
+}
+
+func TestSendBumpsFees(t *testing.T) {
+	client := newTestTxClient()
+	client.onSend = func(c *testTxClient, tx *types.Transaction) {
+		if len(c.sent) == 3 {
+			c.include(tx, types.ReceiptStatusSuccessful)
+		}
+	}
+	m := newTestTxManager(t, client, 1)
+	result, err := sendTestTx(t, m)
+	if err != nil {
+		t.Fatalf("failed to send tx: %v", err)
+	}
+	if len(client.sent) != 3 {
+		t.Fatalf("have %d broadcasts, want 3", len(client.sent))
+	}
+	if result.Tx.Hash() != client.sent[2].Hash() {
+		t.Fatalf("result is not the included re-broadcast")
+	}
+	for i := 1; i < len(client.sent); i++ {
+		prev, tx := client.sent[i-1], client.sent[i]
+		if tx.Nonce() != prev.Nonce() {
+			t.Errorf("re-broadcast %d: have nonce %d, want %d", i, tx.Nonce(), prev.Nonce())
+		}
+		// Replacements must bump both fees by more than `priceBumpPercent`.
+		for _, fees := range [][2]*big.Int{{prev.GasTipCap(), tx.GasTipCap()}, {prev.GasFeeCap(), tx.GasFeeCap()}} {
+			min := new(big.Int).Mul(fees[0], big.NewInt(100+priceBumpPercent))
+			min.Div(min, big.NewInt(100))
+			if fees[1].Cmp(min) <= 0 {
+				t.Errorf("re-broadcast %d: fee %v not bumped from %v", i, fees[1], fees[0])
+			}
+		}
+	}
+}
+
+func TestSendDropped(t *testing.T) {
+	client := newTestTxClient()
+	client.onSend = func(c *testTxClient, tx *types.Transaction) {
+		if len(c.sent) == 2 {
+			// The nonce is used by a tx sent elsewhere.
+			c.nonce = tx.Nonce() + 1
+		}
+	}
+	m := newTestTxManager(t, client, 1)
+	result, err := sendTestTx(t, m)
+	if !errors.Is(err, ErrTxDropped) {
+		t.Fatalf("have error %v, want %v", err, ErrTxDropped)
+	}
+	if result == nil || result.Status != TxDropped || result.Tx.Hash() != client.sent[1].Hash() {
+		t.Fatalf("unexpected result %v", result)
+	}
+}
+
+func TestSendRefetchesNonceOnFailure(t *testing.T) {
+	client := newTestTxClient()
+	client.onSend = func(c *testTxClient, tx *types.Transaction) {
+		c.include(tx, types.ReceiptStatusSuccessful)
+	}
+	m := newTestTxManager(t, client, 1)
+	if _, err := sendTestTx(t, m); err != nil {
+		t.Fatalf("failed to send tx: %v", err)
+	}
+	// The account is used elsewhere, so the next broadcast fails.
+	client.pendingNonce = 5
+	client.sendFailures = 1
+	if _, err := sendTestTx(t, m); err == nil {
+		t.Fatal("broadcast failure not returned")
+	}
+	result, err := sendTestTx(t, m)
+	if err != nil {
+		t.Fatalf("failed to send tx: %v", err)
+	}
+	if nonce := result.Tx.Nonce(); nonce != 5 {
+		t.Fatalf("have nonce %d, want 5", nonce)
+	}
+}
+
+func TestSendReverted(t *testing.T) {
+	client := newTestTxClient()
+	client.revertData = []byte{0xde, 0xad, 0xbe, 0xef}
+	client.onSend = func(c *testTxClient, tx *types.Transaction) {
+		c.include(tx, types.ReceiptStatusFailed)
+	}
+	m := newTestTxManager(t, client, 1)
+	result, err := sendTestTx(t, m)
+	var revertErr *RevertError
+	if !errors.As(err, &revertErr) {
+		t.Fatalf("have error %v, want revert", err)
+	}
+	if result == nil || result.Status != TxReverted || !bytes.Equal(revertErr.Data, client.revertData) {
+		t.Fatalf("unexpected result %v, revert data %x", result, revertErr.Data)
+	}
+}
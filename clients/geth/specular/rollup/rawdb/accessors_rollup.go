@@ -0,0 +1,316 @@
+package rawdb
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// ReadL1Origin retrieves the L1 origin of the L2 block with the given number.
+func ReadL1Origin(db ethdb.KeyValueReader, number uint64) *rollupTypes.L1Origin {
+	data, _ := db.Get(l1OriginKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	origin := new(rollupTypes.L1Origin)
+	if err := rlp.DecodeBytes(data, origin); err != nil {
+		log.Error("Invalid L1 origin RLP", "number", number, "err", err)
+		return nil
+	}
+	return origin
+}
+
+// WriteL1Origin stores the L1 origin of the L2 block with the given number.
+func WriteL1Origin(db ethdb.KeyValueWriter, number uint64, origin *rollupTypes.L1Origin) {
+	data, err := rlp.EncodeToBytes(origin)
+	if err != nil {
+		log.Crit("Failed to RLP encode L1 origin", "err", err)
+	}
+	if err := db.Put(l1OriginKey(number), data); err != nil {
+		log.Crit("Failed to store L1 origin", "err", err)
+	}
+}
+
+// DeleteL1Origin removes the L1 origin of the L2 block with the given number.
+func DeleteL1Origin(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(l1OriginKey(number)); err != nil {
+		log.Crit("Failed to delete L1 origin", "err", err)
+	}
+}
+
+// ReadBatchBlockRange retrieves the L2 block range sequenced by the given batch.
+func ReadBatchBlockRange(db ethdb.KeyValueReader, batchNumber uint64) *rollupTypes.BlockRange {
+	return readBlockRange(db, batchRangeKey(batchNumber))
+}
+
+// WriteBatchBlockRange stores the L2 block range sequenced by the given batch.
+func WriteBatchBlockRange(db ethdb.KeyValueWriter, batchNumber uint64, blockRange *rollupTypes.BlockRange) {
+	writeBlockRange(db, batchRangeKey(batchNumber), blockRange)
+}
+
+// DeleteBatchBlockRange removes the L2 block range of the given batch.
+func DeleteBatchBlockRange(db ethdb.KeyValueWriter, batchNumber uint64) {
+	if err := db.Delete(batchRangeKey(batchNumber)); err != nil {
+		log.Crit("Failed to delete batch block range", "err", err)
+	}
+}
+
+// ReadAssertionBlockRange retrieves the L2 block range covered by the given assertion.
+func ReadAssertionBlockRange(db ethdb.KeyValueReader, assertionID *big.Int) *rollupTypes.BlockRange {
+	return readBlockRange(db, assertionRangeKey(assertionID))
+}
+
+// WriteAssertionBlockRange stores the L2 block range covered by the given assertion.
+func WriteAssertionBlockRange(db ethdb.KeyValueWriter, assertionID *big.Int, blockRange *rollupTypes.BlockRange) {
+	writeBlockRange(db, assertionRangeKey(assertionID), blockRange)
+}
+
+// ReadAssertionInfo retrieves the indexed record of the given assertion.
+func ReadAssertionInfo(db ethdb.KeyValueReader, assertionID *big.Int) *rollupTypes.AssertionInfo {
+	data, _ := db.Get(assertionInfoKey(assertionID))
+	if len(data) == 0 {
+		return nil
+	}
+	info := new(rollupTypes.AssertionInfo)
+	if err := rlp.DecodeBytes(data, info); err != nil {
+		log.Error("Invalid assertion info RLP", "id", assertionID, "err", err)
+		return nil
+	}
+	return info
+}
+
+// WriteAssertionInfo stores the indexed record of an assertion.
+// Unlike other writers, failures are returned so that the indexer can retry them.
+func WriteAssertionInfo(db ethdb.KeyValueWriter, info *rollupTypes.AssertionInfo) error {
+	data, err := rlp.EncodeToBytes(info)
+	if err != nil {
+		return fmt.Errorf("Failed to RLP encode assertion info, err: %w", err)
+	}
+	if err := db.Put(assertionInfoKey(info.ID), data); err != nil {
+		return fmt.Errorf("Failed to store assertion info, err: %w", err)
+	}
+	return nil
+}
+
+// ReadQueuedAssertions retrieves the IDs of the assertions whose blocks are not indexed yet.
+func ReadQueuedAssertions(db ethdb.KeyValueReader) []*big.Int {
+	data, _ := db.Get(queuedAssertionsKey)
+	if len(data) == 0 {
+		return nil
+	}
+	var ids []*big.Int
+	if err := rlp.DecodeBytes(data, &ids); err != nil {
+		log.Error("Invalid queued assertions RLP", "err", err)
+		return nil
+	}
+	return ids
+}
+
+// WriteQueuedAssertions stores the IDs of the assertions whose blocks are not indexed yet.
+// Failures are returned so that the indexer can retry them.
+func WriteQueuedAssertions(db ethdb.KeyValueWriter, ids []*big.Int) error {
+	data, err := rlp.EncodeToBytes(ids)
+	if err != nil {
+		return fmt.Errorf("Failed to RLP encode queued assertions, err: %w", err)
+	}
+	if err := db.Put(queuedAssertionsKey, data); err != nil {
+		return fmt.Errorf("Failed to store queued assertions, err: %w", err)
+	}
+	return nil
+}
+
+// ReadBlockAssertionID retrieves the ID of the assertion covering the L2 block with the given number.
+func ReadBlockAssertionID(db ethdb.KeyValueReader, number uint64) *big.Int {
+	data, _ := db.Get(blockAssertionKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	return new(big.Int).SetBytes(data)
+}
+
+// WriteBlockAssertionID stores the ID of the assertion covering the L2 block with the given number.
+func WriteBlockAssertionID(db ethdb.KeyValueWriter, number uint64, assertionID *big.Int) {
+	if err := db.Put(blockAssertionKey(number), common.BigToHash(assertionID).Bytes()); err != nil {
+		log.Crit("Failed to store block assertion ID", "err", err)
+	}
+}
+
+// DeleteBlockAssertionID removes the ID of the assertion covering the L2 block with the given number.
+func DeleteBlockAssertionID(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(blockAssertionKey(number)); err != nil {
+		log.Crit("Failed to delete block assertion ID", "err", err)
+	}
+}
+
+// ReadSkippedTxs retrieves the txs sequenced in the L2 block with the given number but skipped.
+func ReadSkippedTxs(db ethdb.KeyValueReader, number uint64) []*rollupTypes.SkippedTx {
+	data, _ := db.Get(skippedTxsKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	var txs []*rollupTypes.SkippedTx
+	if err := rlp.DecodeBytes(data, &txs); err != nil {
+		log.Error("Invalid skipped txs RLP", "number", number, "err", err)
+		return nil
+	}
+	return txs
+}
+
+// WriteSkippedTxs stores the txs sequenced in the L2 block with the given number but skipped.
+func WriteSkippedTxs(db ethdb.KeyValueWriter, number uint64, txs []*rollupTypes.SkippedTx) {
+	data, err := rlp.EncodeToBytes(txs)
+	if err != nil {
+		log.Crit("Failed to RLP encode skipped txs", "err", err)
+	}
+	if err := db.Put(skippedTxsKey(number), data); err != nil {
+		log.Crit("Failed to store skipped txs", "err", err)
+	}
+}
+
+// DeleteSkippedTxs removes the skipped txs of the L2 block with the given number.
+func DeleteSkippedTxs(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(skippedTxsKey(number)); err != nil {
+		log.Crit("Failed to delete skipped txs", "err", err)
+	}
+}
+
+// ReadSkippedBatch retrieves the batch skipped in the L2 block with the given number, or nil if none.
+func ReadSkippedBatch(db ethdb.KeyValueReader, number uint64) *rollupTypes.SkippedBatch {
+	data, _ := db.Get(skippedBatchKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	var batch rollupTypes.SkippedBatch
+	if err := rlp.DecodeBytes(data, &batch); err != nil {
+		log.Error("Invalid skipped batch RLP", "number", number, "err", err)
+		return nil
+	}
+	return &batch
+}
+
+// WriteSkippedBatch stores the batch skipped in the L2 block with the given number.
+func WriteSkippedBatch(db ethdb.KeyValueWriter, number uint64, batch *rollupTypes.SkippedBatch) {
+	data, err := rlp.EncodeToBytes(batch)
+	if err != nil {
+		log.Crit("Failed to RLP encode skipped batch", "err", err)
+	}
+	if err := db.Put(skippedBatchKey(number), data); err != nil {
+		log.Crit("Failed to store skipped batch", "err", err)
+	}
+}
+
+// DeleteSkippedBatch removes the batch skipped in the L2 block with the given number.
+func DeleteSkippedBatch(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(skippedBatchKey(number)); err != nil {
+		log.Crit("Failed to delete skipped batch", "err", err)
+	}
+}
+
+// ReadChallengeInfo retrieves the record of the challenge at the given address.
+func ReadChallengeInfo(db ethdb.KeyValueReader, challengeAddr common.Address) *rollupTypes.ChallengeInfo {
+	data, _ := db.Get(challengeInfoKey(challengeAddr))
+	if len(data) == 0 {
+		return nil
+	}
+	info := new(rollupTypes.ChallengeInfo)
+	if err := rlp.DecodeBytes(data, info); err != nil {
+		log.Error("Invalid challenge info RLP", "address", challengeAddr, "err", err)
+		return nil
+	}
+	return info
+}
+
+// ReadAllChallengeInfos retrieves the records of all challenges in progress.
+func ReadAllChallengeInfos(db ethdb.Iteratee) []*rollupTypes.ChallengeInfo {
+	it := db.NewIterator(challengeInfoPrefix, nil)
+	defer it.Release()
+	var infos []*rollupTypes.ChallengeInfo
+	for it.Next() {
+		info := new(rollupTypes.ChallengeInfo)
+		if err := rlp.DecodeBytes(it.Value(), info); err != nil {
+			log.Error("Invalid challenge info RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// WriteChallengeInfo stores the record of a challenge in progress.
+func WriteChallengeInfo(db ethdb.KeyValueWriter, info *rollupTypes.ChallengeInfo) {
+	data, err := rlp.EncodeToBytes(info)
+	if err != nil {
+		log.Crit("Failed to RLP encode challenge info", "err", err)
+	}
+	if err := db.Put(challengeInfoKey(info.Address), data); err != nil {
+		log.Crit("Failed to store challenge info", "err", err)
+	}
+}
+
+// DeleteChallengeInfo removes the record of the challenge at the given address.
+func DeleteChallengeInfo(db ethdb.KeyValueWriter, challengeAddr common.Address) {
+	if err := db.Delete(challengeInfoKey(challengeAddr)); err != nil {
+		log.Crit("Failed to delete challenge info", "err", err)
+	}
+}
+
+// ReadChallengeTraceIndex retrieves the checkpointed index of the trace of the given challenge.
+// The index is written at once, after the whole trace was indexed.
+func ReadChallengeTraceIndex(db ethdb.KeyValueReader, challengeAddr common.Address) *rollupTypes.TraceIndex {
+	data, _ := db.Get(challengeTraceIndexKey(challengeAddr))
+	if len(data) == 0 {
+		return nil
+	}
+	index := new(rollupTypes.TraceIndex)
+	if err := rlp.DecodeBytes(data, index); err != nil {
+		log.Error("Invalid challenge trace index RLP", "address", challengeAddr, "err", err)
+		return nil
+	}
+	return index
+}
+
+// WriteChallengeTraceIndex stores the checkpointed index of the trace of the given challenge.
+func WriteChallengeTraceIndex(db ethdb.KeyValueWriter, challengeAddr common.Address, index *rollupTypes.TraceIndex) {
+	data, err := rlp.EncodeToBytes(index)
+	if err != nil {
+		log.Crit("Failed to RLP encode challenge trace index", "err", err)
+	}
+	if err := db.Put(challengeTraceIndexKey(challengeAddr), data); err != nil {
+		log.Crit("Failed to store challenge trace index", "err", err)
+	}
+}
+
+// DeleteChallengeTrace removes the trace index of the given challenge.
+func DeleteChallengeTrace(db ethdb.KeyValueWriter, challengeAddr common.Address) {
+	if err := db.Delete(challengeTraceIndexKey(challengeAddr)); err != nil {
+		log.Crit("Failed to delete challenge trace index", "err", err)
+	}
+}
+
+func readBlockRange(db ethdb.KeyValueReader, key []byte) *rollupTypes.BlockRange {
+	data, _ := db.Get(key)
+	if len(data) == 0 {
+		return nil
+	}
+	blockRange := new(rollupTypes.BlockRange)
+	if err := rlp.DecodeBytes(data, blockRange); err != nil {
+		log.Error("Invalid block range RLP", "key", key, "err", err)
+		return nil
+	}
+	return blockRange
+}
+
+func writeBlockRange(db ethdb.KeyValueWriter, key []byte, blockRange *rollupTypes.BlockRange) {
+	data, err := rlp.EncodeToBytes(blockRange)
+	if err != nil {
+		log.Crit("Failed to RLP encode block range", "err", err)
+	}
+	if err := db.Put(key, data); err != nil {
+		log.Crit("Failed to store block range", "err", err)
+	}
+}
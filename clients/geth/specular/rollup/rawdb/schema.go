@@ -0,0 +1,86 @@
+package rawdb
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The fields below define the low level database schema prefixing for the
+// rollup indices. Prefixes are chosen so they do not collide with the ones
+// used by go-ethereum's core/rawdb.
+var (
+	// l1OriginPrefix + num (uint64 big endian) -> L1 origin of an L2 block
+	l1OriginPrefix = []byte("rollup-l1o")
+	// batchRangePrefix + batch number (uint64 big endian) -> L2 block range of the batch
+	batchRangePrefix = []byte("rollup-br")
+	// assertionRangePrefix + assertion ID (32 bytes big endian) -> L2 block range of the assertion
+	assertionRangePrefix = []byte("rollup-ar")
+	// assertionInfoPrefix + assertion ID (32 bytes big endian) -> indexed assertion record
+	assertionInfoPrefix = []byte("rollup-ai")
+	// blockAssertionPrefix + num (uint64 big endian) -> ID of the assertion covering an L2 block
+	blockAssertionPrefix = []byte("rollup-ba")
+	// queuedAssertionsKey -> IDs of the assertions whose blocks are not indexed yet, in creation order
+	queuedAssertionsKey = []byte("rollup-qa")
+	// skippedTxsPrefix + num (uint64 big endian) -> txs sequenced in an L2 block but skipped
+	skippedTxsPrefix = []byte("rollup-st")
+	// skippedBatchPrefix + num (uint64 big endian) -> batch derived as an L2 block but skipped
+	skippedBatchPrefix = []byte("rollup-sb")
+	// challengeInfoPrefix + challenge address -> record of a challenge in progress
+	challengeInfoPrefix = []byte("rollup-ci")
+	// challengeTraceIndexPrefix + challenge address -> checkpointed index of the challenge trace
+	challengeTraceIndexPrefix = []byte("rollup-cti")
+)
+
+// encodeBlockNumber encodes a block number as big endian uint64
+func encodeBlockNumber(number uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return enc
+}
+
+// l1OriginKey = l1OriginPrefix + num (uint64 big endian)
+func l1OriginKey(number uint64) []byte {
+	return append(append([]byte{}, l1OriginPrefix...), encodeBlockNumber(number)...)
+}
+
+// batchRangeKey = batchRangePrefix + batch number (uint64 big endian)
+func batchRangeKey(batchNumber uint64) []byte {
+	return append(append([]byte{}, batchRangePrefix...), encodeBlockNumber(batchNumber)...)
+}
+
+// assertionRangeKey = assertionRangePrefix + assertion ID (32 bytes big endian)
+func assertionRangeKey(assertionID *big.Int) []byte {
+	return append(append([]byte{}, assertionRangePrefix...), common.BigToHash(assertionID).Bytes()...)
+}
+
+// assertionInfoKey = assertionInfoPrefix + assertion ID (32 bytes big endian)
+func assertionInfoKey(assertionID *big.Int) []byte {
+	return append(append([]byte{}, assertionInfoPrefix...), common.BigToHash(assertionID).Bytes()...)
+}
+
+// blockAssertionKey = blockAssertionPrefix + num (uint64 big endian)
+func blockAssertionKey(number uint64) []byte {
+	return append(append([]byte{}, blockAssertionPrefix...), encodeBlockNumber(number)...)
+}
+
+// skippedTxsKey = skippedTxsPrefix + num (uint64 big endian)
+func skippedTxsKey(number uint64) []byte {
+	return append(append([]byte{}, skippedTxsPrefix...), encodeBlockNumber(number)...)
+}
+
+// skippedBatchKey = skippedBatchPrefix + num (uint64 big endian)
+func skippedBatchKey(number uint64) []byte {
+	return append(append([]byte{}, skippedBatchPrefix...), encodeBlockNumber(number)...)
+}
+
+// challengeInfoKey = challengeInfoPrefix + challenge address
+func challengeInfoKey(challengeAddr common.Address) []byte {
+	return append(append([]byte{}, challengeInfoPrefix...), challengeAddr.Bytes()...)
+}
+
+// challengeTraceIndexKey = challengeTraceIndexPrefix + challenge address
+func challengeTraceIndexKey(challengeAddr common.Address) []byte {
+	return append(append([]byte{}, challengeTraceIndexPrefix...), challengeAddr.Bytes()...)
+}
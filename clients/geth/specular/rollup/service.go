@@ -10,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/specularl2/specular/clients/geth/specular/proof"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/services"
@@ -19,6 +20,12 @@ import (
 	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
 )
 
+// Rollup services are node lifecycles that also provide RPC APIs
+type rollupService interface {
+	node.Lifecycle
+	APIs() []rpc.API
+}
+
 // RegisterRollupService registers rollup service configured by ctx
 // Either a sequncer service or a validator service will be registered
 func RegisterRollupService(stack *node.Node, eth services.Backend, proofBackend proof.Backend, cfg *services.Config) {
@@ -42,8 +49,17 @@ func RegisterRollupService(stack *node.Node, eth services.Backend, proofBackend
 
 	// Register services
 	ctx := context.Background()
-	l1Client, err := client.NewEthBridgeClient(ctx, cfg.L1Endpoint, cfg.L1RollupGenesisBlock, cfg.SequencerInboxAddr, cfg.RollupAddr, auth)
-	var service node.Lifecycle
+	txMgrCfg := client.TxManagerConfig{
+		NumConfirmations:    cfg.L1Confirmations,
+		ResubmissionTimeout: cfg.L1ResubmitTimeout,
+	}
+	l1Client, err := client.NewEthBridgeClient(
+		ctx, cfg.L1Endpoint, cfg.L1RollupGenesisBlock, cfg.SequencerInboxAddr, cfg.RollupAddr, auth, txMgrCfg, cfg.ChallengeType,
+	)
+	if err != nil {
+		log.Crit("Failed to register the Rollup service", "err", err)
+	}
+	var service rollupService
 	switch cfg.Node {
 	case services.NODE_SEQUENCER:
 		service, err = sequencer.New(eth, proofBackend, l1Client, cfg)
@@ -58,4 +74,5 @@ func RegisterRollupService(stack *node.Node, eth services.Backend, proofBackend
 		log.Crit("Failed to register rollup service", "err", err)
 	}
 	stack.RegisterLifecycle(service)
+	stack.RegisterAPIs(service.APIs())
 }
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+)
+
+// RPCAssertion is the RPC representation of an assertion.
+type RPCAssertion struct {
+	ID         *hexutil.Big   `json:"id"`
+	VmHash     common.Hash    `json:"vmHash"`
+	InboxSize  *hexutil.Big   `json:"inboxSize"`
+	Deadline   *hexutil.Big   `json:"deadline"`
+	StartBlock hexutil.Uint64 `json:"startBlock"`
+	EndBlock   hexutil.Uint64 `json:"endBlock"`
+}
+
+// Returns the RPC representation of an assertion, or nil if `assertion` is nil.
+func NewRPCAssertion(assertion *rollupTypes.Assertion) *RPCAssertion {
+	if assertion == nil {
+		return nil
+	}
+	return &RPCAssertion{
+		ID:         (*hexutil.Big)(assertion.ID),
+		VmHash:     assertion.VmHash,
+		InboxSize:  (*hexutil.Big)(assertion.InboxSize),
+		Deadline:   (*hexutil.Big)(assertion.Deadline),
+		StartBlock: hexutil.Uint64(assertion.StartBlock),
+		EndBlock:   hexutil.Uint64(assertion.EndBlock),
+	}
+}
+
+// RPCChallenge is the RPC representation of a challenge in progress.
+type RPCChallenge struct {
+	ChallengeAddr common.Address `json:"challengeAddr"`
+	Assertion     *RPCAssertion  `json:"assertion"`
+}
@@ -2,23 +2,35 @@ package services
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/log"
-	"github.com/ethereum/go-ethereum/trie"
 	"github.com/specularl2/specular/clients/geth/specular/bindings"
 	"github.com/specularl2/specular/clients/geth/specular/proof"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
+	rollupRawdb "github.com/specularl2/specular/clients/geth/specular/rollup/rawdb"
 	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
 )
 
+// Batches failing with this error cannot be derived into L2 blocks, e.g. because
+// they fail to decode or contain no block. They are skipped (see `skipInvalidBatch`).
+var errInvalidBatch = fmt.Errorf("invalid batch")
+
+// Number of L1 blocks searched at a time for the last batch covered by the local chain (see `findLastLocalBatch`).
+const syncStartSearchWindow = 10000
+
+// Delay before retrying to handle an L1 reorg, after a failure.
+var syncRetryDelay = 2 * time.Second
+
 type BaseService struct {
 	Config *Config
 
@@ -26,6 +38,10 @@ type BaseService struct {
 	ProofBackend proof.Backend
 	L1Client     client.L1BridgeClient
 	L1Syncer     *client.L1Syncer
+	// L1 block to resume syncing from, set by `Start` based on the local L2 chain head.
+	L1SyncStart uint64
+	// Txs of the local blocks rewound by `Start` because they were never sequenced to L1, in order.
+	RewoundTxs types.Transactions
 
 	Cancel context.CancelFunc
 	Wg     sync.WaitGroup
@@ -45,15 +61,197 @@ func NewBaseService(eth Backend, proofBackend proof.Backend, l1Client client.L1B
 func (b *BaseService) Start() (context.Context, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	b.Cancel = cancel
-	// Check if we are at genesis. TODO: remove.
-	if b.Eth.BlockChain().CurrentBlock().NumberU64() != 0 {
-		return nil, fmt.Errorf("Service can only start from clean history")
-	}
 	b.L1Syncer = client.NewL1Syncer(ctx, b.L1Client)
 	b.L1Syncer.Start(ctx)
+	start, err := b.findL1SyncStart(ctx)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("Failed to resume from local L2 chain, err: %w", err)
+	}
+	b.L1SyncStart = start
+	b.Wg.Add(1)
+	go b.HeadsLoop(ctx)
 	return ctx, nil
 }
 
+// Finds the L1 block to resume syncing from, given the local L2 chain head.
+// The local chain is matched against the `TxBatchAppended` history in `SequencerInbox`:
+// the last batch fully covered by the local chain must end exactly at a local block
+// with the same transactions. Local blocks beyond that batch (e.g. blocks the sequencer
+// committed but never appended to L1) are rewound, and their txs kept in `RewoundTxs`.
+func (b *BaseService) findL1SyncStart(ctx context.Context) (uint64, error) {
+	head := b.Chain().CurrentBlock().NumberU64()
+	if head == 0 {
+		return b.Config.L1RollupGenesisBlock, nil
+	}
+	start, found, err := b.findL1SyncStartFromIndex(ctx, head)
+	if err != nil {
+		return 0, err
+	}
+	if found {
+		return start, nil
+	}
+	// No usable index (e.g. database written by an older version), search the batches on L1.
+	log.Warn("L1 origin index unavailable, searching L1 for local chain head", "head", head)
+	lastCovered, batch, err := b.findLastLocalBatch(ctx, head)
+	if err != nil {
+		return 0, err
+	}
+	if lastCovered == nil {
+		log.Warn("No sequenced batch covered by local chain, rewinding to genesis", "head", head)
+		if err := b.rewindUnsequenced(0); err != nil {
+			return 0, err
+		}
+		return b.Config.L1RollupGenesisBlock, nil
+	}
+	lastBlockNumber := batch.LastBlockNumber()
+	if err := b.rewindUnsequenced(lastBlockNumber); err != nil {
+		return 0, err
+	}
+	log.Info(
+		"Resuming from local chain",
+		"l2 head", lastBlockNumber,
+		"batch", lastCovered.BatchNumber,
+		"l1 block", lastCovered.Raw.BlockNumber,
+	)
+	// Other batches may have been appended in the same L1 block; these are
+	// re-processed and skipped if already derived (see `processTxBatchAppendedEvent`).
+	return lastCovered.Raw.BlockNumber, nil
+}
+
+// Finds the last batch on L1 whose blocks are all in the local chain (up to block `head`)
+// and match it, or nil if there is none.
+// L1 is searched backwards from its head, `syncStartSearchWindow` blocks at a time, and
+// the batches of a window by binary search on their block numbers. So only a few batches
+// are fetched and decoded, however long the L1 and L2 chains are.
+func (b *BaseService) findLastLocalBatch(
+	ctx context.Context,
+	head uint64,
+) (*bindings.ISequencerInboxTxBatchAppended, *rollupTypes.TxBatch, error) {
+	l1BlockHead, err := b.L1Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to get L1 head, err: %w", err)
+	}
+	decoded := make(map[uint64]*rollupTypes.TxBatch)
+	decode := func(ev *bindings.ISequencerInboxTxBatchAppended) (*rollupTypes.TxBatch, error) {
+		if batch, ok := decoded[ev.BatchNumber.Uint64()]; ok {
+			return batch, nil
+		}
+		batch, err := b.decodeTxBatchAppendedEvent(ctx, ev)
+		if err != nil {
+			return nil, err
+		}
+		decoded[ev.BatchNumber.Uint64()] = batch
+		return batch, nil
+	}
+	genesis := b.Config.L1RollupGenesisBlock
+	for end := l1BlockHead; end >= genesis; {
+		start := genesis
+		if end-genesis >= syncStartSearchWindow {
+			start = end - syncStartSearchWindow + 1
+		}
+		events, err := b.filterTxBatchAppendedEvents(ctx, start, end)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Batches are appended in block order, so the ones covered by the local chain come first.
+		var decodeErr error
+		numCovered := sort.Search(len(events), func(i int) bool {
+			batch, err := decode(events[i])
+			if err != nil {
+				decodeErr = err
+				return true
+			}
+			return batch.LastBlockNumber() > head
+		})
+		if decodeErr != nil {
+			return nil, nil, decodeErr
+		}
+		for i := numCovered - 1; i >= 0; i-- {
+			batch, err := decode(events[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := b.matchLocalChain(batch.SplitToBlocks()); err != nil {
+				log.Warn("Sequenced batch mismatches local chain", "batch", events[i].BatchNumber, "err", err)
+				continue
+			}
+			return events[i], batch, nil
+		}
+		if start == genesis {
+			break
+		}
+		end = start - 1
+	}
+	return nil, nil, nil
+}
+
+// Gets the `TxBatchAppended` events emitted in L1 blocks [start, end].
+func (b *BaseService) filterTxBatchAppendedEvents(
+	ctx context.Context,
+	start, end uint64,
+) ([]*bindings.ISequencerInboxTxBatchAppended, error) {
+	opts := bind.FilterOpts{Start: start, End: &end, Context: ctx}
+	eventsIter, err := b.L1Client.FilterTxBatchAppendedEvents(&opts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to filter `TxBatchAppended` events, err: %w", err)
+	}
+	var events []*bindings.ISequencerInboxTxBatchAppended
+	for eventsIter.Next() {
+		events = append(events, eventsIter.Event)
+	}
+	if err := eventsIter.Error(); err != nil {
+		return nil, fmt.Errorf("Failed to iterate through `TxBatchAppended` events, err: %w", err)
+	}
+	return events, nil
+}
+
+// Checks that sequenced blocks are in the local chain, with the same timestamps and txs (including the skipped ones).
+func (b *BaseService) matchLocalChain(blocks []*rollupTypes.SequenceBlock) error {
+	for _, sblock := range blocks {
+		local := b.Chain().GetBlockByNumber(sblock.BlockNumber)
+		if local == nil {
+			return fmt.Errorf("sequenced block #%d not in local chain", sblock.BlockNumber)
+		}
+		if local.Time() != sblock.Timestamp {
+			return fmt.Errorf("sequenced block #%d has timestamp %d, local %d", sblock.BlockNumber, sblock.Timestamp, local.Time())
+		}
+		txs, _ := b.sequencedTxs(local)
+		if len(txs) != len(sblock.Txs) {
+			return fmt.Errorf("sequenced block #%d has %d txs, local %d", sblock.BlockNumber, len(sblock.Txs), len(txs))
+		}
+		for i, tx := range sblock.Txs {
+			if tx.Hash() != txs[i].Hash() {
+				return fmt.Errorf("sequenced block #%d has tx %s at %d, local %s", sblock.BlockNumber, tx.Hash(), i, txs[i].Hash())
+			}
+		}
+	}
+	return nil
+}
+
+// Rewinds local blocks after `target`, which were never sequenced to L1.
+// Their txs are appended to `RewoundTxs`, so that the sequencer sequences them again.
+func (b *BaseService) rewindUnsequenced(target uint64) error {
+	head := b.Chain().CurrentBlock().NumberU64()
+	if head <= target {
+		return nil
+	}
+	var txs types.Transactions
+	for number := target + 1; number <= head; number++ {
+		block := b.Chain().GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("Missing local block #%d", number)
+		}
+		txs = append(txs, block.Transactions()...)
+	}
+	log.Warn("Local chain ahead of L1, rewinding", "head", head, "target", target, "#txs", len(txs))
+	if err := b.setHead(target); err != nil {
+		return fmt.Errorf("Failed to rewind local chain, err: %w", err)
+	}
+	b.RewoundTxs = append(b.RewoundTxs, txs...)
+	return nil
+}
+
 func (b *BaseService) Stop() error {
 	log.Info("Stopping service...")
 	b.Cancel()
@@ -105,7 +303,13 @@ func (b *BaseService) GetLastValidatedAssertion(ctx context.Context) (*rollupTyp
 	}
 	// Initialize assertion.
 	assertion := NewAssertionFrom(&lastValidatedAssertion, assertionCreatedEvent)
-	// Set its boundaries using parent. TODO: move this out. Use local caching.
+	// Set its boundaries from the index if available.
+	if blockRange := b.AssertionBlockRange(assertion.ID); blockRange != nil {
+		assertion.StartBlock = blockRange.StartBlock
+		assertion.EndBlock = blockRange.EndBlock
+		return assertion, nil
+	}
+	// Otherwise, set its boundaries using parent.
 	opts = bind.FilterOpts{Start: b.Config.L1RollupGenesisBlock, Context: ctx}
 	parentAssertionCreatedIter, err := b.L1Client.FilterAssertionCreated(&opts)
 	if err != nil {
@@ -119,6 +323,7 @@ func (b *BaseService) GetLastValidatedAssertion(ctx context.Context) (*rollupTyp
 	if err != nil {
 		return nil, fmt.Errorf("Failed to set L2 block boundaries for last validated assertion, err: %w", err)
 	}
+	b.IndexAssertion(assertion)
 	return assertion, nil
 }
 
@@ -164,20 +369,96 @@ func (b *BaseService) SyncL2ChainToL1Head(ctx context.Context, start uint64) (ui
 
 func (b *BaseService) SyncLoop(ctx context.Context, start uint64, newBatchCh chan<- struct{}) {
 	defer b.Wg.Done()
+	// Watch for L1 reorgs.
+	reorgCh := b.L1Syncer.ReorgBroker.Subscribe()
+	defer b.L1Syncer.ReorgBroker.Unsubscribe(reorgCh)
 	// Start watching for new TxBatchAppended events.
 	subCtx, cancel := context.WithCancel(ctx)
 	batchEventCh := client.SubscribeHeaderMapped[*bindings.ISequencerInboxTxBatchAppended](
 		subCtx, b.L1Syncer.LatestHeaderBroker, b.L1Client.FilterTxBatchAppendedEvents, start,
 	)
-	defer cancel()
+	defer func() { cancel() }()
+	resubscribe := func(from uint64) {
+		cancel()
+		subCtx, cancel = context.WithCancel(ctx)
+		batchEventCh = client.SubscribeHeaderMapped[*bindings.ISequencerInboxTxBatchAppended](
+			subCtx, b.L1Syncer.LatestHeaderBroker, b.L1Client.FilterTxBatchAppendedEvents, from,
+		)
+	}
+	// Batch event that failed to be processed, re-filtered from its L1 block after `syncRetryDelay`.
+	// Batches of that block already derived are skipped (see `processTxBatchAppendedEvent`).
+	var resyncFrom uint64
+	var resyncCh <-chan time.Time
+	// Reorg that failed to be handled, retried after `syncRetryDelay`.
+	// Batch events are not processed meanwhile, since they may no longer be canonical.
+	var pendingReorg *client.L1Reorg
+	var retryCh <-chan time.Time
+	handleReorg := func(reorg *client.L1Reorg) {
+		pendingReorg = client.DeeperReorg(pendingReorg, reorg)
+		ancestor, err := b.ReorgAncestor(ctx, pendingReorg)
+		if err == nil {
+			err = b.RewindToL1Block(ancestor)
+		}
+		if err != nil {
+			log.Error("Failed to rewind L2 chain after L1 reorg, retrying", "err", err)
+			// Batch events are re-filtered once the reorg is handled.
+			cancel()
+			retryCh = time.After(syncRetryDelay)
+			return
+		}
+		pendingReorg, retryCh = nil, nil
+		// Re-derive from the first L1 block after the common ancestor, or from the failed batch event if earlier.
+		from := ancestor + 1
+		if resyncCh != nil && resyncFrom < from {
+			from = resyncFrom
+		}
+		resyncCh = nil
+		resubscribe(from)
+	}
 	// Process TxBatchAppended events.
 	for {
+		// Reorgs take priority, since pending batch events may no longer be canonical.
+		select {
+		case reorg := <-reorgCh:
+			handleReorg(reorg)
+			continue
+		default:
+		}
+		if pendingReorg != nil {
+			select {
+			case reorg := <-reorgCh:
+				handleReorg(reorg)
+			case <-retryCh:
+				handleReorg(nil)
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		if resyncCh != nil {
+			select {
+			case reorg := <-reorgCh:
+				handleReorg(reorg)
+			case <-resyncCh:
+				resyncCh = nil
+				resubscribe(resyncFrom)
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
 		select {
+		case reorg := <-reorgCh:
+			handleReorg(reorg)
 		case ev := <-batchEventCh:
 			log.Info("Processing `TxBatchAppended` event", "l1Block", ev.Raw.BlockNumber)
 			err := b.processTxBatchAppendedEvent(ctx, ev)
 			if err != nil {
-				log.Crit("Failed to process event", "err", err)
+				log.Error("Failed to process event, retrying", "l1Block", ev.Raw.BlockNumber, "err", err)
+				// Later batch events are re-filtered once the failed one is processed.
+				cancel()
+				resyncFrom, resyncCh = ev.Raw.BlockNumber, time.After(syncRetryDelay)
+				continue
 			}
 			if newBatchCh != nil {
 				newBatchCh <- struct{}{}
@@ -188,6 +469,124 @@ func (b *BaseService) SyncLoop(ctx context.Context, start uint64, newBatchCh cha
 	}
 }
 
+// Gets the last L1 block known to be canonical after `reorg`, after which the L2 chain must be re-derived.
+// If the reorg is deeper than the L1 history tracked by the syncer, this is the L1 block of the
+// last indexed batch still on L1 (or the rollup genesis block if there is none).
+func (b *BaseService) ReorgAncestor(ctx context.Context, reorg *client.L1Reorg) (uint64, error) {
+	if reorg.CommonAncestor != nil {
+		return reorg.CommonAncestor.Number.Uint64(), nil
+	}
+	number := b.Chain().CurrentBlock().NumberU64()
+	origin := b.L1Origin(number)
+	// Blocks committed by the sequencer but not yet sequenced have no origin.
+	for origin == nil && number > 0 {
+		number--
+		origin = b.L1Origin(number)
+	}
+	for origin != nil {
+		onL1, err := b.isBatchOnL1(ctx, origin)
+		if err != nil {
+			return 0, err
+		}
+		if onL1 {
+			return origin.L1BlockNumber, nil
+		}
+		blockRange := b.BatchBlockRange(origin.BatchNumber)
+		if blockRange == nil || blockRange.StartBlock == 0 || blockRange.StartBlock > number {
+			break
+		}
+		number = blockRange.StartBlock - 1
+		origin = b.L1Origin(number)
+	}
+	return b.Config.L1RollupGenesisBlock, nil
+}
+
+// Rewinds the L2 chain to the last block sequenced at or before the given L1 block,
+// e.g. after the L1 chain reorged past it.
+// Blocks without an L1 origin predate the index and are not rewound.
+func (b *BaseService) RewindToL1Block(l1BlockNumber uint64) error {
+	start, origins := b.sequencedAfterL1Block(l1BlockNumber)
+	if len(origins) == 0 {
+		return nil
+	}
+	head := b.Chain().CurrentBlock().NumberU64()
+	target := start - 1
+	log.Warn("Rewinding L2 chain", "head", head, "target", target, "l1 block", l1BlockNumber)
+	if err := b.setHead(target); err != nil {
+		return fmt.Errorf("Failed to rewind L2 chain, err: %w", err)
+	}
+	return nil
+}
+
+// Gets the block range and L1 origin of each batch sequenced after the given L1 block, in order,
+// e.g. so that the sequencer can wait for them to be sequenced again after the L1 chain reorged past it.
+func (b *BaseService) BatchesAfterL1Block(l1BlockNumber uint64) ([]*rollupTypes.BlockRange, []*rollupTypes.L1Origin) {
+	start, origins := b.sequencedAfterL1Block(l1BlockNumber)
+	var (
+		ranges       []*rollupTypes.BlockRange
+		batchOrigins []*rollupTypes.L1Origin
+	)
+	for i, origin := range origins {
+		number := start + uint64(i)
+		if i == 0 || origin.BatchNumber != origins[i-1].BatchNumber {
+			ranges = append(ranges, &rollupTypes.BlockRange{StartBlock: number})
+			batchOrigins = append(batchOrigins, origin)
+		}
+		ranges[len(ranges)-1].EndBlock = number
+	}
+	return ranges, batchOrigins
+}
+
+// Drops the L1 origins of the blocks sequenced after the given L1 block, e.g. after the L1 chain
+// reorged past it, without rewinding them (see `BatchesAfterL1Block`).
+func (b *BaseService) UnindexAfterL1Block(l1BlockNumber uint64) error {
+	start, origins := b.sequencedAfterL1Block(l1BlockNumber)
+	if len(origins) == 0 {
+		return nil
+	}
+	log.Warn("Unindexing L2 blocks sequenced in reorged-out L1 blocks", "start", start, "end", start+uint64(len(origins))-1)
+	if safe := b.Chain().CurrentSafeBlock(); safe != nil && safe.NumberU64() >= start {
+		b.Chain().SetSafe(b.Chain().GetBlockByNumber(start - 1))
+	}
+	return b.unindexBlocks(start, origins)
+}
+
+// Gets the L1 origins of the local blocks sequenced after the given L1 block, starting at the
+// returned block number. Blocks not sequenced yet, at the head of the sequencer's chain, are ignored.
+func (b *BaseService) sequencedAfterL1Block(l1BlockNumber uint64) (uint64, []*rollupTypes.L1Origin) {
+	number := b.Chain().CurrentBlock().NumberU64()
+	origin := b.L1Origin(number)
+	for origin == nil && number > 0 {
+		number--
+		origin = b.L1Origin(number)
+	}
+	var origins []*rollupTypes.L1Origin
+	for origin != nil && origin.L1BlockNumber > l1BlockNumber && number > 0 {
+		origins = append(origins, origin)
+		number--
+		origin = b.L1Origin(number)
+	}
+	// Blocks were collected from the last one.
+	for i, j := 0, len(origins)-1; i < j; i, j = i+1, j-1 {
+		origins[i], origins[j] = origins[j], origins[i]
+	}
+	return number + 1, origins
+}
+
+// Drops the index entries of blocks [start, start+len(origins)), given their L1 origins.
+func (b *BaseService) unindexBlocks(start uint64, origins []*rollupTypes.L1Origin) error {
+	batch := b.Eth.ChainDb().NewBatch()
+	for i, origin := range origins {
+		number := start + uint64(i)
+		rollupRawdb.DeleteBatchBlockRange(batch, origin.BatchNumber)
+		rollupRawdb.DeleteL1Origin(batch, number)
+	}
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("Failed to delete L1 origins, err: %w", err)
+	}
+	return nil
+}
+
 func filterAssertionCreatedWithID(iter *bindings.IRollupAssertionCreatedIterator, assertionID *big.Int) (*bindings.IRollupAssertionCreated, error) {
 	var assertionCreated *bindings.IRollupAssertionCreated
 	for iter.Next() {
@@ -222,30 +621,165 @@ func (b *BaseService) processTxBatchAppendedEvents(
 	return nil
 }
 
+// Finds the L1 block to resume syncing from using the L1 origin index.
+// Returns false if the local head is not indexed or its batch is no longer on L1.
+func (b *BaseService) findL1SyncStartFromIndex(ctx context.Context, head uint64) (uint64, bool, error) {
+	// Blocks committed by the sequencer but not yet sequenced have no origin.
+	number := head
+	origin := b.L1Origin(number)
+	for origin == nil && number > 0 {
+		number--
+		origin = b.L1Origin(number)
+	}
+	if origin == nil {
+		return 0, false, nil
+	}
+	blockRange := b.BatchBlockRange(origin.BatchNumber)
+	if blockRange == nil || blockRange.EndBlock != number {
+		return 0, false, nil
+	}
+	found, err := b.isBatchOnL1(ctx, origin)
+	if err != nil {
+		return 0, false, err
+	}
+	if !found {
+		log.Warn("Indexed batch not found on L1", "batch", origin.BatchNumber, "l1 block", origin.L1BlockNumber)
+		return 0, false, nil
+	}
+	if err := b.rewindUnsequenced(number); err != nil {
+		return 0, false, err
+	}
+	log.Info("Resuming from local chain", "l2 head", number, "batch", origin.BatchNumber, "l1 block", origin.L1BlockNumber)
+	return origin.L1BlockNumber, true, nil
+}
+
+// Checks whether the batch of L1 origin `origin` is still on L1.
+func (b *BaseService) isBatchOnL1(ctx context.Context, origin *rollupTypes.L1Origin) (bool, error) {
+	opts := bind.FilterOpts{Start: origin.L1BlockNumber, End: &origin.L1BlockNumber, Context: ctx}
+	eventsIter, err := b.L1Client.FilterTxBatchAppendedEvents(&opts)
+	if err != nil {
+		return false, fmt.Errorf("Failed to filter `TxBatchAppended` events, err: %w", err)
+	}
+	found := false
+	for eventsIter.Next() {
+		if eventsIter.Event.Raw.TxHash == origin.L1TxHash && eventsIter.Event.BatchNumber.Uint64() == origin.BatchNumber {
+			found = true
+			break
+		}
+	}
+	if err := eventsIter.Error(); err != nil {
+		return false, fmt.Errorf("Failed to iterate through `TxBatchAppended` events, err: %w", err)
+	}
+	return found, nil
+}
+
 // Reads tx data associated with batch event and commits as blocks on L2.
+// Every batch is derived into at least one block on top of the local chain, in inbox order:
+// an invalid batch is derived as an empty block (see `skipInvalidBatch`). Batches already
+// derived, e.g. when re-processing batches after a restart, are skipped.
 func (b *BaseService) processTxBatchAppendedEvent(
 	ctx context.Context,
 	ev *bindings.ISequencerInboxTxBatchAppended,
 ) error {
+	if b.isDerived(ev) {
+		log.Info("Skipping derived batch", "batch", ev.BatchNumber)
+		return nil
+	}
+	batch, err := b.decodeTxBatchAppendedEvent(ctx, ev)
+	if err != nil {
+		return b.skipInvalidBatch(ev, err)
+	}
+	log.Info("Decoded batch", "#txs", len(batch.Txs))
+	blocks := batch.SplitToBlocks()
+	log.Info("Batch split into blocks", "#blocks", len(blocks))
+	head := b.Chain().CurrentBlock().NumberU64()
+	// Blocks of a local chain predating the index (see `findL1SyncStart`) can only be matched by number.
+	legacy := head > 0 && b.L1Origin(head) == nil
+	if legacy && len(blocks) > 0 && blocks[len(blocks)-1].BlockNumber <= head && b.matchLocalChain(blocks) == nil {
+		log.Info("Skipping committed batch", "batch", ev.BatchNumber)
+		return nil
+	}
+	if err := b.commitBlocks(blocks); err != nil {
+		return b.skipInvalidBatch(ev, err)
+	}
+	sequencedNumbers := make([]uint64, 0, len(blocks))
+	for _, block := range blocks {
+		sequencedNumbers = append(sequencedNumbers, block.BlockNumber)
+	}
+	b.indexBatch(ev, head+1, b.Chain().CurrentBlock().NumberU64(), sequencedNumbers)
+	return nil
+}
+
+// Checks whether the batch of `ev` is indexed as derived into local blocks.
+func (b *BaseService) isDerived(ev *bindings.ISequencerInboxTxBatchAppended) bool {
+	blockRange := b.BatchBlockRange(ev.BatchNumber.Uint64())
+	if blockRange == nil {
+		return false
+	}
+	origin := b.L1Origin(blockRange.StartBlock)
+	return origin != nil && origin.L1TxHash == ev.Raw.TxHash
+}
+
+// Skips the batch of `ev` if `err` makes it invalid, so that a malformed or hostile
+// batch never halts derivation. Other errors are returned.
+// The skipped batch is derived as a single empty block on top of the local chain, with
+// the timestamp and coinbase of its parent, and recorded (see `SkippedBatch`). Its txs are
+// thus still counted in the inbox size of the local chain (see `NumInboxTxs`), which keeps
+// matching the L1 inbox and the assertions made on it.
+func (b *BaseService) skipInvalidBatch(ev *bindings.ISequencerInboxTxBatchAppended, err error) error {
+	if !errors.Is(err, errInvalidBatch) {
+		return err
+	}
+	log.Error("Skipping invalid batch", "batch", ev.BatchNumber, "l1 tx", ev.Raw.TxHash, "err", err)
+	skipped := &rollupTypes.SkippedBatch{
+		BatchNumber: ev.BatchNumber.Uint64(),
+		NumTxs:      new(big.Int).Sub(ev.EndTxNumber, ev.StartTxNumber).Uint64(),
+		Reason:      err.Error(),
+	}
+	parent := b.Chain().CurrentBlock()
+	executor := NewBlockExecutor(b.Chain())
+	result, err := executor.ExecuteBlock(&PayloadAttributes{
+		Timestamp:    parent.Time(),
+		FeeRecipient: parent.Coinbase(),
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to derive skipped batch %v, err: %w", ev.BatchNumber, err)
+	}
+	number := parent.NumberU64() + 1
+	rollupRawdb.WriteSkippedBatch(b.Eth.ChainDb(), number, skipped)
+	if err := executor.CommitBlock(result); err != nil {
+		return err
+	}
+	b.IndexBatch(ev, number, number)
+	return nil
+}
+
+// Reads and decodes the tx batch associated with a batch event.
+func (b *BaseService) decodeTxBatchAppendedEvent(
+	ctx context.Context,
+	ev *bindings.ISequencerInboxTxBatchAppended,
+) (*rollupTypes.TxBatch, error) {
 	tx, _, err := b.L1Client.TransactionByHash(ctx, ev.Raw.TxHash)
 	if err != nil {
-		return fmt.Errorf("Failed to get transaction associated with TxBatchAppended event, err: %w", err)
+		return nil, fmt.Errorf("Failed to get transaction associated with TxBatchAppended event, err: %w", err)
 	}
 	// Decode input to appendTxBatch transaction.
 	decoded, err := b.L1Client.DecodeAppendTxBatchInput(tx)
 	if err != nil {
-		return fmt.Errorf("Failed to decode transaction associated with TxBatchAppended event, err: %w", err)
+		return nil, fmt.Errorf("%w: failed to decode transaction associated with TxBatchAppended event, err: %v", errInvalidBatch, err)
 	}
 	// Construct batch. TODO: decode into blocks directly.
 	batch, err := rollupTypes.TxBatchFromDecoded(decoded)
 	if err != nil {
-		return fmt.Errorf("Failed to split AppendTxBatch input into batches, err: %w", err)
+		return nil, fmt.Errorf("%w: failed to split AppendTxBatch input into batches, err: %v", errInvalidBatch, err)
 	}
-	log.Info("Decoded batch", "#txs", len(batch.Txs))
-	blocks := batch.SplitToBlocks()
-	log.Info("Batch split into blocks", "#blocks", len(blocks))
-	b.commitBlocks(blocks)
-	return nil
+	// Only the inbox's sequencer can append batches, so the sender is the sequencer committed to by the batch.
+	sequencer, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to recover sender of TxBatchAppended transaction, err: %w", err)
+	}
+	batch.SetSequencer(sequencer)
+	return batch, nil
 }
 
 // TODO: clean up.
@@ -289,74 +823,91 @@ func (b *BaseService) setL2BlockBoundaries(
 	return fmt.Errorf("Could not find start or end block for assertion with hash %s", assertion.VmHash)
 }
 
-// commitBlocks executes and commits sequenced blocks to local blockchain
-// TODO: this function shares a lot of codes with Batcher
-// TODO: use StateProcessor::Process() instead
+// commitBlocks executes and commits sequenced blocks to local blockchain.
+// Sequenced txs failing to execute (e.g. with a stale nonce or insufficient funds),
+// or not fitting into the block gas limit, are skipped: the block is built without
+// them, and they are recorded (see `SkippedTxs`). This is the same rule the
+// sequencer applies when building blocks, so an invalid tx never halts derivation.
+// Blocks are numbered after the local head, in order: the block numbers of the batch
+// contexts are not trusted, so that later batches still extend the local chain after a
+// batch is skipped. Returns `errInvalidBatch` if there are no blocks.
+// All blocks are executed before any is committed, so that a batch is never partially derived:
+// if committing fails, the local chain is rewound to its head before the batch.
 func (b *BaseService) commitBlocks(blocks []*rollupTypes.SequenceBlock) error {
 	if len(blocks) == 0 {
-		return nil
-	}
-	chainConfig := b.Chain().Config()
-	parent := b.Chain().CurrentBlock()
-	if parent == nil {
-		return fmt.Errorf("missing parent")
+		return fmt.Errorf("%w: no sequenced blocks", errInvalidBatch)
 	}
-	num := parent.Number()
-	if num.Uint64() != blocks[0].BlockNumber-1 {
-		return fmt.Errorf("rollup services unsynced")
+	executor := NewBlockExecutor(b.Chain())
+	attrs := make([]*PayloadAttributes, 0, len(blocks))
+	for _, sblock := range blocks {
+		attrs = append(attrs, &PayloadAttributes{
+			Timestamp:    sblock.Timestamp,
+			FeeRecipient: sblock.Coinbase,
+			Txs:          sblock.Txs,
+		})
 	}
-	state, err := b.Chain().StateAt(parent.Root())
+	results, err := executor.ExecuteBlocks(attrs)
 	if err != nil {
 		return err
 	}
-	state.StartPrefetcher("rollup")
-	defer state.StopPrefetcher()
-
-	for _, sblock := range blocks {
-		header := &types.Header{
-			ParentHash: parent.Hash(),
-			Number:     new(big.Int).SetUint64(sblock.BlockNumber),
-			GasLimit:   core.CalcGasLimit(parent.GasLimit(), ethconfig.Defaults.Miner.GasCeil), // TODO: this may cause problem if the gas limit generated on sequencer side mismatch with this one
-			Time:       sblock.Timestamp,
-			Coinbase:   b.Config.SequencerAddr,
-			Difficulty: common.Big1, // Fake difficulty. Avoid use 0 here because it means the merge happened
-		}
-		gasPool := new(core.GasPool).AddGas(header.GasLimit)
-		var receipts []*types.Receipt
-		for idx, tx := range sblock.Txs {
-			state.Prepare(tx.Hash(), idx)
-			receipt, err := core.ApplyTransaction(
-				chainConfig, b.Chain(), &b.Config.SequencerAddr, gasPool, state, header, tx, &header.GasUsed, *b.Chain().GetVMConfig())
-			if err != nil {
-				return err
-			}
-			receipts = append(receipts, receipt)
-		}
-		// Finalize header
-		header.Root = state.IntermediateRoot(b.Chain().Config().IsEIP158(header.Number))
-		header.UncleHash = types.CalcUncleHash(nil)
-		// Assemble block
-		block := types.NewBlock(header, sblock.Txs, nil, receipts, trie.NewStackTrie(nil))
-		hash := block.Hash()
-		// Finalize receipts and logs
-		var logs []*types.Log
-		for i, receipt := range receipts {
-			// Add block location fields
-			receipt.BlockHash = hash
-			receipt.BlockNumber = block.Number()
-			receipt.TransactionIndex = uint(i)
-
-			// Update the block hash in all logs since it is now available and not when the
-			// receipt/log of individual transactions were created.
-			for _, log := range receipt.Logs {
-				log.BlockHash = hash
+	head := b.Chain().CurrentBlock().NumberU64()
+	for i, result := range results {
+		if err := executor.CommitBlock(result); err != nil {
+			if rewindErr := b.setHead(head); rewindErr != nil {
+				log.Error("Failed to rewind partially committed batch", "head", head, "err", rewindErr)
 			}
-			logs = append(logs, receipt.Logs...)
-		}
-		_, err := b.Chain().WriteBlockAndSetHead(block, receipts, logs, state, true)
-		if err != nil {
 			return err
 		}
+		sblock := blocks[i]
+		var skipped []*rollupTypes.SkippedTx
+		for _, dropped := range result.Dropped {
+			skipped = append(skipped, &rollupTypes.SkippedTx{
+				Index:  uint64(dropped.Index),
+				Tx:     dropped.Tx,
+				Reason: dropped.Reason.Error(),
+			})
+		}
+		for i, tx := range result.Remaining {
+			skipped = append(skipped, &rollupTypes.SkippedTx{
+				Index:  uint64(len(sblock.Txs) - len(result.Remaining) + i),
+				Tx:     tx,
+				Reason: core.ErrGasLimitReached.Error(),
+			})
+		}
+		if len(skipped) > 0 {
+			number := result.Block.NumberU64()
+			log.Warn("Skipping invalid sequenced txs", "block", number, "#skipped", len(skipped))
+			rollupRawdb.WriteSkippedTxs(b.Eth.ChainDb(), number, skipped)
+		}
 	}
 	return nil
 }
+
+// Rewinds the local chain to block `target`, dropping the index entries and skipped txs and batches of rewound blocks.
+// The safe and finalized heads are moved back to the new head if beyond it, e.g. after an L1 reorg
+// or when the local chain mismatches L1 on startup.
+func (b *BaseService) setHead(target uint64) error {
+	head := b.Chain().CurrentBlock().NumberU64()
+	if err := b.Chain().SetHead(target); err != nil {
+		return err
+	}
+	current := b.Chain().CurrentBlock()
+	if safe := b.Chain().CurrentSafeBlock(); safe != nil && safe.NumberU64() > current.NumberU64() {
+		b.Chain().SetSafe(current)
+	}
+	if finalized := b.Chain().CurrentFinalizedBlock(); finalized != nil && finalized.NumberU64() > current.NumberU64() {
+		log.Warn("Rewinding finalized head", "finalized", finalized.NumberU64(), "target", current.NumberU64())
+		b.Chain().SetFinalized(current)
+	}
+	batch := b.Eth.ChainDb().NewBatch()
+	for number := target + 1; number <= head; number++ {
+		if origin := b.L1Origin(number); origin != nil {
+			rollupRawdb.DeleteBatchBlockRange(batch, origin.BatchNumber)
+		}
+		rollupRawdb.DeleteL1Origin(batch, number)
+		rollupRawdb.DeleteSkippedTxs(batch, number)
+		rollupRawdb.DeleteSkippedBatch(batch, number)
+		rollupRawdb.DeleteBlockAssertionID(batch, number)
+	}
+	return batch.Write()
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/specularl2/specular/clients/geth/specular/bindings"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+)
+
+func newSequenceBlock(number uint64, txs types.Transactions) *rollupTypes.SequenceBlock {
+	return &rollupTypes.SequenceBlock{
+		SequenceContext: rollupTypes.SequenceContext{
+			NumTxs:      uint64(len(txs)),
+			BlockNumber: number,
+			Timestamp:   10 * number,
+			Coinbase:    testSequencer,
+		},
+		Txs: txs,
+	}
+}
+
+// Invalid sequenced txs must be skipped and recorded, without failing derivation.
+func TestCommitBlocksSkipsInvalidTxs(t *testing.T) {
+	config := testChainConfig(0)
+	validator := &BaseService{Config: &Config{}, Eth: newTestBackend(t, config)}
+	recipient := common.HexToAddress("0x1000")
+	transfer := func(nonce uint64, value *big.Int) types.TxData {
+		return &types.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(2 * params.GWei), Gas: params.TxGas, To: &recipient, Value: value}
+	}
+	txs := signTxs(t, config, testKey, []types.TxData{
+		transfer(0, common.Big1),
+		transfer(0, common.Big1),                // Stale nonce
+		transfer(1, big.NewInt(2*params.Ether)), // Insufficient funds
+		transfer(1, common.Big1),
+		&types.LegacyTx{Nonce: 2, GasPrice: common.Big1, Gas: params.TxGas, To: &recipient}, // Fee cap below base fee
+	})
+	sequenced := []*rollupTypes.SequenceBlock{newSequenceBlock(1, txs)}
+	if err := validator.commitBlocks(sequenced); err != nil {
+		t.Fatalf("failed to commit blocks: %v", err)
+	}
+	block := validator.Chain().CurrentBlock()
+	if block.NumberU64() != 1 {
+		t.Fatalf("have head #%d, want #1", block.NumberU64())
+	}
+	if included := block.Transactions(); len(included) != 2 || included[0].Hash() != txs[0].Hash() || included[1].Hash() != txs[3].Hash() {
+		t.Fatalf("unexpected included txs %v", included)
+	}
+	skipped := validator.SkippedTxs(1)
+	if len(skipped) != 3 {
+		t.Fatalf("have %d skipped txs, want 3", len(skipped))
+	}
+	for i, index := range []uint64{1, 2, 4} {
+		if skipped[i].Index != index || skipped[i].Tx.Hash() != txs[index].Hash() || skipped[i].Reason == "" {
+			t.Errorf("skipped tx %d: have index %d, hash %s, reason %q", i, skipped[i].Index, skipped[i].Tx.Hash(), skipped[i].Reason)
+		}
+	}
+	// Skipped txs still count towards the inbox.
+	if n := validator.NumInboxTxs(block); n != uint64(len(txs)) {
+		t.Errorf("have %d inbox txs, want %d", n, len(txs))
+	}
+	all, positions := validator.sequencedTxs(block)
+	for i, tx := range all {
+		if tx.Hash() != txs[i].Hash() {
+			t.Errorf("sequenced tx %d mismatch", i)
+		}
+	}
+	if len(positions) != 2 || positions[0] != 0 || positions[1] != 3 {
+		t.Errorf("unexpected positions %v", positions)
+	}
+}
+
+// Sequenced blocks must be committed after the local head, whatever their block numbers.
+func TestCommitBlocksNumbering(t *testing.T) {
+	config := testChainConfig(0)
+	validator := &BaseService{Config: &Config{}, Eth: newTestBackend(t, config)}
+	if err := validator.commitBlocks([]*rollupTypes.SequenceBlock{newSequenceBlock(1, nil)}); err != nil {
+		t.Fatalf("failed to commit blocks: %v", err)
+	}
+	txs := testTxs(t, config, common.Big2, 0)
+	tests := []struct {
+		name   string
+		blocks []*rollupTypes.SequenceBlock
+	}{
+		{"gap", []*rollupTypes.SequenceBlock{newSequenceBlock(3, txs[:1])}},
+		{"not contiguous", []*rollupTypes.SequenceBlock{newSequenceBlock(2, txs[1:2]), newSequenceBlock(4, nil)}},
+		{"behind local head", []*rollupTypes.SequenceBlock{newSequenceBlock(1, txs[2:])}},
+	}
+	for _, tt := range tests {
+		head := validator.Chain().CurrentBlock().NumberU64()
+		if err := validator.commitBlocks(tt.blocks); err != nil {
+			t.Fatalf("%s: failed to commit blocks: %v", tt.name, err)
+		}
+		for i, sblock := range tt.blocks {
+			block := validator.Chain().GetBlockByNumber(head + 1 + uint64(i))
+			if block == nil || len(block.Transactions()) != len(sblock.Txs) {
+				t.Fatalf("%s: block #%d not committed after head #%d", tt.name, sblock.BlockNumber, head)
+			}
+		}
+	}
+	// Skipped txs are recorded under the local block number.
+	head := validator.Chain().CurrentBlock().NumberU64()
+	if err := validator.commitBlocks([]*rollupTypes.SequenceBlock{newSequenceBlock(10, txs[:1])}); err != nil {
+		t.Fatalf("failed to commit blocks: %v", err)
+	}
+	if skipped := validator.SkippedTxs(head + 1); len(skipped) != 1 || skipped[0].Tx.Hash() != txs[0].Hash() {
+		t.Errorf("have skipped txs %v in block #%d, want stale tx", skipped, head+1)
+	}
+	if skipped := validator.SkippedTxs(10); skipped != nil {
+		t.Error("skipped txs recorded under sequenced block number")
+	}
+	if err := validator.commitBlocks(nil); !errors.Is(err, errInvalidBatch) {
+		t.Fatalf("have error %v, want invalid batch", err)
+	}
+}
+
+// L1 client serving the batches appended to a test inbox.
+type testBatchClient struct {
+	*testTxClient
+	// Input of the decodable `appendTxBatch` txs
+	decoded    map[common.Hash][]interface{}
+	numTxs     uint64
+	numBatches uint64
+}
+
+func newTestBatchClient() *testBatchClient {
+	return &testBatchClient{
+		testTxClient: &testTxClient{txs: make(map[common.Hash]*types.Transaction)},
+		decoded:      make(map[common.Hash][]interface{}),
+	}
+}
+
+func (c *testBatchClient) DecodeAppendTxBatchInput(tx *types.Transaction) ([]interface{}, error) {
+	decoded, ok := c.decoded[tx.Hash()]
+	if !ok {
+		return nil, errors.New("invalid appendTxBatch input")
+	}
+	return decoded, nil
+}
+
+// Appends a batch of `blocks` to the inbox, or an undecodable batch of `numTxs` txs if `blocks` is nil.
+func (c *testBatchClient) appendBatch(t *testing.T, blocks []*rollupTypes.SequenceBlock, numTxs uint64) *bindings.ISequencerInboxTxBatchAppended {
+	batch := new(rollupTypes.TxBatch)
+	for _, sblock := range blocks {
+		batch.Contexts = append(batch.Contexts, sblock.SequenceContext)
+		batch.Txs = append(batch.Txs, sblock.Txs...)
+	}
+	contexts, txLengths, data, err := batch.SerializeToArgs()
+	if err != nil {
+		t.Fatalf("failed to serialize batch: %v", err)
+	}
+	tx, err := types.SignNewTx(testKey, types.LatestSignerForChainID(testChainID), &types.LegacyTx{Nonce: c.numBatches, Data: data})
+	if err != nil {
+		t.Fatalf("failed to sign batch tx: %v", err)
+	}
+	c.txs[tx.Hash()] = tx
+	if blocks != nil {
+		c.decoded[tx.Hash()] = []interface{}{contexts, txLengths, data}
+		numTxs = uint64(len(batch.Txs))
+	}
+	ev := &bindings.ISequencerInboxTxBatchAppended{
+		BatchNumber:   new(big.Int).SetUint64(c.numBatches),
+		StartTxNumber: new(big.Int).SetUint64(c.numTxs),
+		EndTxNumber:   new(big.Int).SetUint64(c.numTxs + numTxs),
+		Raw:           types.Log{BlockNumber: 100 + c.numBatches, TxHash: tx.Hash()},
+	}
+	c.numBatches++
+	c.numTxs += numTxs
+	return ev
+}
+
+// A batch that cannot be derived must be derived as an empty block counting its txs in the inbox,
+// and must not prevent later batches from being derived.
+func TestProcessSkippedBatch(t *testing.T) {
+	config := testChainConfig(0)
+	l1Client := newTestBatchClient()
+	validator := &BaseService{Config: &Config{}, Eth: newTestBackend(t, config), L1Client: l1Client}
+	txs := testTxs(t, config, common.Big1, 0)
+	events := []*bindings.ISequencerInboxTxBatchAppended{
+		l1Client.appendBatch(t, []*rollupTypes.SequenceBlock{newSequenceBlock(1, txs[:1])}, 0),
+		l1Client.appendBatch(t, nil, 4),
+		// Numbered after the 3 blocks the sequencer derived from the skipped batch.
+		l1Client.appendBatch(t, []*rollupTypes.SequenceBlock{newSequenceBlock(5, txs[1:2]), newSequenceBlock(6, txs[2:])}, 0),
+	}
+	ctx := context.Background()
+	for _, ev := range events {
+		if err := validator.processTxBatchAppendedEvent(ctx, ev); err != nil {
+			t.Fatalf("failed to process batch %v: %v", ev.BatchNumber, err)
+		}
+	}
+	head := validator.Chain().CurrentBlock()
+	if head.NumberU64() != 4 {
+		t.Fatalf("have head #%d, want #4", head.NumberU64())
+	}
+	parent, skippedBlock := validator.Chain().GetBlockByNumber(1), validator.Chain().GetBlockByNumber(2)
+	if len(skippedBlock.Transactions()) != 0 || skippedBlock.Time() != parent.Time() || skippedBlock.Coinbase() != parent.Coinbase() {
+		t.Errorf("skipped batch derived as block with %d txs, time %d, coinbase %s", len(skippedBlock.Transactions()), skippedBlock.Time(), skippedBlock.Coinbase())
+	}
+	skipped := validator.SkippedBatch(2)
+	if skipped == nil || skipped.BatchNumber != 1 || skipped.NumTxs != 4 || skipped.Reason == "" {
+		t.Fatalf("unexpected skipped batch record %+v", skipped)
+	}
+	for i, want := range []*rollupTypes.BlockRange{{StartBlock: 1, EndBlock: 1}, {StartBlock: 2, EndBlock: 2}, {StartBlock: 3, EndBlock: 4}} {
+		if have := validator.BatchBlockRange(uint64(i)); have == nil || *have != *want {
+			t.Errorf("batch %d: have block range %+v, want %+v", i, have, want)
+		}
+	}
+	// The sequenced block numbers are kept for the inclusion proofs of the batch.
+	for i, want := range []uint64{5, 6} {
+		origin := validator.L1Origin(uint64(3 + i))
+		if origin == nil || origin.SequencedBlockNumber == nil || *origin.SequencedBlockNumber != want {
+			t.Errorf("block #%d: have origin %+v, want sequenced block number %d", 3+i, origin, want)
+		}
+	}
+	for i, tx := range txs[1:] {
+		if included := validator.Chain().GetBlockByNumber(uint64(3 + i)).Transactions(); len(included) != 1 || included[0].Hash() != tx.Hash() {
+			t.Errorf("block #%d: unexpected txs %v", 3+i, included)
+		}
+	}
+	// The local chain accounts for all inbox txs, including those of the skipped batch.
+	var inboxSize uint64
+	for n := uint64(1); n <= head.NumberU64(); n++ {
+		inboxSize += validator.NumInboxTxs(validator.Chain().GetBlockByNumber(n))
+	}
+	if inboxSize != l1Client.numTxs {
+		t.Errorf("have local inbox size %d, want %d", inboxSize, l1Client.numTxs)
+	}
+	if end, err := validator.FindInboxSizeEnd(1, common.Big0, events[1].EndTxNumber); err != nil || end != 2 {
+		t.Errorf("have inbox end #%d (err %v) after skipped batch, want #2", end, err)
+	}
+	// Re-processing the batches is a no-op.
+	for _, ev := range events {
+		if err := validator.processTxBatchAppendedEvent(ctx, ev); err != nil {
+			t.Fatalf("failed to re-process batch %v: %v", ev.BatchNumber, err)
+		}
+	}
+	if validator.Chain().CurrentBlock().Hash() != head.Hash() {
+		t.Fatal("head changed on re-processing")
+	}
+	// Rewinding drops the record.
+	if err := validator.setHead(1); err != nil {
+		t.Fatalf("failed to rewind: %v", err)
+	}
+	if validator.SkippedBatch(2) != nil || validator.BatchBlockRange(1) != nil {
+		t.Error("skipped batch still recorded after rewind")
+	}
+}
+
+// Blocks rewound on startup must keep their txs, in order, for the sequencer to re-queue.
+func TestRewindUnsequencedKeepsTxs(t *testing.T) {
+	config := testChainConfig(0)
+	backend := newTestBackend(t, config)
+	engine := NewLocalEngine(backend)
+	var blocks types.Blocks
+	for i := 1; i <= 3; i++ {
+		payload, err := engine.BuildPayload(&PayloadAttributes{
+			Timestamp:    uint64(10 * i),
+			FeeRecipient: testSequencer,
+			Txs:          testTxs(t, config, big.NewInt(int64(i)), uint64(3*(i-1))),
+		})
+		if err != nil {
+			t.Fatalf("failed to build block #%d: %v", i, err)
+		}
+		blocks = append(blocks, payload.Block)
+	}
+	service := &BaseService{Config: &Config{}, Eth: backend}
+	if err := service.matchLocalChain(sequenceBlocks(blocks)); err != nil {
+		t.Fatalf("sequenced blocks mismatch local chain: %v", err)
+	}
+	if err := service.rewindUnsequenced(1); err != nil {
+		t.Fatalf("failed to rewind: %v", err)
+	}
+	if head := service.Chain().CurrentBlock().NumberU64(); head != 1 {
+		t.Fatalf("have head #%d, want #1", head)
+	}
+	var want types.Transactions
+	for _, block := range blocks[1:] {
+		want = append(want, block.Transactions()...)
+	}
+	if len(service.RewoundTxs) != len(want) {
+		t.Fatalf("have %d rewound txs, want %d", len(service.RewoundTxs), len(want))
+	}
+	for i, tx := range want {
+		if service.RewoundTxs[i].Hash() != tx.Hash() {
+			t.Errorf("rewound tx %d mismatch", i)
+		}
+	}
+	if err := service.matchLocalChain(sequenceBlocks(blocks)); err == nil {
+		t.Fatal("rewound blocks still match local chain")
+	}
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/specularl2/specular/clients/geth/specular/bindings"
+	"github.com/specularl2/specular/clients/geth/specular/proof"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
+	rollupRawdb "github.com/specularl2/specular/clients/geth/specular/rollup/rawdb"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// Stores the record of a challenge in progress, so that it can be resumed after a restart.
+func (b *BaseService) SaveChallenge(info *rollupTypes.ChallengeInfo) {
+	rollupRawdb.WriteChallengeInfo(b.Eth.ChainDb(), info)
+}
+
+// Gets the records of the challenges in progress.
+func (b *BaseService) PendingChallenges() []*rollupTypes.ChallengeInfo {
+	return rollupRawdb.ReadAllChallengeInfos(b.Eth.ChainDb())
+}
+
+// Removes the record and the execution trace of a finished challenge.
+func (b *BaseService) DeleteChallenge(challengeAddr common.Address) {
+	rollupRawdb.DeleteChallengeTrace(b.Eth.ChainDb(), challengeAddr)
+	rollupRawdb.DeleteChallengeInfo(b.Eth.ChainDb(), challengeAddr)
+}
+
+// Gets the challenges to resume after a restart: the ones recorded locally or, if none, the challenge the node
+// is engaged in according to its L1 staker record (e.g. if the node stopped before recording it).
+func (b *BaseService) ChallengesToResume(ctx context.Context) ([]*rollupTypes.ChallengeInfo, error) {
+	if pending := b.PendingChallenges(); len(pending) > 0 {
+		return pending, nil
+	}
+	info, err := b.FindCurrentChallenge(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, nil
+	}
+	return []*rollupTypes.ChallengeInfo{info}, nil
+}
+
+// Rebuilds the record of the challenge the node is engaged in from L1, or returns nil if there is none.
+func (b *BaseService) FindCurrentChallenge(ctx context.Context) (*rollupTypes.ChallengeInfo, error) {
+	staker, err := b.L1Client.GetStaker()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get staker, err: %w", err)
+	}
+	if !staker.IsStaked || staker.CurrentChallenge == (common.Address{}) {
+		return nil, nil
+	}
+	ev, err := b.findAssertionChallenged(ctx, staker.CurrentChallenge)
+	if err != nil {
+		return nil, err
+	}
+	defenderAssertion, err := b.L1Client.GetAssertion(ev.AssertionID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get challenged assertion, err: %w", err)
+	}
+	parent, err := b.L1Client.GetAssertion(defenderAssertion.Parent)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get parent assertion, err: %w", err)
+	}
+	var parentEnd uint64
+	if blockRange := b.AssertionBlockRange(defenderAssertion.Parent); blockRange != nil {
+		parentEnd = blockRange.EndBlock
+	} else {
+		parentEnd, err = b.FindInboxSizeEnd(1, common.Big0, parent.InboxSize)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to find parent assertion blocks, err: %w", err)
+		}
+	}
+	end, err := b.FindInboxSizeEnd(parentEnd+1, parent.InboxSize, defenderAssertion.InboxSize)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find challenged assertion blocks, err: %w", err)
+	}
+	info := &rollupTypes.ChallengeInfo{
+		Address:       staker.CurrentChallenge,
+		L1BlockNumber: ev.Raw.BlockNumber,
+		StartBlock:    parentEnd + 1,
+		EndBlock:      end,
+		InboxSize:     defenderAssertion.InboxSize,
+	}
+	if ev.AssertionID.Cmp(staker.AssertionID) == 0 {
+		// We are the defender
+		info.VmHash = defenderAssertion.StateHash
+	} else {
+		// We are the challenger, staked on a sibling of the challenged assertion
+		ourAssertion, err := b.L1Client.GetAssertion(staker.AssertionID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get staked assertion, err: %w", err)
+		}
+		info.VmHash = ourAssertion.StateHash
+		info.OpponentVmHash = defenderAssertion.StateHash
+	}
+	log.Info("Found challenge on L1", "address", info.Address, "assertion", ev.AssertionID, "start", info.StartBlock, "end", info.EndBlock)
+	return info, nil
+}
+
+// Finds the `AssertionChallenged` event that created the challenge at `challengeAddr`.
+func (b *BaseService) findAssertionChallenged(
+	ctx context.Context,
+	challengeAddr common.Address,
+) (*bindings.IRollupAssertionChallenged, error) {
+	iter, err := b.L1Client.FilterAssertionChallenged(&bind.FilterOpts{Start: b.Config.L1RollupGenesisBlock, Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to filter challenges, err: %w", err)
+	}
+	defer iter.Close()
+	for iter.Next() {
+		if iter.Event.ChallengeAddr == challengeAddr {
+			return iter.Event, nil
+		}
+	}
+	if iter.Error() != nil {
+		return nil, fmt.Errorf("Failed to iterate challenges, err: %w", iter.Error())
+	}
+	return nil, fmt.Errorf("Creation of challenge %s not found", challengeAddr)
+}
+
+// MaxChallengeLength is the longest challenge played, so that step offsets and the number of states
+// (steps + 1) of the challenged trace fit in a uint64.
+const MaxChallengeLength = math.MaxUint64 - 1
+
+// Returned for a challenge length committed by the defender that cannot be played.
+var ErrInvalidChallengeLength = errors.New("invalid challenge length")
+
+// Converts the challenge length committed on L1 (see `initializeChallengeLength`),
+// which must be in [1, MaxChallengeLength].
+func ChallengeLength(numSteps *big.Int) (uint64, error) {
+	if numSteps.Sign() <= 0 || !numSteps.IsUint64() || numSteps.Uint64() > MaxChallengeLength {
+		return 0, fmt.Errorf("%w: %v steps", ErrInvalidChallengeLength, numSteps)
+	}
+	return numSteps.Uint64(), nil
+}
+
+// ChallengeProgress is the progress of a challenge session on L1.
+type ChallengeProgress struct {
+	NumSteps     uint64                          // Challenge length committed by the defender (0 if not initialized)
+	LastBisected *bindings.ISymChallengeBisected // Latest `Bisected` event (nil if not initialized)
+	Completed    bool
+	Winner       common.Address // Set if completed
+}
+
+// Gets the progress of a challenge from its L1 events in blocks [start, end].
+func GetChallengeProgress(
+	ctx context.Context,
+	chalClient client.SymChallengeClient,
+	start, end uint64,
+) (*ChallengeProgress, error) {
+	opts := &bind.FilterOpts{Start: start, End: &end, Context: ctx}
+	completedIter, err := chalClient.FilterChallengeCompleted(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to filter challenge completion, err: %w", err)
+	}
+	defer completedIter.Close()
+	if completedIter.Next() {
+		return &ChallengeProgress{Completed: true, Winner: completedIter.Event.Winner}, nil
+	}
+	if completedIter.Error() != nil {
+		return nil, fmt.Errorf("Failed to iterate challenge completion, err: %w", completedIter.Error())
+	}
+	bisectedIter, err := chalClient.FilterBisected(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to filter bisections, err: %w", err)
+	}
+	defer bisectedIter.Close()
+	progress := &ChallengeProgress{}
+	for bisectedIter.Next() {
+		if progress.LastBisected == nil {
+			// The first event is emitted by `initializeChallengeLength`
+			if progress.NumSteps, err = ChallengeLength(bisectedIter.Event.ChallengedSegmentLength); err != nil {
+				return nil, err
+			}
+		}
+		progress.LastBisected = bisectedIter.Event
+	}
+	if bisectedIter.Error() != nil {
+		return nil, fmt.Errorf("Failed to iterate bisections, err: %w", bisectedIter.Error())
+	}
+	return progress, nil
+}
+
+// AsymChallengeProgress is the progress of an asymmetric challenge session on L1.
+type AsymChallengeProgress struct {
+	NumSteps uint64 // Challenge length committed by the defender (0 if not initialized)
+	// Latest `Bisected` event (nil if not initialized)
+	LastBisected *bindings.IAsymChallengeBisected
+	// Latest `SegmentChallenged` event, if more recent than `LastBisected` (i.e. the defender is to bisect it)
+	LastChallenged *bindings.IAsymChallengeSegmentChallenged
+	Completed      bool
+	Winner         common.Address // Set if completed
+}
+
+// Gets the progress of an asymmetric challenge from its L1 events in blocks [start, end].
+func GetAsymChallengeProgress(
+	ctx context.Context,
+	chalClient client.AsymChallengeClient,
+	start, end uint64,
+) (*AsymChallengeProgress, error) {
+	opts := &bind.FilterOpts{Start: start, End: &end, Context: ctx}
+	completedIter, err := chalClient.FilterChallengeCompleted(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to filter challenge completion, err: %w", err)
+	}
+	defer completedIter.Close()
+	if completedIter.Next() {
+		return &AsymChallengeProgress{Completed: true, Winner: completedIter.Event.Winner}, nil
+	}
+	if completedIter.Error() != nil {
+		return nil, fmt.Errorf("Failed to iterate challenge completion, err: %w", completedIter.Error())
+	}
+	bisectedIter, err := chalClient.FilterBisected(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to filter bisections, err: %w", err)
+	}
+	defer bisectedIter.Close()
+	progress := &AsymChallengeProgress{}
+	for bisectedIter.Next() {
+		if progress.LastBisected == nil {
+			// The first event is emitted by `initializeChallengeLength`
+			if progress.NumSteps, err = ChallengeLength(bisectedIter.Event.ChallengedSegmentLength); err != nil {
+				return nil, err
+			}
+		}
+		progress.LastBisected = bisectedIter.Event
+	}
+	if bisectedIter.Error() != nil {
+		return nil, fmt.Errorf("Failed to iterate bisections, err: %w", bisectedIter.Error())
+	}
+	challengedIter, err := chalClient.FilterSegmentChallenged(opts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to filter challenged segments, err: %w", err)
+	}
+	defer challengedIter.Close()
+	for challengedIter.Next() {
+		progress.LastChallenged = challengedIter.Event
+	}
+	if challengedIter.Error() != nil {
+		return nil, fmt.Errorf("Failed to iterate challenged segments, err: %w", challengedIter.Error())
+	}
+	// Moves alternate, so the latest one is the most recent log
+	if progress.LastChallenged != nil && progress.LastBisected != nil &&
+		!isLaterLog(&progress.LastChallenged.Raw, &progress.LastBisected.Raw) {
+		progress.LastChallenged = nil
+	}
+	return progress, nil
+}
+
+func isLaterLog(a, b *types.Log) bool {
+	if a.BlockNumber != b.BlockNumber {
+		return a.BlockNumber > b.BlockNumber
+	}
+	return a.Index > b.Index
+}
+
+// ChallengeTrace is the execution trace of a challenged assertion. Only its checkpointed index
+// (see `proof.StateIndex`) is kept, and persisted in the node's database so that a challenge can be
+// resumed after a restart without re-executing the whole assertion: states are looked up by
+// re-executing from the closest checkpoint.
+type ChallengeTrace struct {
+	*proof.StateIndex
+}
+
+// Loads the execution trace of the given challenge from its index in the database.
+// If it is not stored yet, the disputed blocks are re-executed to index the trace, and the index is stored.
+func OpenChallengeTrace(
+	ctx context.Context,
+	db ethdb.Database,
+	proofBackend proof.Backend,
+	info *rollupTypes.ChallengeInfo,
+) (*ChallengeTrace, error) {
+	endNum := info.EndBlock + 1
+	if stored := rollupRawdb.ReadChallengeTraceIndex(db, info.Address); stored != nil {
+		index, err := proof.RestoreStateIndex(proofBackend, endNum, nil, stored)
+		if err == nil {
+			log.Info("Loaded challenge trace", "challenge", info.Address, "states", index.Len())
+			return &ChallengeTrace{index}, nil
+		}
+		log.Warn("Invalid stored challenge trace, regenerating", "challenge", info.Address, "err", err)
+	}
+	log.Info("Generating challenge trace", "challenge", info.Address, "start", info.StartBlock, "end", info.EndBlock)
+	index, err := proof.NewStateIndex(proofBackend, ctx, info.StartBlock, endNum, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate challenge trace, err: %w", err)
+	}
+	rollupRawdb.WriteChallengeTraceIndex(db, info.Address, index.TraceIndex())
+	log.Info("Stored challenge trace", "challenge", info.Address, "states", index.Len())
+	return &ChallengeTrace{index}, nil
+}
@@ -0,0 +1,169 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/services"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// Delay before retrying a failed session
+var retryDelay = 2 * time.Second
+
+// ErrChallengeInProgress is returned when starting a challenge that already has a session.
+var ErrChallengeInProgress = errors.New("challenge already in progress")
+
+// Result is the outcome of a challenge session.
+type Result struct {
+	Info   *rollupTypes.ChallengeInfo
+	Winner common.Address
+	// Set if the challenge cannot be played (the winner is then unknown, and zero).
+	Err error
+}
+
+// ChallengeManager runs concurrent challenge sessions, one per challenge contract.
+type ChallengeManager struct {
+	base     *services.BaseService
+	strategy Strategy
+	results  chan *Result
+	// Plays a session until the challenge is completed, `(*Session).run` (replaced in tests)
+	play func(s *Session, ctx context.Context) (common.Address, error)
+
+	mu       sync.Mutex
+	sessions map[common.Address]*Session
+}
+
+func NewChallengeManager(base *services.BaseService, strategy Strategy) *ChallengeManager {
+	return &ChallengeManager{
+		base:     base,
+		strategy: strategy,
+		results:  make(chan *Result, 16),
+		play:     (*Session).run,
+		sessions: make(map[common.Address]*Session),
+	}
+}
+
+// Results receives the result of each challenge started, once completed or found impossible to play.
+func (m *ChallengeManager) Results() <-chan *Result {
+	return m.results
+}
+
+// Starts a session playing the challenge of `info`, whose result is sent to `Results` once completed.
+// A session failing on an error (e.g. an L1 request) is restarted after `retryDelay`: sessions catch up
+// with the challenge events emitted so far, so no move is lost.
+// Returns `ErrChallengeInProgress` if the challenge is already played, whose session reports the result.
+func (m *ChallengeManager) StartChallenge(ctx context.Context, info *rollupTypes.ChallengeInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[info.Address]; ok {
+		return fmt.Errorf("Failed to start challenge %s, err: %w", info.Address, ErrChallengeInProgress)
+	}
+	m.sessions[info.Address] = m.newSession(info, nil)
+	m.base.Wg.Add(1)
+	go func() {
+		defer m.base.Wg.Done()
+		winner, err := m.runSession(ctx, info)
+		m.mu.Lock()
+		delete(m.sessions, info.Address)
+		m.mu.Unlock()
+		if ctx.Err() != nil {
+			// Aborted, the challenge is resumed on restart.
+			return
+		}
+		select {
+		case m.results <- &Result{Info: info, Winner: winner, Err: err}:
+		case <-ctx.Done():
+		}
+	}()
+	return nil
+}
+
+func (m *ChallengeManager) newSession(info *rollupTypes.ChallengeInfo, chalClient client.ChallengeClient) *Session {
+	return &Session{
+		Info:            info,
+		Client:          chalClient,
+		L1Client:        m.base.L1Client,
+		ProofBackend:    m.base.ProofBackend,
+		InclusionProver: m.base,
+		base:            m.base,
+		strategy:        m.strategy,
+		txDoneCh:        make(chan error, 1),
+	}
+}
+
+// Runs the session of the challenge at `info.Address` until it is completed, restarting it on failure.
+// Returns the winner, or an error if the challenge cannot be played. Returns the zero address if aborted.
+func (m *ChallengeManager) runSession(ctx context.Context, info *rollupTypes.ChallengeInfo) (common.Address, error) {
+	for {
+		m.mu.Lock()
+		session := m.sessions[info.Address]
+		m.mu.Unlock()
+		winner, err := m.playSession(ctx, session)
+		if err == nil {
+			return winner, nil
+		}
+		if errors.Is(err, services.ErrInvalidChallengeLength) {
+			// Cannot be played whatever the retries, the opponent will win by timeout.
+			log.Error("Cannot play challenge", "address", info.Address, "err", err)
+			return common.Address{}, err
+		}
+		log.Warn("Challenge session failed, retrying", "address", info.Address, "err", err)
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+			return common.Address{}, nil
+		}
+		// Restart with a fresh session, whose state is restored from L1.
+		m.mu.Lock()
+		m.sessions[info.Address] = m.newSession(info, session.Client)
+		m.mu.Unlock()
+	}
+}
+
+// Plays `session`, accessing its challenge contract first if not done yet.
+func (m *ChallengeManager) playSession(ctx context.Context, session *Session) (common.Address, error) {
+	if session.Client == nil {
+		chalClient, err := m.base.L1Client.NewChallengeClient(ctx, session.Info.Address)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("Failed to access challenge (address=%s), err: %w", session.Info.Address, err)
+		}
+		session.Client = chalClient
+	}
+	return m.play(session, ctx)
+}
+
+// Starts sessions for the challenges to resume after a restart (see `BaseService.ChallengesToResume`).
+// Returns the resumed challenges.
+func (m *ChallengeManager) ResumeChallenges(ctx context.Context) ([]*rollupTypes.ChallengeInfo, error) {
+	infos, err := m.base.ChallengesToResume(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find challenges to resume, err: %w", err)
+	}
+	for _, info := range infos {
+		log.Info("Resuming challenge", "address", info.Address)
+		if err := m.StartChallenge(ctx, info); err != nil {
+			return nil, err
+		}
+	}
+	return infos, nil
+}
+
+// Gets the challenges in progress, in order of creation.
+func (m *ChallengeManager) Challenges() []*rollupTypes.ChallengeInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	infos := make([]*rollupTypes.ChallengeInfo, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		infos = append(infos, session.Info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].L1BlockNumber < infos[j].L1BlockNumber })
+	return infos
+}
@@ -0,0 +1,156 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/services"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+var (
+	testChallengeAddr = common.HexToAddress("0xc0")
+	testWinner        = common.HexToAddress("0x1")
+	errTestL1         = errors.New("L1 unavailable")
+)
+
+type testChallengeClient struct {
+	client.ChallengeClient
+}
+
+// L1 client failing to access challenges `numFailures` times.
+type testL1Client struct {
+	client.L1BridgeClient
+	mu          sync.Mutex
+	numFailures int
+}
+
+func (c *testL1Client) NewChallengeClient(ctx context.Context, challengeAddress common.Address) (client.ChallengeClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.numFailures > 0 {
+		c.numFailures--
+		return nil, errTestL1
+	}
+	return &testChallengeClient{}, nil
+}
+
+// Returns a manager playing sessions with `play`, retrying without delay.
+func newTestManager(t *testing.T, l1Client *testL1Client, play func(s *Session, ctx context.Context) (common.Address, error)) *ChallengeManager {
+	delay := retryDelay
+	retryDelay = time.Millisecond
+	t.Cleanup(func() { retryDelay = delay })
+	m := NewChallengeManager(&services.BaseService{L1Client: l1Client}, BisectionStrategy{})
+	m.play = play
+	return m
+}
+
+func waitResult(t *testing.T, m *ChallengeManager) *Result {
+	t.Helper()
+	select {
+	case result := <-m.Results():
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatal("no challenge result")
+		return nil
+	}
+}
+
+// Failed sessions are restarted with a fresh session, until the challenge is completed.
+func TestManagerRetriesFailedSession(t *testing.T) {
+	var sessions []*Session
+	m := newTestManager(t, &testL1Client{numFailures: 1}, func(s *Session, ctx context.Context) (common.Address, error) {
+		sessions = append(sessions, s)
+		if len(sessions) < 3 {
+			return common.Address{}, errTestL1
+		}
+		return testWinner, nil
+	})
+	info := &rollupTypes.ChallengeInfo{Address: testChallengeAddr}
+	if err := m.StartChallenge(context.Background(), info); err != nil {
+		t.Fatalf("failed to start challenge: %v", err)
+	}
+	result := waitResult(t, m)
+	if result.Err != nil || result.Winner != testWinner || result.Info != info {
+		t.Fatalf("have result %+v, want winner %s", result, testWinner)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("have %d sessions played, want 3", len(sessions))
+	}
+	if sessions[0] == sessions[1] || sessions[1] == sessions[2] {
+		t.Fatal("failed session not replaced")
+	}
+	for i, s := range sessions {
+		if s.Client == nil || s.Client != sessions[0].Client {
+			t.Fatalf("session %d does not reuse the challenge client", i)
+		}
+	}
+	if len(m.Challenges()) != 0 {
+		t.Fatal("completed challenge still in progress")
+	}
+}
+
+// A challenge that cannot be played is reported, so that its players do not wait for it forever.
+func TestManagerReportsUnplayableChallenge(t *testing.T) {
+	var numPlayed int
+	m := newTestManager(t, &testL1Client{}, func(s *Session, ctx context.Context) (common.Address, error) {
+		numPlayed++
+		return common.Address{}, fmt.Errorf("Failed to play, err: %w", services.ErrInvalidChallengeLength)
+	})
+	if err := m.StartChallenge(context.Background(), &rollupTypes.ChallengeInfo{Address: testChallengeAddr}); err != nil {
+		t.Fatalf("failed to start challenge: %v", err)
+	}
+	result := waitResult(t, m)
+	if !errors.Is(result.Err, services.ErrInvalidChallengeLength) || result.Winner != (common.Address{}) {
+		t.Fatalf("have result %+v, want error %v", result, services.ErrInvalidChallengeLength)
+	}
+	if numPlayed != 1 {
+		t.Fatalf("unplayable challenge played %d times", numPlayed)
+	}
+}
+
+func TestManagerStartChallengeInProgress(t *testing.T) {
+	release := make(chan struct{})
+	m := newTestManager(t, &testL1Client{}, func(s *Session, ctx context.Context) (common.Address, error) {
+		<-release
+		return testWinner, nil
+	})
+	info := &rollupTypes.ChallengeInfo{Address: testChallengeAddr}
+	if err := m.StartChallenge(context.Background(), info); err != nil {
+		t.Fatalf("failed to start challenge: %v", err)
+	}
+	if err := m.StartChallenge(context.Background(), info); !errors.Is(err, ErrChallengeInProgress) {
+		t.Fatalf("have error %v, want %v", err, ErrChallengeInProgress)
+	}
+	if infos := m.Challenges(); len(infos) != 1 || infos[0] != info {
+		t.Fatalf("have challenges %v in progress, want 1", infos)
+	}
+	close(release)
+	if result := waitResult(t, m); result.Winner != testWinner {
+		t.Fatalf("have winner %s, want %s", result.Winner, testWinner)
+	}
+}
+
+// Aborted sessions report no result, they are resumed on restart.
+func TestManagerAbort(t *testing.T) {
+	m := newTestManager(t, &testL1Client{}, func(s *Session, ctx context.Context) (common.Address, error) {
+		return common.Address{}, errTestL1
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := m.StartChallenge(ctx, &rollupTypes.ChallengeInfo{Address: testChallengeAddr}); err != nil {
+		t.Fatalf("failed to start challenge: %v", err)
+	}
+	cancel()
+	m.base.Wg.Wait()
+	select {
+	case result := <-m.Results():
+		t.Fatalf("have result %+v of aborted challenge", result)
+	default:
+	}
+}
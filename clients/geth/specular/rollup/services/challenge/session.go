@@ -0,0 +1,341 @@
+package challenge
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/specularl2/specular/clients/geth/specular/bindings"
+	"github.com/specularl2/specular/clients/geth/specular/proof"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/services"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// Session plays a single challenge, with its own client of the challenge contract.
+// The protocol played (symmetric or asymmetric) depends on the type of `Client`.
+type Session struct {
+	Info            *rollupTypes.ChallengeInfo
+	Client          client.ChallengeClient
+	L1Client        client.L1BridgeClient
+	ProofBackend    proof.Backend
+	InclusionProver services.TxInclusionProver
+
+	base     *services.BaseService
+	strategy Strategy
+	trace    *services.ChallengeTrace
+	// Challenge length committed by the defender, which may differ from the length of our trace.
+	// 0 if not initialized yet.
+	numSteps             uint64
+	opponentTimeoutBlock uint64
+
+	// Our L1 tx being sent in the background, if any (see `sendInBackground`)
+	txPending bool
+	// Move deferred until the pending tx is done
+	deferredMove func() error
+	// Receives the error of the pending tx once done. Buffered, so the sender never blocks.
+	txDoneCh chan error
+}
+
+// Trace returns our execution trace, adapted to the committed challenge length (see `proof.PadTrace`).
+func (s *Session) Trace() proof.ExecutionTrace {
+	if s.numSteps == 0 {
+		return s.trace
+	}
+	return proof.PadTrace(s.trace, s.numSteps)
+}
+
+// Plays the challenge until it is completed, catching up with the challenge events emitted so far
+// (e.g. while we were offline). Returns the winner, or the zero address if aborted.
+func (s *Session) run(ctx context.Context) (common.Address, error) {
+	var err error
+	s.base.SaveChallenge(s.Info)
+	log.Info("to generate state from", "start", s.Info.StartBlock, "to", s.Info.EndBlock)
+	s.trace, err = services.OpenChallengeTrace(ctx, s.base.Eth.ChainDb(), s.ProofBackend, s.Info)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("Failed to generate states, err: %w", err)
+	}
+	head := s.base.L1Syncer.Latest.Number.Uint64()
+	log.Info("Playing challenge", "address", s.Info.Address, "type", s.Client.Type(), "defender", s.Info.IsDefender())
+	switch chalClient := s.Client.(type) {
+	case client.SymChallengeClient:
+		return s.runSym(ctx, chalClient, head)
+	case client.AsymChallengeClient:
+		return s.runAsym(ctx, chalClient, head)
+	default:
+		return common.Address{}, fmt.Errorf("Unsupported challenge type %s", s.Client.Type())
+	}
+}
+
+// Plays a symmetric challenge from L1 block `head`.
+func (s *Session) runSym(ctx context.Context, chalClient client.SymChallengeClient, head uint64) (common.Address, error) {
+	progress, err := services.GetChallengeProgress(ctx, chalClient, s.Info.L1BlockNumber, head)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("Failed to get challenge progress, err: %w", err)
+	}
+	if progress.Completed {
+		return s.complete(progress.Winner), nil
+	}
+	s.numSteps = progress.NumSteps
+	if progress.LastBisected == nil {
+		if err := s.initialize(ctx); err != nil {
+			return common.Address{}, err
+		}
+	}
+
+	subCtx, subCancel := context.WithCancel(ctx)
+	defer subCancel()
+	bisectedCh := client.SubscribeHeaderMapped[*bindings.ISymChallengeBisected](
+		subCtx, s.base.L1Syncer.LatestHeaderBroker, chalClient.FilterBisected, head+1,
+	)
+	challengeCompletedCh := client.SubscribeHeaderMapped[*bindings.ISymChallengeCompleted](
+		subCtx, s.base.L1Syncer.LatestHeaderBroker, chalClient.FilterChallengeCompleted, head+1,
+	)
+	headCh := s.base.L1Syncer.LatestHeaderBroker.Subscribe()
+	defer s.base.L1Syncer.LatestHeaderBroker.Unsubscribe(headCh)
+	// case get bisection, if is our turn
+	//   if in single step, submit proof
+	//   if multiple step, track current segment, update
+	handleBisected := func(ev *bindings.ISymChallengeBisected) error {
+		if err := s.onBisected(ev.ChallengedSegmentLength); err != nil {
+			return err
+		}
+		return s.onMove(ev.Raw.BlockNumber, func() error {
+			return s.strategy.RespondBisection(ctx, s, chalClient, ev)
+		})
+	}
+	if progress.LastBisected != nil {
+		log.Info(
+			"Resuming from bisection",
+			"address", s.Info.Address,
+			"segStart", progress.LastBisected.ChallengedSegmentStart,
+			"segLen", progress.LastBisected.ChallengedSegmentLength,
+		)
+		if err := handleBisected(progress.LastBisected); err != nil {
+			return common.Address{}, err
+		}
+	}
+	for {
+		select {
+		case ev := <-bisectedCh:
+			if err := handleBisected(ev); err != nil {
+				return common.Address{}, err
+			}
+		case header := <-headCh:
+			s.handleHeader(header)
+		case err := <-s.txDoneCh:
+			if err := s.onTxDone(err); err != nil {
+				return common.Address{}, err
+			}
+		case ev := <-challengeCompletedCh:
+			return s.complete(ev.Winner), nil
+		case <-ctx.Done():
+			log.Info("Aborting.")
+			return common.Address{}, nil
+		}
+	}
+}
+
+// Plays an asymmetric challenge from L1 block `head`.
+// The defender bisects the segments selected by the challenger, who proves the last single step.
+func (s *Session) runAsym(ctx context.Context, chalClient client.AsymChallengeClient, head uint64) (common.Address, error) {
+	progress, err := services.GetAsymChallengeProgress(ctx, chalClient, s.Info.L1BlockNumber, head)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("Failed to get challenge progress, err: %w", err)
+	}
+	if progress.Completed {
+		return s.complete(progress.Winner), nil
+	}
+	s.numSteps = progress.NumSteps
+	if progress.LastBisected == nil {
+		if err := s.initialize(ctx); err != nil {
+			return common.Address{}, err
+		}
+	}
+
+	subCtx, subCancel := context.WithCancel(ctx)
+	defer subCancel()
+	bisectedCh := client.SubscribeHeaderMapped[*bindings.IAsymChallengeBisected](
+		subCtx, s.base.L1Syncer.LatestHeaderBroker, chalClient.FilterBisected, head+1,
+	)
+	segmentChallengedCh := client.SubscribeHeaderMapped[*bindings.IAsymChallengeSegmentChallenged](
+		subCtx, s.base.L1Syncer.LatestHeaderBroker, chalClient.FilterSegmentChallenged, head+1,
+	)
+	challengeCompletedCh := client.SubscribeHeaderMapped[*bindings.IAsymChallengeCompleted](
+		subCtx, s.base.L1Syncer.LatestHeaderBroker, chalClient.FilterChallengeCompleted, head+1,
+	)
+	headCh := s.base.L1Syncer.LatestHeaderBroker.Subscribe()
+	defer s.base.L1Syncer.LatestHeaderBroker.Unsubscribe(headCh)
+	// The challenger selects a segment of each bisection, or proves it if it is a single step
+	handleBisected := func(ev *bindings.IAsymChallengeBisected) error {
+		if err := s.onBisected(ev.ChallengedSegmentLength); err != nil {
+			return err
+		}
+		return s.onMove(ev.Raw.BlockNumber, func() error {
+			return s.strategy.RespondAsymBisection(ctx, s, chalClient, ev)
+		})
+	}
+	// The defender bisects each selected segment
+	handleSegmentChallenged := func(ev *bindings.IAsymChallengeSegmentChallenged) error {
+		return s.onMove(ev.Raw.BlockNumber, func() error {
+			return s.strategy.BisectSegment(ctx, s, chalClient, ev)
+		})
+	}
+	if progress.LastChallenged != nil {
+		log.Info(
+			"Resuming from challenged segment",
+			"address", s.Info.Address,
+			"segStart", progress.LastChallenged.ChallengedSegmentStart,
+			"segLen", progress.LastChallenged.ChallengedSegmentLength,
+		)
+		if err := handleSegmentChallenged(progress.LastChallenged); err != nil {
+			return common.Address{}, err
+		}
+	} else if progress.LastBisected != nil {
+		log.Info(
+			"Resuming from bisection",
+			"address", s.Info.Address,
+			"segStart", progress.LastBisected.ChallengedSegmentStart,
+			"segLen", progress.LastBisected.ChallengedSegmentLength,
+		)
+		if err := handleBisected(progress.LastBisected); err != nil {
+			return common.Address{}, err
+		}
+	}
+	for {
+		select {
+		case ev := <-bisectedCh:
+			if err := handleBisected(ev); err != nil {
+				return common.Address{}, err
+			}
+		case ev := <-segmentChallengedCh:
+			if err := handleSegmentChallenged(ev); err != nil {
+				return common.Address{}, err
+			}
+		case header := <-headCh:
+			s.handleHeader(header)
+		case err := <-s.txDoneCh:
+			if err := s.onTxDone(err); err != nil {
+				return common.Address{}, err
+			}
+		case ev := <-challengeCompletedCh:
+			return s.complete(ev.Winner), nil
+		case <-ctx.Done():
+			log.Info("Aborting.")
+			return common.Address{}, nil
+		}
+	}
+}
+
+// Initializes the challenge length, if we are the defender.
+func (s *Session) initialize(ctx context.Context) error {
+	if !s.Info.IsDefender() {
+		return nil
+	}
+	numSteps, err := s.strategy.ChallengeLength(ctx, s)
+	if err != nil {
+		return fmt.Errorf("Failed to get challenge length, err: %w", err)
+	}
+	_, err = s.Client.InitializeChallengeLength(new(big.Int).SetUint64(numSteps))
+	if err != nil {
+		return fmt.Errorf("Failed to initialize challenge, err: %w", err)
+	}
+	return nil
+}
+
+// Records the challenge length from the first bisection.
+// Returns `services.ErrInvalidChallengeLength` if the committed length cannot be played.
+func (s *Session) onBisected(segLen *big.Int) error {
+	if s.numSteps != 0 {
+		return nil
+	}
+	// The first bisection is emitted when the defender initializes the challenge length
+	numSteps, err := services.ChallengeLength(segLen)
+	if err != nil {
+		return err
+	}
+	s.numSteps = numSteps
+	if s.numSteps != s.trace.Len()-1 {
+		log.Warn("Challenge length differs from local trace", "defender", s.numSteps, "local", s.trace.Len()-1)
+	}
+	return nil
+}
+
+// Calls `respond` if it is our turn after the opponent's move in L1 block `moveBlock`,
+// or tracks the deadline of the opponent otherwise.
+func (s *Session) onMove(moveBlock uint64, respond func() error) error {
+	responder, err := s.Client.CurrentChallengeResponder()
+	if err != nil {
+		return fmt.Errorf("Failed to get current responder, err: %w", err)
+	}
+	if responder == common.Address(s.base.Config.Coinbase) {
+		// If it's our turn
+		s.sendInBackground(respond, true)
+		return nil
+	}
+	opponentTimeLeft, err := s.Client.CurrentChallengeResponderTimeLeft()
+	if err != nil {
+		return fmt.Errorf("Failed to get current responder time left, err: %w", err)
+	}
+	log.Info("Opponent time left", "time", opponentTimeLeft)
+	s.opponentTimeoutBlock = moveBlock + opponentTimeLeft.Uint64()
+	return nil
+}
+
+func (s *Session) handleHeader(header *types.Header) {
+	if s.opponentTimeoutBlock == 0 {
+		return
+	}
+	// TODO: can we use >= here?
+	if header.Number.Uint64() > s.opponentTimeoutBlock {
+		s.sendInBackground(func() error {
+			_, err := s.Client.TimeoutChallenge()
+			if err != nil {
+				return fmt.Errorf("Cannot timeout opponent, err: %w", err)
+			}
+			return nil
+		}, false)
+	}
+}
+
+// Sends one of our L1 txs with `send`, which blocks until the tx is final, in the background,
+// so that the session keeps handling events and headers meanwhile.
+// If a tx is already pending, a move is deferred until it is done, while a timeout is
+// dropped (it is retried on the next header).
+func (s *Session) sendInBackground(send func() error, isMove bool) {
+	if s.txPending {
+		if isMove {
+			s.deferredMove = send
+		}
+		return
+	}
+	s.txPending = true
+	go func() { s.txDoneCh <- send() }()
+}
+
+// Handles the completion of our pending L1 tx, and sends the deferred move if any.
+// Returns the error of a failed tx, on which the session must be restarted: the move is then
+// recomputed from the challenge state on L1 (which may have changed, e.g. if the opponent timed out).
+func (s *Session) onTxDone(err error) error {
+	s.txPending = false
+	if err != nil {
+		s.deferredMove = nil
+		return fmt.Errorf("Failed to respond to challenge move, err: %w", err)
+	}
+	if move := s.deferredMove; move != nil {
+		s.deferredMove = nil
+		s.sendInBackground(move, true)
+	}
+	return nil
+}
+
+// Cleans up after the challenge is completed and returns its winner.
+func (s *Session) complete(winner common.Address) common.Address {
+	// TODO: handle if we are not winner --> state corrupted
+	log.Info("Challenge completed", "address", s.Info.Address, "winner", winner)
+	s.base.DeleteChallenge(s.Info.Address)
+	return winner
+}
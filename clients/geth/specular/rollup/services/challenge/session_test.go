@@ -0,0 +1,76 @@
+package challenge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+)
+
+func newTestSession() *Session {
+	return &Session{Info: &rollupTypes.ChallengeInfo{Address: testChallengeAddr}, txDoneCh: make(chan error, 1)}
+}
+
+func waitTxDone(t *testing.T, s *Session) error {
+	t.Helper()
+	select {
+	case err := <-s.txDoneCh:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending tx not done")
+		return nil
+	}
+}
+
+// Moves sent while a tx is pending are deferred until it is done, timeouts are dropped.
+func TestSessionDefersMoves(t *testing.T) {
+	s := newTestSession()
+	release := make(chan struct{})
+	var sent []string
+	send := func(name string) func() error {
+		return func() error {
+			sent = append(sent, name)
+			return nil
+		}
+	}
+	s.sendInBackground(func() error {
+		<-release
+		return send("first")()
+	}, true)
+	s.sendInBackground(send("timeout"), false)
+	s.sendInBackground(send("deferred"), true)
+	close(release)
+
+	if err := s.onTxDone(waitTxDone(t, s)); err != nil {
+		t.Fatalf("failed tx: %v", err)
+	}
+	if !s.txPending || s.deferredMove != nil {
+		t.Fatal("deferred move not sent")
+	}
+	if err := s.onTxDone(waitTxDone(t, s)); err != nil {
+		t.Fatalf("failed tx: %v", err)
+	}
+	if s.txPending {
+		t.Fatal("no tx should be pending")
+	}
+	if len(sent) != 2 || sent[0] != "first" || sent[1] != "deferred" {
+		t.Fatalf("have txs %v sent, want [first deferred]", sent)
+	}
+}
+
+// A failed move ends the session (which is then restarted), instead of being dropped.
+func TestSessionFailedMove(t *testing.T) {
+	s := newTestSession()
+	s.sendInBackground(func() error { return errTestL1 }, true)
+	s.sendInBackground(func() error {
+		t.Error("move deferred after a failed move sent")
+		return nil
+	}, true)
+	if err := s.onTxDone(waitTxDone(t, s)); !errors.Is(err, errTestL1) {
+		t.Fatalf("have error %v, want %v", err, errTestL1)
+	}
+	if s.txPending || s.deferredMove != nil {
+		t.Fatal("move still pending after a failed move")
+	}
+}
@@ -0,0 +1,96 @@
+package challenge
+
+import (
+	"context"
+
+	"github.com/specularl2/specular/clients/geth/specular/bindings"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/services"
+)
+
+// Strategy decides the moves of the node in its challenge sessions.
+type Strategy interface {
+	// Returns the number of steps to initialize the challenge with, when we are the defender.
+	ChallengeLength(ctx context.Context, s *Session) (uint64, error)
+	// Responds to the bisection `ev` of a symmetric challenge, when it is our turn.
+	RespondBisection(
+		ctx context.Context,
+		s *Session,
+		chalClient client.SymChallengeClient,
+		ev *bindings.ISymChallengeBisected,
+	) error
+	// Bisects the segment selected by the challenger of an asymmetric challenge, when we are the defender.
+	BisectSegment(
+		ctx context.Context,
+		s *Session,
+		chalClient client.AsymChallengeClient,
+		ev *bindings.IAsymChallengeSegmentChallenged,
+	) error
+	// Responds to the defender's bisection `ev` of an asymmetric challenge, when we are the challenger.
+	RespondAsymBisection(
+		ctx context.Context,
+		s *Session,
+		chalClient client.AsymChallengeClient,
+		ev *bindings.IAsymChallengeBisected,
+	) error
+}
+
+// BisectionStrategy is the honest strategy: it commits to the length of the local execution trace,
+// and bisects and proves the local trace (adapted to the committed challenge length).
+type BisectionStrategy struct{}
+
+func (BisectionStrategy) ChallengeLength(ctx context.Context, s *Session) (uint64, error) {
+	return s.trace.Len() - 1, nil
+}
+
+func (BisectionStrategy) RespondBisection(
+	ctx context.Context,
+	s *Session,
+	chalClient client.SymChallengeClient,
+	ev *bindings.ISymChallengeBisected,
+) error {
+	return services.RespondBisection(
+		ctx,
+		s.ProofBackend,
+		s.L1Client,
+		chalClient,
+		s.InclusionProver,
+		ev,
+		s.Trace(),
+		s.Info.OpponentVmHash,
+		s.Info.IsDefender(),
+	)
+}
+
+func (BisectionStrategy) BisectSegment(
+	ctx context.Context,
+	s *Session,
+	chalClient client.AsymChallengeClient,
+	ev *bindings.IAsymChallengeSegmentChallenged,
+) error {
+	return services.BisectAsymSegment(
+		ctx,
+		chalClient,
+		s.Trace(),
+		ev.ChallengedSegmentStart.Uint64(),
+		ev.ChallengedSegmentLength.Uint64(),
+	)
+}
+
+func (BisectionStrategy) RespondAsymBisection(
+	ctx context.Context,
+	s *Session,
+	chalClient client.AsymChallengeClient,
+	ev *bindings.IAsymChallengeBisected,
+) error {
+	return services.RespondAsymBisection(
+		ctx,
+		s.ProofBackend,
+		s.L1Client,
+		chalClient,
+		s.InclusionProver,
+		ev,
+		s.Trace(),
+		s.Info.OpponentVmHash,
+	)
+}
@@ -0,0 +1,427 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/specularl2/specular/clients/geth/specular/bindings"
+	"github.com/specularl2/specular/clients/geth/specular/proof"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
+	rollupRawdb "github.com/specularl2/specular/clients/geth/specular/rollup/rawdb"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+)
+
+// Proof backend over a test chain, counting the re-executions of the chain.
+type testProofBackend struct {
+	*testBackend
+	numReplays int
+}
+
+func (b *testProofBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.chain.GetHeaderByHash(hash), nil
+}
+
+func (b *testProofBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+
+func (b *testProofBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.chain.GetBlockByHash(hash), nil
+}
+
+func (b *testProofBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	return b.chain.GetBlockByNumber(uint64(number)), nil
+}
+
+func (b *testProofBackend) GetTransaction(ctx context.Context, hash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	return nil, common.Hash{}, 0, 0, errors.New("not supported")
+}
+
+func (b *testProofBackend) RPCGasCap() uint64                { return 50_000_000 }
+func (b *testProofBackend) ChainConfig() *params.ChainConfig { return b.chain.Config() }
+func (b *testProofBackend) Engine() consensus.Engine         { return b.chain.Engine() }
+func (b *testProofBackend) ChainDb() ethdb.Database          { return b.db }
+
+func (b *testProofBackend) StateAtBlock(ctx context.Context, block *types.Block, reexec uint64, base *state.StateDB, checkLive, preferDisk bool) (*state.StateDB, error) {
+	if base == nil {
+		b.numReplays++
+	}
+	return b.chain.StateAt(block.Root())
+}
+
+func (b *testProofBackend) StateAtTransaction(ctx context.Context, block *types.Block, txIndex int, reexec uint64) (core.Message, vm.BlockContext, *state.StateDB, error) {
+	return nil, vm.BlockContext{}, nil, errors.New("not supported")
+}
+
+// Returns a proof backend over `numBlocks` blocks of test txs.
+func newTestProofBackend(t *testing.T, numBlocks int) *testProofBackend {
+	config := testChainConfig(0)
+	backend := newTestBackend(t, config)
+	engine := NewLocalEngine(backend)
+	var nonce uint64
+	for i := 1; i <= numBlocks; i++ {
+		txs := testTxs(t, config, big.NewInt(int64(i)), nonce)
+		nonce += uint64(len(txs))
+		if _, err := engine.BuildPayload(&PayloadAttributes{Timestamp: uint64(10 * i), FeeRecipient: testSequencer, Txs: txs}); err != nil {
+			t.Fatalf("failed to build block #%d: %v", i, err)
+		}
+	}
+	return &testProofBackend{testBackend: backend}
+}
+
+func checkChallengeTrace(t *testing.T, trace *ChallengeTrace, want []*proof.ExecutionState) {
+	t.Helper()
+	if trace.Len() != uint64(len(want)) {
+		t.Fatalf("have %d states, want %d", trace.Len(), len(want))
+	}
+	for i, w := range want {
+		s, err := trace.State(context.Background(), uint64(i))
+		if err != nil {
+			t.Fatalf("failed to read state %d: %v", i, err)
+		}
+		if s.VMHash != w.VMHash || s.Block.Hash() != w.Block.Hash() || s.TransactionIdx != w.TransactionIdx || s.StepIdx != w.StepIdx {
+			t.Fatalf("state %d: have %+v, want %+v", i, s, w)
+		}
+	}
+	if _, err := trace.State(context.Background(), trace.Len()); err == nil {
+		t.Fatal("read state past the end of the trace")
+	}
+}
+
+func TestChallengeTraceRoundTrip(t *testing.T) {
+	backend := newTestProofBackend(t, 2)
+	ctx := context.Background()
+	info := &rollupTypes.ChallengeInfo{Address: common.HexToAddress("0xc0"), StartBlock: 1, EndBlock: 2}
+	want, err := proof.GenerateStates(backend, ctx, 1, 3, nil)
+	if err != nil {
+		t.Fatalf("failed to generate states: %v", err)
+	}
+
+	backend.numReplays = 0
+	trace, err := OpenChallengeTrace(ctx, backend.db, backend, info)
+	if err != nil {
+		t.Fatalf("failed to open trace: %v", err)
+	}
+	if backend.numReplays != 1 {
+		t.Fatalf("have %d replays generating the trace, want 1", backend.numReplays)
+	}
+	checkChallengeTrace(t, trace, want)
+
+	// Once stored, the trace is loaded without re-execution (states are replayed on lookup only).
+	backend.numReplays = 0
+	trace, err = OpenChallengeTrace(ctx, backend.db, backend, info)
+	if err != nil {
+		t.Fatalf("failed to reopen trace: %v", err)
+	}
+	if backend.numReplays != 0 {
+		t.Fatal("stored trace regenerated")
+	}
+	checkChallengeTrace(t, trace, want)
+}
+
+// An invalid stored index (e.g. written by an incompatible version) must be regenerated.
+func TestChallengeTraceInvalidIndex(t *testing.T) {
+	backend := newTestProofBackend(t, 2)
+	ctx := context.Background()
+	info := &rollupTypes.ChallengeInfo{Address: common.HexToAddress("0xc0"), StartBlock: 1, EndBlock: 2}
+	want, err := proof.GenerateStates(backend, ctx, 1, 3, nil)
+	if err != nil {
+		t.Fatalf("failed to generate states: %v", err)
+	}
+	rollupRawdb.WriteChallengeTraceIndex(backend.db, info.Address, &rollupTypes.TraceIndex{Length: 2, Interval: 1})
+
+	backend.numReplays = 0
+	trace, err := OpenChallengeTrace(ctx, backend.db, backend, info)
+	if err != nil {
+		t.Fatalf("failed to open trace: %v", err)
+	}
+	if backend.numReplays != 1 {
+		t.Fatalf("invalid trace index not regenerated")
+	}
+	checkChallengeTrace(t, trace, want)
+	if stored := rollupRawdb.ReadChallengeTraceIndex(backend.db, info.Address); stored == nil || stored.Length != uint64(len(want)) {
+		t.Fatalf("have stored index %+v, want %d states", stored, len(want))
+	}
+}
+
+func TestChallengeLength(t *testing.T) {
+	maxUint64 := new(big.Int).SetUint64(math.MaxUint64)
+	tests := []struct {
+		numSteps *big.Int
+		valid    bool
+	}{
+		{big.NewInt(-1), false},
+		{common.Big0, false},
+		{common.Big1, true},
+		{new(big.Int).SetUint64(MaxChallengeLength), true},
+		// The number of states would overflow.
+		{maxUint64, false},
+		// Would be truncated to 0.
+		{new(big.Int).Add(maxUint64, common.Big1), false},
+		{new(big.Int).Lsh(common.Big1, 255), false},
+	}
+	for _, tt := range tests {
+		numSteps, err := ChallengeLength(tt.numSteps)
+		if !tt.valid {
+			if !errors.Is(err, ErrInvalidChallengeLength) {
+				t.Errorf("length %v: have error %v, want %v", tt.numSteps, err, ErrInvalidChallengeLength)
+			}
+			continue
+		}
+		if err != nil || numSteps != tt.numSteps.Uint64() {
+			t.Errorf("length %v: have %d, err %v", tt.numSteps, numSteps, err)
+		}
+	}
+}
+
+// Asymmetric challenge client recording our moves.
+type testAsymChallengeClient struct {
+	client.AsymChallengeClient
+	// Bisections of the defender's `bisectExecution` txs
+	bisections map[common.Hash][][32]byte
+
+	bisection     [][32]byte
+	challengedIdx *big.Int
+	prevBisection [][32]byte
+	osp           []byte
+	rawCtx        bindings.VerificationContextLibRawContext
+}
+
+func (c *testAsymChallengeClient) BisectExecution(bisection [][32]byte) (*client.TxResult, error) {
+	c.bisection = bisection
+	return &client.TxResult{}, nil
+}
+
+func (c *testAsymChallengeClient) ChallengeExecution(challengedSegmentIndex *big.Int, prevBisection [][32]byte) (*client.TxResult, error) {
+	c.challengedIdx, c.prevBisection = challengedSegmentIndex, prevBisection
+	return &client.TxResult{}, nil
+}
+
+func (c *testAsymChallengeClient) VerifyOneStepProof(
+	osp []byte,
+	txInclusionProof []byte,
+	verificationRawCtx bindings.VerificationContextLibRawContext,
+	challengedStepIndex *big.Int,
+	prevBisection [][32]byte,
+) (*client.TxResult, error) {
+	c.osp, c.rawCtx = osp, verificationRawCtx
+	c.challengedIdx, c.prevBisection = challengedStepIndex, prevBisection
+	return &client.TxResult{}, nil
+}
+
+func (c *testAsymChallengeClient) DecodeBisectExecutionInput(tx *types.Transaction) ([]interface{}, error) {
+	bisection, ok := c.bisections[tx.Hash()]
+	if !ok {
+		return nil, errors.New("not a bisectExecution call")
+	}
+	return []interface{}{bisection}, nil
+}
+
+// L1 client serving the txs of challenge events.
+type testTxClient struct {
+	client.L1BridgeClient
+	txs map[common.Hash]*types.Transaction
+}
+
+func (c *testTxClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	tx, ok := c.txs[hash]
+	if !ok {
+		return nil, false, errors.New("not found")
+	}
+	return tx, false, nil
+}
+
+type testInclusionProver struct{}
+
+func (testInclusionProver) TxInclusionProof(state *proof.ExecutionState) (*proof.TxInclusionProof, error) {
+	return &proof.TxInclusionProof{BatchNumber: state.Block.NumberU64()}, nil
+}
+
+// Returns the trace of the blocks of `backend`, and the hashes of its states.
+func openTestChallengeTrace(t *testing.T, backend *testProofBackend) (*ChallengeTrace, []common.Hash) {
+	info := &rollupTypes.ChallengeInfo{Address: common.HexToAddress("0xc0"), StartBlock: 1, EndBlock: 2}
+	trace, err := OpenChallengeTrace(context.Background(), backend.db, backend, info)
+	if err != nil {
+		t.Fatalf("failed to open trace: %v", err)
+	}
+	hashes := make([]common.Hash, trace.Len())
+	for i := range hashes {
+		if hashes[i], err = trace.Hash(context.Background(), uint64(i)); err != nil {
+			t.Fatalf("failed to read state %d: %v", i, err)
+		}
+	}
+	return trace, hashes
+}
+
+func TestBisectAsymSegment(t *testing.T) {
+	trace, hashes := openTestChallengeTrace(t, newTestProofBackend(t, 2))
+	numSteps := trace.Len() - 1
+	for _, seg := range [][2]uint64{{0, numSteps}, {1, numSteps - 1}, {0, 2}, {numSteps - 3, 3}} {
+		chalClient := &testAsymChallengeClient{}
+		if err := BisectAsymSegment(context.Background(), chalClient, trace, seg[0], seg[1]); err != nil {
+			t.Fatalf("segment %v: failed to bisect: %v", seg, err)
+		}
+		// The first segment is the longer one, as in `ChallengeLib.firstSegmentLength`.
+		mid := seg[0] + seg[1]/2 + seg[1]%2
+		want := [][32]byte{hashes[seg[0]], hashes[mid], hashes[seg[0]+seg[1]]}
+		if !reflect.DeepEqual(chalClient.bisection, want) {
+			t.Errorf("segment %v: have bisection %x, want %x", seg, chalClient.bisection, want)
+		}
+	}
+	if err := BisectAsymSegment(context.Background(), &testAsymChallengeClient{}, trace, 1, numSteps); err == nil {
+		t.Error("bisected segment past the end of the trace")
+	}
+}
+
+func TestRespondAsymBisection(t *testing.T) {
+	backend := newTestProofBackend(t, 2)
+	trace, hashes := openTestChallengeTrace(t, backend)
+	numSteps := trace.Len() - 1
+	if numSteps < 5 {
+		t.Fatalf("trace of %d steps too short to bisect twice", numSteps)
+	}
+	mid := numSteps/2 + numSteps%2
+	subMid := 1 + (numSteps-1)/2 + (numSteps-1)%2
+	wrongHash := common.HexToHash("0xbad")
+	initTx := types.NewTx(&types.LegacyTx{Nonce: 0})
+	bisectTx := types.NewTx(&types.LegacyTx{Nonce: 1})
+	l1Client := &testTxClient{txs: map[common.Hash]*types.Transaction{initTx.Hash(): initTx, bisectTx.Hash(): bisectTx}}
+	bisected := func(tx *types.Transaction, segStart, segLen uint64) *bindings.IAsymChallengeBisected {
+		return &bindings.IAsymChallengeBisected{
+			ChallengedSegmentStart:  new(big.Int).SetUint64(segStart),
+			ChallengedSegmentLength: new(big.Int).SetUint64(segLen),
+			Raw:                     types.Log{TxHash: tx.Hash()},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		bisection [][32]byte // Defender's bisection, nil for `initializeChallengeLength`
+		segStart  uint64
+		segLen    uint64
+		wantIdx   int64 // Challenged segment, 0 for an error
+		wantPrev  [][32]byte
+	}{
+		{
+			name:     "initial segment",
+			segLen:   numSteps,
+			wantIdx:  1,
+			wantPrev: [][32]byte{hashes[0], wrongHash},
+		},
+		{
+			name:   "challenge length mismatch",
+			segLen: numSteps + 1,
+		},
+		{
+			name:      "wrong mid state",
+			bisection: [][32]byte{hashes[0], wrongHash, wrongHash},
+			segLen:    numSteps,
+			wantIdx:   1,
+		},
+		{
+			name:      "wrong end state",
+			bisection: [][32]byte{hashes[0], hashes[mid], wrongHash},
+			segLen:    numSteps,
+			wantIdx:   2,
+		},
+		{
+			name:      "wrong end state of subsegment",
+			bisection: [][32]byte{hashes[1], hashes[subMid], wrongHash},
+			segStart:  1,
+			segLen:    numSteps - 1,
+			wantIdx:   2,
+		},
+		{
+			name:      "agreed bisection",
+			bisection: [][32]byte{hashes[0], hashes[mid], hashes[numSteps]},
+			segLen:    numSteps,
+		},
+		{
+			name:      "malformed bisection",
+			bisection: [][32]byte{hashes[0], wrongHash},
+			segLen:    numSteps,
+		},
+	}
+	for _, tt := range tests {
+		chalClient := &testAsymChallengeClient{bisections: make(map[common.Hash][][32]byte)}
+		ev := bisected(initTx, tt.segStart, tt.segLen)
+		if tt.bisection != nil {
+			chalClient.bisections[bisectTx.Hash()] = tt.bisection
+			ev = bisected(bisectTx, tt.segStart, tt.segLen)
+		}
+		err := RespondAsymBisection(context.Background(), backend, l1Client, chalClient, testInclusionProver{}, ev, trace, wrongHash)
+		if tt.wantIdx == 0 {
+			if err == nil {
+				t.Errorf("%s: responded to invalid bisection", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: failed to respond: %v", tt.name, err)
+		}
+		if chalClient.osp != nil {
+			t.Fatalf("%s: proved segment of %d steps", tt.name, tt.segLen)
+		}
+		wantPrev := tt.wantPrev
+		if wantPrev == nil {
+			wantPrev = tt.bisection
+		}
+		if chalClient.challengedIdx == nil || chalClient.challengedIdx.Int64() != tt.wantIdx {
+			t.Errorf("%s: have challenged segment %v, want %d", tt.name, chalClient.challengedIdx, tt.wantIdx)
+		}
+		if !reflect.DeepEqual(chalClient.prevBisection, wantPrev) {
+			t.Errorf("%s: have previous bisection %x, want %x", tt.name, chalClient.prevBisection, wantPrev)
+		}
+	}
+}
+
+// A single-step segment is proven instead of challenged.
+func TestRespondAsymBisectionProof(t *testing.T) {
+	backend := newTestProofBackend(t, 2)
+	trace, hashes := openTestChallengeTrace(t, backend)
+	numSteps := trace.Len() - 1
+	// The defender committed to 2 more steps, which leave our end state unchanged.
+	padded := proof.PadTrace(trace, numSteps+2)
+	end := hashes[numSteps]
+	bisection := [][32]byte{end, common.HexToHash("0xbad"), end}
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+	l1Client := &testTxClient{txs: map[common.Hash]*types.Transaction{tx.Hash(): tx}}
+	chalClient := &testAsymChallengeClient{bisections: map[common.Hash][][32]byte{tx.Hash(): bisection}}
+	ev := &bindings.IAsymChallengeBisected{
+		ChallengedSegmentStart:  new(big.Int).SetUint64(numSteps),
+		ChallengedSegmentLength: big.NewInt(2),
+		Raw:                     types.Log{TxHash: tx.Hash()},
+	}
+	err := RespondAsymBisection(context.Background(), backend, l1Client, chalClient, testInclusionProver{}, ev, padded, end)
+	if err != nil {
+		t.Fatalf("failed to respond: %v", err)
+	}
+	if chalClient.osp == nil {
+		t.Fatal("single step not proven")
+	}
+	if !bytes.Equal(chalClient.osp, proof.EmptyProof().Encode()) {
+		t.Errorf("have proof %x of a step past the end state, want an empty proof", chalClient.osp)
+	}
+	if chalClient.challengedIdx.Int64() != 1 || !reflect.DeepEqual(chalClient.prevBisection, bisection) {
+		t.Errorf("proved step %v of bisection %x", chalClient.challengedIdx, chalClient.prevBisection)
+	}
+	// The context is that of the last tx before the end state.
+	last := backend.chain.GetBlockByNumber(2)
+	if chalClient.rawCtx.L2BlockNumber.Uint64() != 2 || chalClient.rawCtx.L2BlockTimestamp.Uint64() != last.Time() {
+		t.Errorf("have context of block #%v, want #2", chalClient.rawCtx.L2BlockNumber)
+	}
+}
@@ -1,7 +1,10 @@
 package services
 
 import (
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
 )
 
 const (
@@ -22,4 +25,11 @@ type Config struct {
 	RollupAddr           common.Address // L1 Rollup contract address
 	L1RollupGenesisBlock uint64         // L1 Rollup genesis block
 	RollupStakeAmount    uint64         // Amount of stake
+	MaxBatchSize         uint64         // Max calldata size of an L1 batch in bytes (0 for no limit)
+	MaxBatchGas          uint64         // Max estimated L1 gas of a batch (0 for no limit)
+	L1Confirmations      uint64         // Number of L1 confirmations to wait for on sent L1 txs
+	L1ResubmitTimeout    time.Duration  // Time to wait for inclusion before re-broadcasting an L1 tx with bumped fees
+
+	// Challenge protocol assumed for L1 challenge contracts not reporting their type
+	ChallengeType client.ChallengeType
 }
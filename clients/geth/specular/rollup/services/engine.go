@@ -0,0 +1,60 @@
+package services
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// PayloadAttributes describes the next L2 block to build, in the manner of the
+// engine API's payload attributes.
+type PayloadAttributes struct {
+	Timestamp    uint64
+	FeeRecipient common.Address
+	// Txs to include in the block, in order.
+	Txs types.Transactions
+}
+
+// Payload is a sealed block built from PayloadAttributes.
+type Payload struct {
+	Block *types.Block
+	// Txs from the attributes that failed to execute, with the reason.
+	Dropped []DroppedTx
+	// Txs from the attributes that did not fit into the block, in order.
+	// These should be carried over to the next payload.
+	Remaining types.Transactions
+}
+
+// ExecutionEngine builds L2 blocks on top of the current chain head and
+// inserts them into the chain. It is the only way the sequencer produces
+// blocks, so that it can run against the local blockchain (`LocalEngine`),
+// the miner's worker or an external execution engine.
+type ExecutionEngine interface {
+	BuildPayload(attrs *PayloadAttributes) (*Payload, error)
+}
+
+// LocalEngine is an ExecutionEngine executing blocks directly on the local blockchain.
+type LocalEngine struct {
+	executor *BlockExecutor
+}
+
+func NewLocalEngine(eth Backend) *LocalEngine {
+	return &LocalEngine{executor: NewBlockExecutor(eth.BlockChain())}
+}
+
+// BuildPayload executes `attrs.Txs` on top of the current chain head until the
+// block gas limit is reached, then seals the block and sets it as the new head.
+// Invalid txs (e.g. with a stale nonce) are dropped.
+func (e *LocalEngine) BuildPayload(attrs *PayloadAttributes) (*Payload, error) {
+	result, err := e.executor.ExecuteBlock(attrs)
+	if err != nil {
+		return nil, err
+	}
+	for _, dropped := range result.Dropped {
+		log.Debug("Dropping transaction", "hash", dropped.Tx.Hash(), "err", dropped.Reason)
+	}
+	if err := e.executor.CommitBlock(result); err != nil {
+		return nil, err
+	}
+	return &Payload{Block: result.Block, Dropped: result.Dropped, Remaining: result.Remaining}, nil
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+)
+
+var (
+	testChainID   = big.NewInt(13527)
+	testSequencer = common.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	testKeys      = newTestKeys(4)
+	testKey       = testKeys[0]
+)
+
+// Returns `n` deterministic keys, funded in the test genesis.
+func newTestKeys(n int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		keys[i], _ = crypto.ToECDSA(common.BigToHash(big.NewInt(int64(i + 1))).Bytes())
+	}
+	return keys
+}
+
+// Backend over a bare blockchain, without tx pool.
+type testBackend struct {
+	db    ethdb.Database
+	chain *core.BlockChain
+}
+
+func (b *testBackend) BlockChain() *core.BlockChain { return b.chain }
+func (b *testBackend) TxPool() *core.TxPool         { return nil }
+func (b *testBackend) ChainDb() ethdb.Database      { return b.db }
+func (b *testBackend) StateAtBlock(*types.Block, uint64, *state.StateDB, bool, bool) (*state.StateDB, error) {
+	return nil, errors.New("not supported")
+}
+
+// Returns a chain config with Berlin at genesis and London at `londonBlock`.
+func testChainConfig(londonBlock int64) *params.ChainConfig {
+	return &params.ChainConfig{
+		ChainID:             testChainID,
+		HomesteadBlock:      common.Big0,
+		EIP150Block:         common.Big0,
+		EIP155Block:         common.Big0,
+		EIP158Block:         common.Big0,
+		ByzantiumBlock:      common.Big0,
+		ConstantinopleBlock: common.Big0,
+		PetersburgBlock:     common.Big0,
+		IstanbulBlock:       common.Big0,
+		BerlinBlock:         common.Big0,
+		LondonBlock:         big.NewInt(londonBlock),
+	}
+}
+
+func newTestBackend(t *testing.T, config *params.ChainConfig) *testBackend {
+	alloc := make(core.GenesisAlloc)
+	for _, key := range testKeys {
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = core.GenesisAccount{Balance: big.NewInt(params.Ether)}
+	}
+	genesis := &core.Genesis{Config: config, GasLimit: params.GenesisGasLimit, Alloc: alloc}
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	t.Cleanup(chain.Stop)
+	return &testBackend{db: db, chain: chain}
+}
+
+// Returns a legacy, an access list and (after London) a dynamic fee tx, starting at `nonce`.
+func testTxs(t *testing.T, config *params.ChainConfig, number *big.Int, nonce uint64) types.Transactions {
+	recipient := common.HexToAddress("0x1000")
+	gasPrice := big.NewInt(2 * params.GWei)
+	inner := []types.TxData{
+		&types.LegacyTx{Nonce: nonce, GasPrice: gasPrice, Gas: params.TxGas, To: &recipient, Value: common.Big1},
+		&types.AccessListTx{
+			ChainID:    testChainID,
+			Nonce:      nonce + 1,
+			GasPrice:   gasPrice,
+			Gas:        params.TxGas + params.TxAccessListAddressGas,
+			To:         &recipient,
+			Value:      common.Big1,
+			AccessList: types.AccessList{{Address: recipient}},
+		},
+	}
+	if config.IsLondon(number) {
+		inner = append(inner, &types.DynamicFeeTx{
+			ChainID:   testChainID,
+			Nonce:     nonce + 2,
+			GasTipCap: big.NewInt(params.GWei),
+			GasFeeCap: gasPrice,
+			Gas:       params.TxGas,
+			To:        &recipient,
+			Value:     common.Big1,
+		})
+	}
+	return signTxs(t, config, testKey, inner)
+}
+
+func signTxs(t *testing.T, config *params.ChainConfig, key *ecdsa.PrivateKey, inner []types.TxData) types.Transactions {
+	signer := types.LatestSigner(config)
+	var txs types.Transactions
+	for _, data := range inner {
+		tx, err := types.SignNewTx(key, signer, data)
+		if err != nil {
+			t.Fatalf("failed to sign tx: %v", err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// Returns the blocks as sequenced to the L1 inbox.
+func sequenceBlocks(blocks types.Blocks) []*rollupTypes.SequenceBlock {
+	var sequenced []*rollupTypes.SequenceBlock
+	for _, block := range blocks {
+		sequenced = append(sequenced, &rollupTypes.SequenceBlock{
+			SequenceContext: rollupTypes.SequenceContext{
+				NumTxs:      uint64(len(block.Transactions())),
+				BlockNumber: block.NumberU64(),
+				Timestamp:   block.Time(),
+				Coinbase:    block.Coinbase(),
+			},
+			Txs: block.Transactions(),
+		})
+	}
+	return sequenced
+}
+
+// Blocks built by the sequencer must be re-derived identically by validators
+// on both sides of the London fork.
+func TestSequencedBlocksAcrossLondon(t *testing.T) {
+	const londonBlock = 3
+	config := testChainConfig(londonBlock)
+	sequencer := newTestBackend(t, config)
+	engine := NewLocalEngine(sequencer)
+
+	var (
+		blocks types.Blocks
+		nonce  uint64
+	)
+	for i := 1; i <= 2*londonBlock; i++ {
+		txs := testTxs(t, config, big.NewInt(int64(i)), nonce)
+		nonce += uint64(len(txs))
+		payload, err := engine.BuildPayload(&PayloadAttributes{
+			Timestamp:    uint64(10 * i),
+			FeeRecipient: testSequencer,
+			Txs:          txs,
+		})
+		if err != nil {
+			t.Fatalf("failed to build block #%d: %v", i, err)
+		}
+		if len(payload.Remaining) > 0 || len(payload.Block.Transactions()) != len(txs) {
+			t.Fatalf("block #%d: have %d txs, want %d", i, len(payload.Block.Transactions()), len(txs))
+		}
+		blocks = append(blocks, payload.Block)
+	}
+	for _, block := range blocks {
+		london := config.IsLondon(block.Number())
+		if london != (block.BaseFee() != nil) {
+			t.Fatalf("block #%d: unexpected base fee %v", block.NumberU64(), block.BaseFee())
+		}
+		if block.NumberU64() == londonBlock && block.BaseFee().Cmp(big.NewInt(params.InitialBaseFee)) != 0 {
+			t.Fatalf("fork block: have base fee %v, want %d", block.BaseFee(), params.InitialBaseFee)
+		}
+		gasLimit := uint64(params.GenesisGasLimit)
+		if london {
+			gasLimit *= params.ElasticityMultiplier
+		}
+		if block.GasLimit() != gasLimit {
+			t.Fatalf("block #%d: have gas limit %d, want %d", block.NumberU64(), block.GasLimit(), gasLimit)
+		}
+	}
+
+	validator := &BaseService{Config: &Config{}, Eth: newTestBackend(t, config)}
+	if err := validator.commitBlocks(sequenceBlocks(blocks)); err != nil {
+		t.Fatalf("failed to commit blocks: %v", err)
+	}
+	for _, block := range blocks {
+		derived := validator.Chain().GetBlockByNumber(block.NumberU64())
+		if derived == nil {
+			t.Fatalf("missing derived block #%d", block.NumberU64())
+		}
+		if derived.Hash() != block.Hash() {
+			t.Fatalf("block #%d: derived hash %s, sequenced hash %s", block.NumberU64(), derived.Hash(), block.Hash())
+		}
+	}
+}
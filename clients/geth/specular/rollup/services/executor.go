@@ -0,0 +1,222 @@
+package services
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// ErrReplayProtectedTx is the reason for dropping EIP-155 txs before the EIP-155 fork.
+var ErrReplayProtectedTx = errors.New("replay protected tx before EIP155")
+
+// DroppedTx is a tx left out of an executed block.
+type DroppedTx struct {
+	// Position of the tx in the payload attributes
+	Index  int
+	Tx     *types.Transaction
+	Reason error
+}
+
+// ExecutionResult is a sealed block executed by the BlockExecutor, not yet committed.
+type ExecutionResult struct {
+	Block    *types.Block
+	Receipts types.Receipts
+	// Txs that failed to execute and were left out of the block, in order.
+	Dropped []DroppedTx
+	// Txs not executed because the block ran out of gas, in order.
+	Remaining types.Transactions
+
+	logs  []*types.Log
+	state *state.StateDB
+}
+
+// BlockExecutor deterministically executes L2 blocks on top of the local chain head.
+// It is shared by the sequencer (through `LocalEngine`) and validators (through
+// `commitBlocks`), so that blocks sequenced to L1 are derived identically.
+type BlockExecutor struct {
+	chain *core.BlockChain
+}
+
+func NewBlockExecutor(chain *core.BlockChain) *BlockExecutor {
+	return &BlockExecutor{chain: chain}
+}
+
+// ExecuteBlock executes `attrs.Txs` in order on top of the current chain head, and seals the block.
+// Txs failing to execute are dropped. Execution stops once the block runs out of gas,
+// except for a tx exceeding the gas limit of an empty block, which can never be included.
+func (e *BlockExecutor) ExecuteBlock(attrs *PayloadAttributes) (*ExecutionResult, error) {
+	parent := e.chain.CurrentBlock()
+	if parent == nil {
+		return nil, fmt.Errorf("missing parent")
+	}
+	state, err := e.chain.StateAt(parent.Root())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get parent state, err: %w", err)
+	}
+	return e.executeBlock(e.chain, parent.Header(), state, attrs)
+}
+
+// ExecuteBlocks executes consecutive blocks on top of the current chain head (see `ExecuteBlock`),
+// each on top of the previous one, without committing any of them.
+func (e *BlockExecutor) ExecuteBlocks(attrs []*PayloadAttributes) ([]*ExecutionResult, error) {
+	parent := e.chain.CurrentBlock()
+	if parent == nil {
+		return nil, fmt.Errorf("missing parent")
+	}
+	state, err := e.chain.StateAt(parent.Root())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get parent state, err: %w", err)
+	}
+	chain := &pendingChain{BlockChain: e.chain, pending: make(map[common.Hash]*types.Header)}
+	header := parent.Header()
+	results := make([]*ExecutionResult, 0, len(attrs))
+	for _, blockAttrs := range attrs {
+		result, err := e.executeBlock(chain, header, state, blockAttrs)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+		header = result.Block.Header()
+		chain.pending[result.Block.Hash()] = header
+		// The state of each result is kept for its commit.
+		state = result.state.Copy()
+	}
+	return results, nil
+}
+
+// Executes `attrs.Txs` on top of `parent` with state `state`, see `ExecuteBlock`.
+func (e *BlockExecutor) executeBlock(
+	chain core.ChainContext,
+	parent *types.Header,
+	state *state.StateDB,
+	attrs *PayloadAttributes,
+) (*ExecutionResult, error) {
+	chainConfig := e.chain.Config()
+	header := newL2Header(chainConfig, parent, attrs.Timestamp, attrs.FeeRecipient)
+	state.StartPrefetcher("executor")
+	defer state.StopPrefetcher()
+
+	var (
+		result  = &ExecutionResult{state: state}
+		gasPool = new(core.GasPool).AddGas(header.GasLimit)
+		txs     types.Transactions
+	)
+	for i, tx := range attrs.Txs {
+		// If we don't have enough gas for any further transactions, leave them for the next block.
+		if gasPool.Gas() < params.TxGas {
+			result.Remaining = attrs.Txs[i:]
+			break
+		}
+		// Check whether the tx is replay protected. If we're not in the EIP155 hf
+		// phase, start ignoring the sender until we do.
+		if tx.Protected() && !chainConfig.IsEIP155(header.Number) {
+			result.Dropped = append(result.Dropped, DroppedTx{i, tx, ErrReplayProtectedTx})
+			continue
+		}
+		state.Prepare(tx.Hash(), len(txs))
+		snap := state.Snapshot()
+		receipt, err := core.ApplyTransaction(
+			chainConfig, chain, &header.Coinbase, gasPool, state, header, tx, &header.GasUsed, *e.chain.GetVMConfig())
+		if err != nil {
+			state.RevertToSnapshot(snap)
+		}
+		if errors.Is(err, core.ErrGasLimitReached) && len(txs) > 0 {
+			result.Remaining = attrs.Txs[i:]
+			break
+		}
+		if err != nil {
+			result.Dropped = append(result.Dropped, DroppedTx{i, tx, err})
+			continue
+		}
+		txs = append(txs, tx)
+		result.Receipts = append(result.Receipts, receipt)
+	}
+	// Finalize header
+	header.Root = state.IntermediateRoot(chainConfig.IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+	// Assemble block
+	result.Block = types.NewBlock(header, txs, nil, result.Receipts, trie.NewStackTrie(nil))
+	hash := result.Block.Hash()
+	// Finalize receipts and logs
+	for i, receipt := range result.Receipts {
+		// Add block location fields
+		receipt.BlockHash = hash
+		receipt.BlockNumber = result.Block.Number()
+		receipt.TransactionIndex = uint(i)
+
+		// Update the block hash in all logs since it is now available and not when the
+		// receipt/log of individual transactions were created.
+		for _, log := range receipt.Logs {
+			log.BlockHash = hash
+		}
+		result.logs = append(result.logs, receipt.Logs...)
+	}
+	return result, nil
+}
+
+// CommitBlock writes an executed block to the blockchain and sets it as the new head.
+func (e *BlockExecutor) CommitBlock(result *ExecutionResult) error {
+	if _, err := e.chain.WriteBlockAndSetHead(result.Block, result.Receipts, result.logs, result.state, true); err != nil {
+		return fmt.Errorf("Failed to write block, err: %w", err)
+	}
+	return nil
+}
+
+// Chain context of blocks executed on top of uncommitted ones, which are looked up by BLOCKHASH.
+type pendingChain struct {
+	*core.BlockChain
+	pending map[common.Hash]*types.Header
+}
+
+func (c *pendingChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header, ok := c.pending[hash]; ok {
+		return header
+	}
+	return c.BlockChain.GetHeader(hash, number)
+}
+
+// Returns the header of the L2 block following `parent`, before execution.
+// Shared by the sequencer and the validators, so the fields must be
+// derived deterministically from the parent and the sequenced block.
+// In particular, the gas limit is fixed by the L2 genesis instead of moving
+// towards the local miner's gas ceiling.
+func newL2Header(
+	config *params.ChainConfig,
+	parent *types.Header,
+	timestamp uint64,
+	coinbase common.Address,
+) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       timestamp,
+		Coinbase:   coinbase,
+		Difficulty: common.Big1, // Fake difficulty. Avoid use 0 here because it means the merge happened
+	}
+	// Set the base fee and bump the gas limit at the fork block, keeping the gas target.
+	if config.IsLondon(header.Number) {
+		header.BaseFee = misc.CalcBaseFee(config, parent)
+		if !config.IsLondon(parent.Number) {
+			header.GasLimit = parent.GasLimit * params.ElasticityMultiplier
+		}
+	}
+	return header
+}
+
+// NextBaseFee returns the base fee of the block following `parent`,
+// or nil if it is before the London fork.
+func NextBaseFee(config *params.ChainConfig, parent *types.Header) *big.Int {
+	if !config.IsLondon(new(big.Int).Add(parent.Number, common.Big1)) {
+		return nil
+	}
+	return misc.CalcBaseFee(config, parent)
+}
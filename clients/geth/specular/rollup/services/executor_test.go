@@ -0,0 +1,139 @@
+package services
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Generates random txs from the test accounts, some of them invalid:
+// stale or future nonces, unaffordable values and too low fee caps.
+type randomTxGenerator struct {
+	rng    *rand.Rand
+	config *params.ChainConfig
+	nonces []uint64
+}
+
+func (g *randomTxGenerator) next(t *testing.T) *types.Transaction {
+	sender := g.rng.Intn(len(testKeys))
+	nonce := g.nonces[sender]
+	recipient := common.BigToAddress(big.NewInt(int64(0x1000 + g.rng.Intn(4))))
+	gasPrice := big.NewInt(2 * params.GWei)
+	value := big.NewInt(int64(g.rng.Intn(1000)))
+	valid := false
+	switch g.rng.Intn(8) {
+	case 0:
+		nonce++
+	case 1:
+		if nonce == 0 {
+			nonce++
+		} else {
+			nonce--
+		}
+	case 2:
+		value = big.NewInt(2 * params.Ether)
+	case 3:
+		gasPrice = common.Big1
+	default:
+		valid = true
+	}
+	var inner types.TxData
+	switch g.rng.Intn(3) {
+	case 0:
+		inner = &types.LegacyTx{Nonce: nonce, GasPrice: gasPrice, Gas: params.TxGas, To: &recipient, Value: value}
+	case 1:
+		inner = &types.AccessListTx{
+			ChainID:    testChainID,
+			Nonce:      nonce,
+			GasPrice:   gasPrice,
+			Gas:        params.TxGas + params.TxAccessListAddressGas,
+			To:         &recipient,
+			Value:      value,
+			AccessList: types.AccessList{{Address: recipient}},
+		}
+	default:
+		// Invalid before London.
+		inner = &types.DynamicFeeTx{
+			ChainID:   testChainID,
+			Nonce:     nonce,
+			GasTipCap: common.Big1,
+			GasFeeCap: gasPrice,
+			Gas:       params.TxGas,
+			To:        &recipient,
+			Value:     value,
+		}
+	}
+	if valid {
+		g.nonces[sender]++
+	}
+	return signTxs(t, g.config, testKeys[sender], []types.TxData{inner})[0]
+}
+
+// Random blocks built by the sequencer, including invalid txs, must be
+// re-derived by validators into byte-identical blocks.
+func TestBlockExecutionEquivalence(t *testing.T) {
+	const (
+		londonBlock = 4
+		numBlocks   = 8
+		maxTxs      = 12
+	)
+	config := testChainConfig(londonBlock)
+	var numDropped int
+	for seed := int64(0); seed < 20; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		gen := &randomTxGenerator{rng: rng, config: config, nonces: make([]uint64, len(testKeys))}
+		engine := NewLocalEngine(newTestBackend(t, config))
+
+		var blocks types.Blocks
+		for i := 1; i <= numBlocks; i++ {
+			var txs types.Transactions
+			for j := rng.Intn(maxTxs + 1); j > 0; j-- {
+				txs = append(txs, gen.next(t))
+			}
+			payload, err := engine.BuildPayload(&PayloadAttributes{
+				Timestamp:    uint64(10 * i),
+				FeeRecipient: testSequencer,
+				Txs:          txs,
+			})
+			if err != nil {
+				t.Fatalf("seed %d: failed to build block #%d: %v", seed, i, err)
+			}
+			if have := len(payload.Block.Transactions()) + len(payload.Dropped); have != len(txs) || len(payload.Remaining) > 0 {
+				t.Fatalf("seed %d: block #%d: %d txs unaccounted for", seed, i, len(txs)-have)
+			}
+			numDropped += len(payload.Dropped)
+			blocks = append(blocks, payload.Block)
+		}
+
+		validator := &BaseService{Config: &Config{}, Eth: newTestBackend(t, config)}
+		if err := validator.commitBlocks(sequenceBlocks(blocks)); err != nil {
+			t.Fatalf("seed %d: failed to commit blocks: %v", seed, err)
+		}
+		for _, block := range blocks {
+			want, err := rlp.EncodeToBytes(block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			derived := validator.Chain().GetBlockByNumber(block.NumberU64())
+			if derived == nil {
+				t.Fatalf("seed %d: missing derived block #%d", seed, block.NumberU64())
+			}
+			have, err := rlp.EncodeToBytes(derived)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(have, want) {
+				t.Fatalf("seed %d: block #%d: derived block differs from sequenced block", seed, block.NumberU64())
+			}
+		}
+	}
+	if numDropped == 0 {
+		t.Fatal("no invalid txs generated")
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Maintains the L2 safe and finalized heads, exposed through the `safe` and
+// `finalized` block tags:
+// - unsafe: the local chain head (`CurrentBlock`).
+// - safe: the last block whose batch is included in the L1 chain (up to the L1 latest header).
+// - finalized: the last block whose batch is included in an L1-finalized block.
+// Both are derived from the L1 origin index, so blocks must be indexed to advance.
+func (b *BaseService) HeadsLoop(ctx context.Context) {
+	defer b.Wg.Done()
+	latestCh := b.L1Syncer.LatestHeaderBroker.Subscribe()
+	defer b.L1Syncer.LatestHeaderBroker.Unsubscribe(latestCh)
+	finalizedCh := b.L1Syncer.FinalizedHeaderBroker.Subscribe()
+	defer b.L1Syncer.FinalizedHeaderBroker.Unsubscribe(finalizedCh)
+	for {
+		select {
+		case header := <-latestCh:
+			b.advanceSafe(header)
+		case header := <-finalizedCh:
+			b.advanceFinalized(header)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Advances the safe head to the last block sequenced at or before the given L1 header.
+func (b *BaseService) advanceSafe(l1Header *types.Header) {
+	current := b.Chain().CurrentSafeBlock()
+	block := b.lastBlockSequencedBy(current, l1Header.Number.Uint64())
+	if block == nil {
+		return
+	}
+	b.Chain().SetSafe(block)
+	log.Debug("Advanced safe head", "number", block.NumberU64(), "l1 block", l1Header.Number)
+}
+
+// Advances the finalized head to the last block sequenced at or before the given L1 finalized header.
+func (b *BaseService) advanceFinalized(l1Header *types.Header) {
+	current := b.Chain().CurrentFinalizedBlock()
+	block := b.lastBlockSequencedBy(current, l1Header.Number.Uint64())
+	if block == nil {
+		return
+	}
+	b.Chain().SetFinalized(block)
+	// The safe head never lags behind the finalized head.
+	if safe := b.Chain().CurrentSafeBlock(); safe == nil || safe.NumberU64() < block.NumberU64() {
+		b.Chain().SetSafe(block)
+	}
+	log.Info("Advanced finalized head", "number", block.NumberU64(), "l1 block", l1Header.Number)
+}
+
+// Scans forward from `current` for the last local block with an L1 origin at or before
+// `l1BlockNumber`. Returns nil if there is no such block beyond `current`.
+func (b *BaseService) lastBlockSequencedBy(current *types.Block, l1BlockNumber uint64) *types.Block {
+	number := uint64(0)
+	if current != nil {
+		number = current.NumberU64()
+	}
+	head := b.Chain().CurrentBlock().NumberU64()
+	last := number
+	for n := number + 1; n <= head; n++ {
+		origin := b.L1Origin(n)
+		if origin == nil || origin.L1BlockNumber > l1BlockNumber {
+			break
+		}
+		last = n
+	}
+	if current != nil && last == number {
+		return nil
+	}
+	return b.Chain().GetBlockByNumber(last)
+}
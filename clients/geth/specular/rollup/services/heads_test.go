@@ -0,0 +1,76 @@
+package services
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	rollupRawdb "github.com/specularl2/specular/clients/geth/specular/rollup/rawdb"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+)
+
+// Returns a service over a chain of blocks sequenced in the given L1 blocks.
+func newIndexedTestService(t *testing.T, l1BlockNumbers []uint64) *BaseService {
+	config := testChainConfig(0)
+	backend := newTestBackend(t, config)
+	engine := NewLocalEngine(backend)
+	for i, l1BlockNumber := range l1BlockNumbers {
+		number := uint64(i + 1)
+		if _, err := engine.BuildPayload(&PayloadAttributes{Timestamp: 10 * number, FeeRecipient: testSequencer}); err != nil {
+			t.Fatalf("failed to build block #%d: %v", number, err)
+		}
+		rollupRawdb.WriteL1Origin(backend.db, number, &rollupTypes.L1Origin{BatchNumber: uint64(i), L1BlockNumber: l1BlockNumber})
+	}
+	return &BaseService{Config: &Config{}, Eth: backend}
+}
+
+func l1Header(number uint64) *types.Header {
+	return &types.Header{Number: new(big.Int).SetUint64(number)}
+}
+
+func checkHeads(t *testing.T, b *BaseService, safe, finalized uint64) {
+	t.Helper()
+	if have := b.Chain().CurrentSafeBlock(); have == nil || have.NumberU64() != safe {
+		t.Errorf("have safe head %v, want #%d", have, safe)
+	}
+	if have := b.Chain().CurrentFinalizedBlock(); have == nil || have.NumberU64() != finalized {
+		t.Errorf("have finalized head %v, want #%d", have, finalized)
+	}
+}
+
+func TestAdvanceSafeAndFinalized(t *testing.T) {
+	b := newIndexedTestService(t, []uint64{10, 10, 20, 30, 40})
+	b.advanceFinalized(l1Header(5))
+	checkHeads(t, b, 0, 0)
+	b.advanceSafe(l1Header(25))
+	checkHeads(t, b, 3, 0)
+	// An older L1 header does not move the safe head back.
+	b.advanceSafe(l1Header(10))
+	checkHeads(t, b, 3, 0)
+	b.advanceFinalized(l1Header(15))
+	checkHeads(t, b, 3, 2)
+	// The safe head never lags behind the finalized head.
+	b.advanceFinalized(l1Header(30))
+	checkHeads(t, b, 4, 4)
+	b.advanceSafe(l1Header(100))
+	checkHeads(t, b, 5, 4)
+}
+
+// Rewinds must move the safe and finalized heads back, both after L1 reorgs and on startup.
+func TestSetHeadResetsSafeAndFinalized(t *testing.T) {
+	b := newIndexedTestService(t, []uint64{10, 20, 30, 40})
+	b.advanceFinalized(l1Header(30))
+	b.advanceSafe(l1Header(40))
+	checkHeads(t, b, 4, 3)
+	if err := b.RewindToL1Block(30); err != nil {
+		t.Fatalf("failed to rewind: %v", err)
+	}
+	checkHeads(t, b, 3, 3)
+	if err := b.rewindUnsequenced(1); err != nil {
+		t.Fatalf("failed to rewind: %v", err)
+	}
+	checkHeads(t, b, 1, 1)
+	if origin := b.L1Origin(2); origin != nil {
+		t.Errorf("rewound block #2 still indexed")
+	}
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/specularl2/specular/clients/geth/specular/bindings"
+	"github.com/specularl2/specular/clients/geth/specular/proof"
+	rollupRawdb "github.com/specularl2/specular/clients/geth/specular/rollup/rawdb"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+var (
+	ErrInboxSizeNotReached = fmt.Errorf("inbox size not reached by local chain")
+	ErrInboxSizeMidBlock   = fmt.Errorf("inbox size reached in the middle of a block")
+)
+
+// Gets the L1 origin (i.e. the sequencing batch) of an L2 block, or nil if not indexed.
+func (b *BaseService) L1Origin(number uint64) *rollupTypes.L1Origin {
+	return rollupRawdb.ReadL1Origin(b.Eth.ChainDb(), number)
+}
+
+// Gets the L2 block range sequenced by a batch, or nil if not indexed.
+func (b *BaseService) BatchBlockRange(batchNumber uint64) *rollupTypes.BlockRange {
+	return rollupRawdb.ReadBatchBlockRange(b.Eth.ChainDb(), batchNumber)
+}
+
+// Gets the L2 block range covered by an assertion, or nil if not indexed.
+func (b *BaseService) AssertionBlockRange(assertionID *big.Int) *rollupTypes.BlockRange {
+	return rollupRawdb.ReadAssertionBlockRange(b.Eth.ChainDb(), assertionID)
+}
+
+// Gets the txs sequenced in an L2 block but skipped (see `commitBlocks`).
+func (b *BaseService) SkippedTxs(number uint64) []*rollupTypes.SkippedTx {
+	return rollupRawdb.ReadSkippedTxs(b.Eth.ChainDb(), number)
+}
+
+// Gets the batch skipped in an L2 block, or nil if the block was derived from a valid batch
+// (see `processTxBatchAppendedEvent`).
+func (b *BaseService) SkippedBatch(number uint64) *rollupTypes.SkippedBatch {
+	return rollupRawdb.ReadSkippedBatch(b.Eth.ChainDb(), number)
+}
+
+// Gets the number of inbox txs sequenced in an L2 block, including the skipped ones
+// and the txs of a skipped batch.
+func (b *BaseService) NumInboxTxs(block *types.Block) uint64 {
+	numTxs := uint64(len(block.Transactions()) + len(b.SkippedTxs(block.NumberU64())))
+	if skipped := b.SkippedBatch(block.NumberU64()); skipped != nil {
+		numTxs += skipped.NumTxs
+	}
+	return numTxs
+}
+
+// Gets the txs sequenced in an L2 block, including the skipped ones,
+// and the position among them of each tx included in the block.
+func (b *BaseService) sequencedTxs(block *types.Block) (types.Transactions, []int) {
+	included := block.Transactions()
+	skipped := b.SkippedTxs(block.NumberU64())
+	txs := make(types.Transactions, 0, len(included)+len(skipped))
+	positions := make([]int, 0, len(included))
+	for len(included) > 0 || len(skipped) > 0 {
+		if len(skipped) > 0 && skipped[0].Index == uint64(len(txs)) {
+			txs = append(txs, skipped[0].Tx)
+			skipped = skipped[1:]
+			continue
+		}
+		if len(included) == 0 {
+			// Corrupted record, keep the skipped txs in order.
+			txs = append(txs, skipped[0].Tx)
+			skipped = skipped[1:]
+			continue
+		}
+		positions = append(positions, len(txs))
+		txs = append(txs, included[0])
+		included = included[1:]
+	}
+	return txs, positions
+}
+
+// Indexes L2 blocks [start, end] as sequenced by the batch of the given `TxBatchAppended` event.
+func (b *BaseService) IndexBatch(ev *bindings.ISequencerInboxTxBatchAppended, start, end uint64) {
+	b.indexBatch(ev, start, end, nil)
+}
+
+// Indexes L2 blocks [start, end] as `IndexBatch`, where `sequencedNumbers` are the block numbers
+// of their sequence contexts in the batch, which may differ from their local numbers.
+func (b *BaseService) indexBatch(ev *bindings.ISequencerInboxTxBatchAppended, start, end uint64, sequencedNumbers []uint64) {
+	batch := b.Eth.ChainDb().NewBatch()
+	for number := start; number <= end; number++ {
+		origin := &rollupTypes.L1Origin{
+			BatchNumber:   ev.BatchNumber.Uint64(),
+			L1BlockNumber: ev.Raw.BlockNumber,
+			L1TxHash:      ev.Raw.TxHash,
+			StartTxNumber: ev.StartTxNumber.Uint64(),
+		}
+		if i := number - start; i < uint64(len(sequencedNumbers)) {
+			origin.SequencedBlockNumber = &sequencedNumbers[i]
+		}
+		rollupRawdb.WriteL1Origin(batch, number, origin)
+	}
+	rollupRawdb.WriteBatchBlockRange(batch, ev.BatchNumber.Uint64(), &rollupTypes.BlockRange{StartBlock: start, EndBlock: end})
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write batch index", "err", err)
+	}
+}
+
+// Builds the proof of inclusion of the tx executed from `state` in the sequencer inbox.
+func (b *BaseService) TxInclusionProof(state *proof.ExecutionState) (*proof.TxInclusionProof, error) {
+	number := state.Block.NumberU64()
+	origin := b.L1Origin(number)
+	if origin == nil {
+		return nil, fmt.Errorf("L1 origin of block #%d not indexed", number)
+	}
+	if origin.BatchNumber > 0 && origin.StartTxNumber == 0 {
+		return nil, fmt.Errorf("Start tx number of batch #%d not indexed", origin.BatchNumber)
+	}
+	blockRange := b.BatchBlockRange(origin.BatchNumber)
+	if blockRange == nil {
+		return nil, fmt.Errorf("Block range of batch #%d not indexed", origin.BatchNumber)
+	}
+	// Rebuild the batch as sequenced, with the skipped txs.
+	batch := new(rollupTypes.TxBatch)
+	var index int
+	for n := blockRange.StartBlock; n <= blockRange.EndBlock; n++ {
+		block := b.Chain().GetBlockByNumber(n)
+		if block == nil {
+			return nil, fmt.Errorf("Block #%d of batch #%d not found", n, origin.BatchNumber)
+		}
+		txs, positions := b.sequencedTxs(block)
+		// Position of the tx in the batch
+		if n == number {
+			if state.TransactionIdx >= uint64(len(positions)) {
+				return nil, fmt.Errorf("Tx #%d not found in block #%d", state.TransactionIdx, number)
+			}
+			index = len(batch.Txs) + positions[state.TransactionIdx]
+		}
+		// The batch committed to the block numbers of its contexts, not to the local ones.
+		sequencedNumber := n
+		if blockOrigin := b.L1Origin(n); blockOrigin != nil && blockOrigin.SequencedBlockNumber != nil {
+			sequencedNumber = *blockOrigin.SequencedBlockNumber
+		}
+		batch.Contexts = append(batch.Contexts, rollupTypes.SequenceContext{
+			NumTxs:      uint64(len(txs)),
+			BlockNumber: sequencedNumber,
+			Timestamp:   block.Time(),
+			Coinbase:    block.Coinbase(),
+		})
+		batch.Txs = append(batch.Txs, txs...)
+	}
+	txs, err := proof.NewInboxTxs(batch, state.Block.Coinbase())
+	if err != nil {
+		return nil, err
+	}
+	var prevAcc common.Hash
+	if origin.BatchNumber > 0 {
+		prevAcc, err = b.L1Client.GetAccumulator(origin.BatchNumber - 1)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get accumulator of batch #%d, err: %w", origin.BatchNumber-1, err)
+		}
+	}
+	return proof.NewTxInclusionProof(origin.BatchNumber, origin.StartTxNumber, prevAcc, txs, index)
+}
+
+// Indexes the L2 block range covered by an assertion. Assertion ID and boundaries must be set.
+func (b *BaseService) IndexAssertion(assertion *rollupTypes.Assertion) {
+	rollupRawdb.WriteAssertionBlockRange(
+		b.Eth.ChainDb(),
+		assertion.ID,
+		&rollupTypes.BlockRange{StartBlock: assertion.StartBlock, EndBlock: assertion.EndBlock},
+	)
+}
+
+// Finds the local block at which the inbox reaches `inboxSize`, counting from
+// block `start` with `startInboxSize` txs before it.
+// Returns `ErrInboxSizeNotReached` if the local chain is too short, or
+// `ErrInboxSizeMidBlock` if no block ends at `inboxSize`.
+func (b *BaseService) FindInboxSizeEnd(start uint64, startInboxSize, inboxSize *big.Int) (uint64, error) {
+	remaining := new(big.Int).Sub(inboxSize, startInboxSize)
+	number := start
+	for remaining.Sign() > 0 {
+		block := b.Chain().GetBlockByNumber(number)
+		if block == nil {
+			return 0, ErrInboxSizeNotReached
+		}
+		remaining.Sub(remaining, new(big.Int).SetUint64(b.NumInboxTxs(block)))
+		number++
+	}
+	if remaining.Sign() < 0 {
+		return 0, fmt.Errorf("%w: inbox size %v, block #%d", ErrInboxSizeMidBlock, inboxSize, number-1)
+	}
+	return number - 1, nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestFindInboxSizeEnd(t *testing.T) {
+	config := testChainConfig(0)
+	backend := newTestBackend(t, config)
+	engine := NewLocalEngine(backend)
+	// Blocks #1-#3 of 3 txs each.
+	for i := 1; i <= 3; i++ {
+		_, err := engine.BuildPayload(&PayloadAttributes{
+			Timestamp:    uint64(10 * i),
+			FeeRecipient: testSequencer,
+			Txs:          testTxs(t, config, big.NewInt(int64(i)), uint64(3*(i-1))),
+		})
+		if err != nil {
+			t.Fatalf("failed to build block #%d: %v", i, err)
+		}
+	}
+	service := &BaseService{Config: &Config{}, Eth: backend}
+	tests := []struct {
+		name           string
+		start          uint64
+		startInboxSize int64
+		inboxSize      int64
+		end            uint64
+		err            error
+	}{
+		{"first block", 1, 0, 3, 1, nil},
+		{"last block", 1, 0, 9, 3, nil},
+		{"from parent end", 2, 3, 6, 2, nil},
+		{"middle of block", 1, 0, 4, 0, ErrInboxSizeMidBlock},
+		{"below start", 2, 3, 2, 0, ErrInboxSizeMidBlock},
+		{"beyond local chain", 1, 0, 12, 0, ErrInboxSizeNotReached},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, err := service.FindInboxSizeEnd(tt.start, big.NewInt(tt.startInboxSize), big.NewInt(tt.inboxSize))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("have error %v, want %v", err, tt.err)
+			}
+			if err == nil && end != tt.end {
+				t.Fatalf("have end #%d, want #%d", end, tt.end)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// RPCBatch is the RPC representation of the L1 batch that sequenced an L2 block.
+type RPCBatch struct {
+	BatchNumber   hexutil.Uint64 `json:"batchNumber"`
+	L1BlockNumber hexutil.Uint64 `json:"l1BlockNumber"`
+	L1TxHash      common.Hash    `json:"l1TxHash"`
+	StartBlock    hexutil.Uint64 `json:"startBlock"`
+	EndBlock      hexutil.Uint64 `json:"endBlock"`
+	// Reason the batch could not be derived, if it was skipped (and derived as an empty block).
+	SkipReason string `json:"skipReason,omitempty"`
+}
+
+// RPCAssertionInfo is the RPC representation of an indexed assertion.
+type RPCAssertionInfo struct {
+	ID            *hexutil.Big    `json:"id"`
+	Parent        *hexutil.Big    `json:"parent"`
+	VmHash        common.Hash     `json:"vmHash"`
+	InboxSize     *hexutil.Big    `json:"inboxSize"`
+	StartBlock    hexutil.Uint64  `json:"startBlock"`
+	EndBlock      hexutil.Uint64  `json:"endBlock"`
+	Status        string          `json:"status"`
+	L1BlockNumber hexutil.Uint64  `json:"l1BlockNumber"`
+	L1TxHash      common.Hash     `json:"l1TxHash"`
+	ChallengeAddr *common.Address `json:"challengeAddr,omitempty"`
+	Invalid       bool            `json:"invalid,omitempty"`
+}
+
+// RPCSkippedTx is the RPC representation of a tx sequenced in an L2 block but skipped.
+type RPCSkippedTx struct {
+	// Position of the tx among the txs sequenced in the block
+	Index  hexutil.Uint64 `json:"index"`
+	TxHash common.Hash    `json:"txHash"`
+	Reason string         `json:"reason"`
+}
+
+// BlockStatus describes the L1 settlement state of an L2 block.
+type BlockStatus struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	// Batch that sequenced the block, nil if not sequenced (or indexed) yet.
+	Batch *RPCBatch `json:"batch"`
+	// Assertion covering the block, nil if not asserted (or indexed) yet.
+	Assertion *RPCAssertionInfo `json:"assertion"`
+	// Txs sequenced in the block but skipped, e.g. with a stale nonce.
+	SkippedTxs []*RPCSkippedTx `json:"skippedTxs,omitempty"`
+}
+
+// TransactionStatus describes the L1 settlement state of an L2 transaction.
+type TransactionStatus struct {
+	TxHash common.Hash `json:"txHash"`
+	*BlockStatus
+}
+
+// RollupAPI is the collection of rollup metadata APIs served by the indexer.
+type RollupAPI struct {
+	i *Indexer
+}
+
+// GetTransactionStatus returns the L1 settlement state of an L2 transaction,
+// or nil if the transaction is not found.
+func (api *RollupAPI) GetTransactionStatus(ctx context.Context, hash common.Hash) (*TransactionStatus, error) {
+	tx, _, blockNumber, _, err := api.i.ProofBackend.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get transaction, err: %w", err)
+	}
+	if tx == nil {
+		return nil, nil
+	}
+	status, err := api.GetBlockStatus(ctx, hexutil.Uint64(blockNumber))
+	if err != nil || status == nil {
+		return nil, err
+	}
+	return &TransactionStatus{TxHash: hash, BlockStatus: status}, nil
+}
+
+// GetBlockStatus returns the L1 settlement state of an L2 block, or nil if the block is not found.
+func (api *RollupAPI) GetBlockStatus(ctx context.Context, number hexutil.Uint64) (*BlockStatus, error) {
+	block := api.i.Chain().GetBlockByNumber(uint64(number))
+	if block == nil {
+		return nil, nil
+	}
+	status := &BlockStatus{BlockNumber: number, BlockHash: block.Hash()}
+	if origin := api.i.L1Origin(uint64(number)); origin != nil {
+		status.Batch = api.newRPCBatch(origin)
+	}
+	if info := api.i.BlockAssertionInfo(uint64(number)); info != nil {
+		status.Assertion = newRPCAssertionInfo(info)
+	}
+	status.SkippedTxs = api.GetSkippedTransactions(ctx, number)
+	return status, nil
+}
+
+// GetSkippedTransactions returns the txs sequenced in an L2 block but skipped,
+// because they failed to execute (e.g. with a stale nonce or insufficient funds).
+func (api *RollupAPI) GetSkippedTransactions(ctx context.Context, number hexutil.Uint64) []*RPCSkippedTx {
+	var txs []*RPCSkippedTx
+	for _, skipped := range api.i.SkippedTxs(uint64(number)) {
+		txs = append(txs, &RPCSkippedTx{
+			Index:  hexutil.Uint64(skipped.Index),
+			TxHash: skipped.Tx.Hash(),
+			Reason: skipped.Reason,
+		})
+	}
+	return txs
+}
+
+// GetBatch returns an indexed L1 batch, or nil if not indexed.
+func (api *RollupAPI) GetBatch(ctx context.Context, batchNumber hexutil.Uint64) *RPCBatch {
+	blockRange := api.i.BatchBlockRange(uint64(batchNumber))
+	if blockRange == nil {
+		return nil
+	}
+	origin := api.i.L1Origin(blockRange.StartBlock)
+	if origin == nil {
+		return nil
+	}
+	return api.newRPCBatch(origin)
+}
+
+// GetAssertion returns an indexed assertion, or nil if not indexed.
+func (api *RollupAPI) GetAssertion(ctx context.Context, id *hexutil.Big) *RPCAssertionInfo {
+	info := api.i.AssertionInfo((*big.Int)(id))
+	if info == nil {
+		return nil
+	}
+	return newRPCAssertionInfo(info)
+}
+
+func (api *RollupAPI) newRPCBatch(origin *rollupTypes.L1Origin) *RPCBatch {
+	batch := &RPCBatch{
+		BatchNumber:   hexutil.Uint64(origin.BatchNumber),
+		L1BlockNumber: hexutil.Uint64(origin.L1BlockNumber),
+		L1TxHash:      origin.L1TxHash,
+	}
+	if blockRange := api.i.BatchBlockRange(origin.BatchNumber); blockRange != nil {
+		batch.StartBlock = hexutil.Uint64(blockRange.StartBlock)
+		batch.EndBlock = hexutil.Uint64(blockRange.EndBlock)
+		if skipped := api.i.SkippedBatch(blockRange.StartBlock); skipped != nil {
+			batch.SkipReason = skipped.Reason
+		}
+	}
+	return batch
+}
+
+func newRPCAssertionInfo(info *rollupTypes.AssertionInfo) *RPCAssertionInfo {
+	result := &RPCAssertionInfo{
+		ID:            (*hexutil.Big)(info.ID),
+		Parent:        (*hexutil.Big)(info.Parent),
+		VmHash:        info.VmHash,
+		InboxSize:     (*hexutil.Big)(info.InboxSize),
+		StartBlock:    hexutil.Uint64(info.StartBlock),
+		EndBlock:      hexutil.Uint64(info.EndBlock),
+		Status:        info.Status.String(),
+		L1BlockNumber: hexutil.Uint64(info.L1BlockNumber),
+		L1TxHash:      info.L1TxHash,
+		Invalid:       info.Invalid,
+	}
+	if info.ChallengeAddr != (common.Address{}) {
+		result.ChallengeAddr = &info.ChallengeAddr
+	}
+	return result
+}
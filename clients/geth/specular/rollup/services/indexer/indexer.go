@@ -1,21 +1,312 @@
 package indexer
 
 import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/specularl2/specular/clients/geth/specular/bindings"
 	"github.com/specularl2/specular/clients/geth/specular/proof"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
+	rollupRawdb "github.com/specularl2/specular/clients/geth/specular/rollup/rawdb"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/services"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
 )
 
-type Indexer struct{ *services.BaseService }
+// Delay before retrying a failed L1 request or database write
+var retryDelay = 2 * time.Second
+
+// Returned for an assertion that does not end at a local block boundary (or whose parent does not).
+var errInvalidAssertion = errors.New("invalid assertion")
+
+// Indexer is a read-replica that also indexes rollup metadata (batches and assertions)
+// of L2 blocks and serves it through the `rollup` API namespace.
+type Indexer struct {
+	*services.BaseService
+
+	newBatchCh chan struct{}
+}
 
 func New(eth services.Backend, proofBackend proof.Backend, l1Client client.L1BridgeClient, cfg *services.Config) (*Indexer, error) {
 	base, err := services.NewBaseService(eth, proofBackend, l1Client, cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &Indexer{BaseService: base}, nil
+	return &Indexer{BaseService: base, newBatchCh: make(chan struct{}, 4096)}, nil
+}
+
+// This goroutine indexes assertions created on the L1 Rollup contract, and tracks their status.
+// Batches are indexed by `SyncLoop`.
+func (i *Indexer) assertionLoop(ctx context.Context) {
+	defer i.Wg.Done()
+
+	start := i.L1SyncStart
+	createdCh := client.SubscribeHeaderMapped[*bindings.IRollupAssertionCreated](
+		ctx, i.L1Syncer.LatestHeaderBroker, i.L1Client.FilterAssertionCreated, start,
+	)
+	challengedCh := client.SubscribeHeaderMapped[*bindings.IRollupAssertionChallenged](
+		ctx, i.L1Syncer.LatestHeaderBroker, i.L1Client.FilterAssertionChallenged, start,
+	)
+	confirmedCh := client.SubscribeHeaderMapped[*bindings.IRollupAssertionConfirmed](
+		ctx, i.L1Syncer.LatestHeaderBroker, i.L1Client.FilterAssertionConfirmed, start,
+	)
+	rejectedCh := client.SubscribeHeaderMapped[*bindings.IRollupAssertionRejected](
+		ctx, i.L1Syncer.LatestHeaderBroker, i.L1Client.FilterAssertionRejected, start,
+	)
+
+	db := i.Eth.ChainDb()
+	// Assertions whose blocks are not synced yet, in creation order.
+	// Persisted, since their creation events are not replayed after a restart.
+	var queued []*rollupTypes.AssertionInfo
+	for _, id := range rollupRawdb.ReadQueuedAssertions(db) {
+		if info := i.AssertionInfo(id); info != nil {
+			queued = append(queued, info)
+		}
+	}
+	writeQueued := func(db ethdb.KeyValueWriter) error {
+		ids := make([]*big.Int, len(queued))
+		for n, info := range queued {
+			ids[n] = info.ID
+		}
+		return rollupRawdb.WriteQueuedAssertions(db, ids)
+	}
+	// Fires when indexing failed on an L1 request or a database write and must be retried
+	var retryCh <-chan time.Time
+	// Indexes queued assertions whose blocks are now synced
+	indexQueued := func() {
+		for len(queued) > 0 {
+			info := queued[0]
+			err := i.indexAssertion(info)
+			if errors.Is(err, services.ErrInboxSizeNotReached) {
+				return
+			}
+			if errors.Is(err, errInvalidAssertion) {
+				// Any staker can create an assertion, so an invalid one must not halt indexing.
+				log.Warn("Skipping invalid assertion", "id", info.ID, "inbox size", info.InboxSize, "err", err)
+				info.Invalid = true
+				err = rollupRawdb.WriteAssertionInfo(db, info)
+			}
+			if err != nil {
+				log.Warn("Failed to index assertion, retrying", "id", info.ID, "err", err)
+				retryCh = time.After(retryDelay)
+				return
+			}
+			queued = queued[1:]
+			if err := writeQueued(db); err != nil {
+				log.Warn("Failed to write queued assertions, retrying", "err", err)
+				retryCh = time.After(retryDelay)
+				return
+			}
+		}
+	}
+	// Updates the status of an assertion, indexed or queued.
+	// Returns an error only if `ctx` is done.
+	setStatus := func(assertionID *big.Int, status rollupTypes.AssertionStatus, update func(*rollupTypes.AssertionInfo)) error {
+		var info *rollupTypes.AssertionInfo
+		for _, q := range queued {
+			if q.ID.Cmp(assertionID) == 0 {
+				info = q
+			}
+		}
+		isQueued := info != nil
+		if !isQueued {
+			info = i.AssertionInfo(assertionID)
+		}
+		if info == nil {
+			log.Warn("Status update for unknown assertion", "id", assertionID, "status", status)
+			return nil
+		}
+		info.Status = status
+		if update != nil {
+			update(info)
+		}
+		return retry(ctx, "Failed to update assertion status", func() error {
+			if err := rollupRawdb.WriteAssertionInfo(db, info); err != nil {
+				return err
+			}
+			if status == rollupTypes.AssertionConfirmed && !isQueued && !info.Invalid {
+				// Confirmed assertions take precedence over siblings covering the same blocks.
+				return i.indexBlockAssertions(info, true)
+			}
+			return nil
+		})
+	}
+
+	for {
+		select {
+		case <-i.newBatchCh:
+			indexQueued()
+		case <-retryCh:
+			retryCh = nil
+			indexQueued()
+		case ev := <-createdCh:
+			if i.AssertionInfo(ev.AssertionID) != nil {
+				// Already indexed (or queued) before a restart
+				continue
+			}
+			assertion, err := i.getAssertion(ctx, ev.AssertionID)
+			if err != nil {
+				return
+			}
+			info := &rollupTypes.AssertionInfo{
+				ID:            ev.AssertionID,
+				Parent:        assertion.Parent,
+				VmHash:        ev.VmHash,
+				InboxSize:     assertion.InboxSize,
+				Status:        rollupTypes.AssertionPending,
+				L1BlockNumber: ev.Raw.BlockNumber,
+				L1TxHash:      ev.Raw.TxHash,
+			}
+			log.Info("Indexing assertion", "id", info.ID, "inbox size", info.InboxSize)
+			queued = append(queued, info)
+			err = retry(ctx, "Failed to queue assertion", func() error {
+				batch := db.NewBatch()
+				if err := rollupRawdb.WriteAssertionInfo(batch, info); err != nil {
+					return err
+				}
+				if err := writeQueued(batch); err != nil {
+					return err
+				}
+				return batch.Write()
+			})
+			if err != nil {
+				return
+			}
+			indexQueued()
+		case ev := <-challengedCh:
+			err := setStatus(ev.AssertionID, rollupTypes.AssertionChallenged, func(info *rollupTypes.AssertionInfo) {
+				info.ChallengeAddr = ev.ChallengeAddr
+			})
+			if err != nil {
+				return
+			}
+		case ev := <-confirmedCh:
+			if err := setStatus(ev.AssertionID, rollupTypes.AssertionConfirmed, nil); err != nil {
+				return
+			}
+		case ev := <-rejectedCh:
+			if err := setStatus(ev.AssertionID, rollupTypes.AssertionRejected, nil); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Gets an assertion from L1, retrying on failure. Returns an error only if `ctx` is done.
+func (i *Indexer) getAssertion(ctx context.Context, assertionID *big.Int) (bindings.IRollupAssertion, error) {
+	var assertion bindings.IRollupAssertion
+	err := retry(ctx, "Failed to get assertion", func() error {
+		var err error
+		assertion, err = i.L1Client.GetAssertion(assertionID)
+		return err
+	})
+	return assertion, err
+}
+
+// Runs `fn` until it succeeds, e.g. an L1 request or a database write, logging failures with `msg`.
+// Returns an error only if `ctx` is done.
+func retry(ctx context.Context, msg string, fn func() error) error {
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		log.Warn(msg+", retrying", "err", err)
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Sets the block range of an assertion and indexes it.
+// Returns `services.ErrInboxSizeNotReached` if its blocks are not synced yet, or
+// `errInvalidAssertion` if it does not end at a block boundary.
+func (i *Indexer) indexAssertion(info *rollupTypes.AssertionInfo) error {
+	// The genesis assertion covers the genesis block only.
+	if info.ID.Sign() != 0 {
+		var parentEnd uint64
+		var parentInboxSize *big.Int
+		if parent := i.AssertionInfo(info.Parent); parent != nil {
+			if parent.Invalid {
+				return fmt.Errorf("%w: parent %v is invalid", errInvalidAssertion, parent.ID)
+			}
+			parentEnd, parentInboxSize = parent.EndBlock, parent.InboxSize
+		} else {
+			// Parent created before the indexer started, find its blocks from L1.
+			parent, err := i.L1Client.GetAssertion(info.Parent)
+			if err != nil {
+				return fmt.Errorf("Failed to get parent assertion, err: %w", err)
+			}
+			parentInboxSize = parent.InboxSize
+			parentEnd, err = i.FindInboxSizeEnd(1, common.Big0, parentInboxSize)
+			if errors.Is(err, services.ErrInboxSizeMidBlock) {
+				return fmt.Errorf("%w: parent %v, err: %v", errInvalidAssertion, info.Parent, err)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		end, err := i.FindInboxSizeEnd(parentEnd+1, parentInboxSize, info.InboxSize)
+		if errors.Is(err, services.ErrInboxSizeMidBlock) {
+			return fmt.Errorf("%w: %v", errInvalidAssertion, err)
+		}
+		if err != nil {
+			return err
+		}
+		info.StartBlock = parentEnd + 1
+		info.EndBlock = end
+	}
+	if err := rollupRawdb.WriteAssertionInfo(i.Eth.ChainDb(), info); err != nil {
+		return err
+	}
+	i.IndexAssertion(&rollupTypes.Assertion{
+		ID:         info.ID,
+		VmHash:     info.VmHash,
+		InboxSize:  info.InboxSize,
+		StartBlock: info.StartBlock,
+		EndBlock:   info.EndBlock,
+	})
+	return i.indexBlockAssertions(info, info.Status == rollupTypes.AssertionConfirmed)
+}
+
+// Indexes `info` as the assertion covering its blocks.
+// Blocks already covered by another assertion (e.g. a challenged sibling) are overwritten only if `overwrite` is set.
+func (i *Indexer) indexBlockAssertions(info *rollupTypes.AssertionInfo, overwrite bool) error {
+	batch := i.Eth.ChainDb().NewBatch()
+	for number := info.StartBlock; number <= info.EndBlock; number++ {
+		if !overwrite && rollupRawdb.ReadBlockAssertionID(i.Eth.ChainDb(), number) != nil {
+			continue
+		}
+		rollupRawdb.WriteBlockAssertionID(batch, number, info.ID)
+	}
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("Failed to write block assertion index, err: %w", err)
+	}
+	return nil
+}
+
+// Gets the indexed record of an assertion, or nil if not indexed.
+func (i *Indexer) AssertionInfo(assertionID *big.Int) *rollupTypes.AssertionInfo {
+	return rollupRawdb.ReadAssertionInfo(i.Eth.ChainDb(), assertionID)
+}
+
+// Gets the indexed record of the assertion covering an L2 block, or nil if not indexed.
+func (i *Indexer) BlockAssertionInfo(number uint64) *rollupTypes.AssertionInfo {
+	assertionID := rollupRawdb.ReadBlockAssertionID(i.Eth.ChainDb(), number)
+	if assertionID == nil {
+		return nil
+	}
+	return i.AssertionInfo(assertionID)
 }
 
 func (i *Indexer) Start() error {
@@ -24,10 +315,20 @@ func (i *Indexer) Start() error {
 	if err != nil {
 		return err
 	}
-	i.Wg.Add(1)
-	go i.SyncLoop(ctx, i.Config.L1RollupGenesisBlock, nil)
+	i.Wg.Add(2)
+	go i.SyncLoop(ctx, i.L1SyncStart, i.newBatchCh)
+	go i.assertionLoop(ctx)
 	log.Info("Indexer started")
 	return nil
 }
 
-func (i *Indexer) APIs() []rpc.API { return []rpc.API{} }
+func (i *Indexer) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "rollup",
+			Version:   "1.0",
+			Service:   &RollupAPI{i},
+			Public:    true,
+		},
+	}
+}
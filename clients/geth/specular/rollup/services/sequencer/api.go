@@ -0,0 +1,145 @@
+package sequencer
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/specularl2/specular/clients/geth/specular/bindings"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/services"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+)
+
+// Sequencer state reported through the sequencer API.
+// Written by the service goroutines, guarded by `Sequencer.statusLock`.
+type status struct {
+	paused        bool
+	pendingBlocks int
+	sentBatches   int
+	lastBatch     *BatchStatus
+
+	queuedAssertion    *rollupTypes.Assertion
+	pendingAssertion   *rollupTypes.Assertion
+	confirmedAssertion *rollupTypes.Assertion
+}
+
+// BatchStatus describes a batch appended to the L1 sequencer inbox.
+type BatchStatus struct {
+	BatchNumber   *hexutil.Big   `json:"batchNumber"`
+	L1TxHash      common.Hash    `json:"l1TxHash"`
+	L1BlockNumber hexutil.Uint64 `json:"l1BlockNumber"`
+	StartBlock    hexutil.Uint64 `json:"startBlock"`
+	EndBlock      hexutil.Uint64 `json:"endBlock"`
+}
+
+func newBatchStatus(ev *bindings.ISequencerInboxTxBatchAppended, batch *rollupTypes.TxBatch) *BatchStatus {
+	return &BatchStatus{
+		BatchNumber:   (*hexutil.Big)(ev.BatchNumber),
+		L1TxHash:      ev.Raw.TxHash,
+		L1BlockNumber: hexutil.Uint64(ev.Raw.BlockNumber),
+		StartBlock:    hexutil.Uint64(batch.FirstBlockNumber()),
+		EndBlock:      hexutil.Uint64(batch.LastBlockNumber()),
+	}
+}
+
+// BatcherStatus describes the state of L1 batch submission.
+type BatcherStatus struct {
+	Paused bool `json:"paused"`
+	// L2 blocks not yet sent to the L1 sequencer inbox.
+	PendingBlocks hexutil.Uint64 `json:"pendingBlocks"`
+	// Batches included in the L1 sequencer inbox but not yet indexed.
+	SentBatches hexutil.Uint64 `json:"sentBatches"`
+	// Last batch of ours appended to the L1 sequencer inbox.
+	LastBatch *BatchStatus `json:"lastBatch"`
+}
+
+// AssertionsStatus describes the assertions tracked by the sequencer.
+type AssertionsStatus struct {
+	// Assertion to be created on L1 next.
+	Queued *services.RPCAssertion `json:"queued"`
+	// Assertion created on L1 and pending confirmation.
+	Pending *services.RPCAssertion `json:"pending"`
+	// Last validated assertion.
+	Confirmed *services.RPCAssertion `json:"confirmed"`
+}
+
+// SequencerAPI is the collection of sequencer admin APIs.
+type SequencerAPI struct {
+	s *Sequencer
+}
+
+// BatcherStatus returns the state of L1 batch submission.
+func (api *SequencerAPI) BatcherStatus() *BatcherStatus {
+	api.s.statusLock.RLock()
+	defer api.s.statusLock.RUnlock()
+	st := api.s.status
+	return &BatcherStatus{
+		Paused:        st.paused,
+		PendingBlocks: hexutil.Uint64(st.pendingBlocks),
+		SentBatches:   hexutil.Uint64(st.sentBatches),
+		LastBatch:     st.lastBatch,
+	}
+}
+
+// Assertions returns the queued, pending and confirmed assertions.
+func (api *SequencerAPI) Assertions() *AssertionsStatus {
+	api.s.statusLock.RLock()
+	defer api.s.statusLock.RUnlock()
+	st := api.s.status
+	return &AssertionsStatus{
+		Queued:    services.NewRPCAssertion(st.queuedAssertion),
+		Pending:   services.NewRPCAssertion(st.pendingAssertion),
+		Confirmed: services.NewRPCAssertion(st.confirmedAssertion),
+	}
+}
+
+// Challenge returns the oldest challenge in progress, or nil if there is none.
+func (api *SequencerAPI) Challenge() *services.RPCChallenge {
+	challenges := api.Challenges()
+	if len(challenges) == 0 {
+		return nil
+	}
+	return challenges[0]
+}
+
+// Challenges returns the challenges in progress, oldest first.
+func (api *SequencerAPI) Challenges() []*services.RPCChallenge {
+	infos := api.s.challenges.Challenges()
+	challenges := make([]*services.RPCChallenge, 0, len(infos))
+	for _, info := range infos {
+		challenges = append(challenges, &services.RPCChallenge{
+			ChallengeAddr: info.Address,
+			Assertion: services.NewRPCAssertion(&rollupTypes.Assertion{
+				VmHash:     info.VmHash,
+				InboxSize:  info.InboxSize,
+				StartBlock: info.StartBlock,
+				EndBlock:   info.EndBlock,
+			}),
+		})
+	}
+	return challenges
+}
+
+// PauseBatching stops sending batches to the L1 sequencer inbox.
+// L2 blocks are still produced and sent once batching is resumed.
+func (api *SequencerAPI) PauseBatching() {
+	api.s.statusLock.Lock()
+	defer api.s.statusLock.Unlock()
+	api.s.status.paused = true
+}
+
+// ResumeBatching resumes sending batches to the L1 sequencer inbox.
+func (api *SequencerAPI) ResumeBatching() {
+	api.s.statusLock.Lock()
+	defer api.s.statusLock.Unlock()
+	api.s.status.paused = false
+}
+
+// FlushBatch sends pending L2 blocks to the L1 sequencer inbox immediately,
+// even if batching is paused. Blocks exceeding the batch limits are split into
+// several batches; while paused, only the first one is sent.
+func (api *SequencerAPI) FlushBatch() {
+	select {
+	case api.s.flushCh <- struct{}{}:
+	default:
+		// A flush is already scheduled.
+	}
+}
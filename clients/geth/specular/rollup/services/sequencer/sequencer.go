@@ -4,8 +4,11 @@ import (
 	"context"
 	errors "errors"
 	"math/big"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -14,6 +17,7 @@ import (
 	"github.com/specularl2/specular/clients/geth/specular/proof"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/services"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/services/challenge"
 	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/log"
@@ -21,37 +25,58 @@ import (
 
 const timeInterval = 3 * time.Second
 
-// TODO: get rid of batcher; use engine API
-// TODO: modularize challenge
+// Time a sent batch may go without a `TxBatchAppended` event before its L1 tx is re-checked
+const batchLandingTimeout = 2 * time.Minute
 
-type challengeCtx struct {
-	challengeAddr common.Address
-	assertion     *rollupTypes.Assertion
+// Batch sent to the inbox, awaiting its `TxBatchAppended` event
+type sentBatch struct {
+	batch  *rollupTypes.TxBatch
+	sentAt time.Time
+}
+
+// Outcome of sending a batch to the inbox in the background
+type batchSendResult struct {
+	batch  *rollupTypes.TxBatch
+	result *client.TxResult
+	err    error
 }
 
-// Current Sequencer assumes no Berlin+London fork on L2
 type Sequencer struct {
 	*services.BaseService
 
-	blockCh              chan types.Blocks
-	pendingAssertionCh   chan *rollupTypes.Assertion
-	confirmedIDCh        chan *big.Int
-	challengeCh          chan *challengeCtx
-	challengeResoutionCh chan struct{}
+	blockCh            chan types.Blocks
+	pendingAssertionCh chan *rollupTypes.Assertion
+	confirmedIDCh      chan *big.Int
+	flushCh            chan struct{}
+
+	// Builds the blocks batched from the tx pool
+	engine services.ExecutionEngine
+
+	challenges *challenge.ChallengeManager
+
+	// State reported through the sequencer API
+	statusLock sync.RWMutex
+	status     status
 }
 
 func New(eth services.Backend, proofBackend proof.Backend, l1Client client.L1BridgeClient, cfg *services.Config) (*Sequencer, error) {
+	// Validators derive the coinbase of sequenced blocks from the sender of the batch tx (see `TxBatch.SetSequencer`),
+	// so blocks built with any other fee recipient would not match the derived ones.
+	if sender := l1Client.Sender(); sender != cfg.Coinbase {
+		return nil, fmt.Errorf("Coinbase %s differs from the L1 tx sender %s", cfg.Coinbase, sender)
+	}
 	base, err := services.NewBaseService(eth, proofBackend, l1Client, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to create base service, err: %w", err)
 	}
 	return &Sequencer{
-		BaseService:          base,
-		blockCh:              make(chan types.Blocks, 4096),
-		pendingAssertionCh:   make(chan *rollupTypes.Assertion, 4096),
-		confirmedIDCh:        make(chan *big.Int, 4096),
-		challengeCh:          make(chan *challengeCtx),
-		challengeResoutionCh: make(chan struct{}),
+		BaseService:        base,
+		blockCh:            make(chan types.Blocks, 4096),
+		pendingAssertionCh: make(chan *rollupTypes.Assertion, 4096),
+		confirmedIDCh:      make(chan *big.Int, 4096),
+		flushCh:            make(chan struct{}, 1),
+		engine:             services.NewLocalEngine(eth),
+		challenges:         challenge.NewChallengeManager(base, challenge.BisectionStrategy{}),
 	}, nil
 }
 
@@ -82,37 +107,45 @@ func (s *Sequencer) modifyTxnsInBatch(ctx context.Context, batchTxs []*types.Tra
 	return batchTxs, nil
 }
 
-// Add sorted txs to batch and commit txs
-func (s *Sequencer) addTxsToBatchAndCommit(
+// Add sorted txs to batch
+func (s *Sequencer) addTxsToBatch(
 	ctx context.Context,
-	batcher *Batcher,
 	txs *types.TransactionsByPriceAndNonce,
 	batchTxs []*types.Transaction,
-	signer types.Signer,
 ) ([]*types.Transaction, error) {
-	if txs != nil {
-		for {
-			tx := txs.Peek()
-			if tx == nil {
-				break
-			}
-			var err error
-			batchTxs, err = s.modifyTxnsInBatch(ctx, batchTxs, tx)
-			if err != nil {
-				return nil, fmt.Errorf("Modifying batch failed, err: %w", err)
-			}
-			txs.Pop()
+	for {
+		tx := txs.Peek()
+		if tx == nil {
+			break
 		}
+		var err error
+		batchTxs, err = s.modifyTxnsInBatch(ctx, batchTxs, tx)
+		if err != nil {
+			return nil, fmt.Errorf("Modifying batch failed, err: %w", err)
+		}
+		txs.Pop()
 	}
-	if len(batchTxs) == 0 {
-		return batchTxs, nil
+	return batchTxs, nil
+}
+
+// Builds blocks from the batch through the execution engine, until all txs are consumed
+func (s *Sequencer) buildBlocks(batchTxs []*types.Transaction) (types.Blocks, error) {
+	var blocks types.Blocks
+	attrs := &services.PayloadAttributes{
+		Timestamp:    uint64(time.Now().Unix()),
+		FeeRecipient: s.Config.Coinbase,
+		Txs:          batchTxs,
 	}
-	err := batcher.CommitTransactions(batchTxs)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to commit transactions, err: %w", err)
+	for len(attrs.Txs) > 0 {
+		payload, err := s.engine.BuildPayload(attrs)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to build payload, err: %w", err)
+		}
+		blocks = append(blocks, payload.Block)
+		attrs.Txs = payload.Remaining
 	}
-	log.Info("Committed tx batch", "batch size", len(batchTxs))
-	return batchTxs, nil
+	log.Info("Built blocks", "batch size", len(batchTxs), "#blocks", len(blocks))
+	return blocks, nil
 }
 
 // This goroutine fetches txs from txpool and batches them
@@ -129,13 +162,24 @@ func (s *Sequencer) batchingLoop(ctx context.Context) {
 	txsSub := s.Eth.TxPool().SubscribeNewTxsEvent(txsCh)
 	defer txsSub.Unsubscribe()
 
-	// Process txns via batcher
-	batcher, err := NewBatcher(s.Config.Coinbase, s.Eth)
-	if err != nil {
-		log.Crit("Failed to start batcher", "err", err)
+	chainConfig := s.Chain().Config()
+	// Signer and base fee of the next block
+	nextBlock := func() (types.Signer, *big.Int) {
+		head := s.Chain().CurrentBlock().Header()
+		next := new(big.Int).Add(head.Number, common.Big1)
+		return types.MakeSigner(chainConfig, next), services.NextBaseFee(chainConfig, head)
 	}
 
-	var batchTxs []*types.Transaction
+	var (
+		batchTxs []*types.Transaction
+		err      error
+	)
+	// Txs of the blocks rewound on startup were never sequenced to L1, queue them first.
+	if len(s.RewoundTxs) > 0 {
+		log.Info("Re-queued txs of rewound blocks", "txs", len(s.RewoundTxs))
+		batchTxs = append(batchTxs, s.RewoundTxs...)
+		s.RewoundTxs = nil
+	}
 
 	// Loop over txns
 	for {
@@ -143,7 +187,7 @@ func (s *Sequencer) batchingLoop(ctx context.Context) {
 		case <-ticker.C:
 			// Get pending txs - locals and remotes, sorted by price
 			var txs []*types.Transaction
-			signer := types.MakeSigner(batcher.chainConfig, batcher.header.Number)
+			signer, baseFee := nextBlock()
 
 			pending := s.Eth.TxPool().Pending(true)
 			localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
@@ -154,23 +198,23 @@ func (s *Sequencer) batchingLoop(ctx context.Context) {
 				}
 			}
 			if len(localTxs) > 0 {
-				sortedTxs := types.NewTransactionsByPriceAndNonce(signer, localTxs, batcher.header.BaseFee)
-				batchTxs, err = s.addTxsToBatchAndCommit(ctx, batcher, sortedTxs, batchTxs, signer)
+				sortedTxs := types.NewTransactionsByPriceAndNonce(signer, localTxs, baseFee)
+				batchTxs, err = s.addTxsToBatch(ctx, sortedTxs, batchTxs)
 				if err != nil {
 					log.Crit("Failed to process local txs", "err", err)
 				}
 			}
 			if len(remoteTxs) > 0 {
-				sortedTxs := types.NewTransactionsByPriceAndNonce(signer, remoteTxs, batcher.header.BaseFee)
-				batchTxs, err = s.addTxsToBatchAndCommit(ctx, batcher, sortedTxs, batchTxs, signer)
+				sortedTxs := types.NewTransactionsByPriceAndNonce(signer, remoteTxs, baseFee)
+				batchTxs, err = s.addTxsToBatch(ctx, sortedTxs, batchTxs)
 				if err != nil {
 					log.Crit("Failed to process remote txs", "err", err)
 				}
 			}
 			if len(batchTxs) > 0 {
-				blocks, err := batcher.Batch()
+				blocks, err := s.buildBlocks(batchTxs)
 				if err != nil {
-					log.Crit("Failed to send transaction to batch", "err", err)
+					log.Crit("Failed to build blocks", "err", err)
 				}
 				s.blockCh <- blocks
 			}
@@ -179,13 +223,13 @@ func (s *Sequencer) batchingLoop(ctx context.Context) {
 			// Batch txs in case of txEvent
 			log.Info("Received txsCh event", "txs", len(ev.Txs))
 			txs := make(map[common.Address]types.Transactions)
-			signer := types.MakeSigner(batcher.chainConfig, batcher.header.Number)
+			signer, baseFee := nextBlock()
 			for _, tx := range ev.Txs {
 				acc, _ := types.Sender(signer, tx)
 				txs[acc] = append(txs[acc], tx)
 			}
-			sortedTxs := types.NewTransactionsByPriceAndNonce(signer, txs, batcher.header.BaseFee)
-			batchTxs, err = s.addTxsToBatchAndCommit(ctx, batcher, sortedTxs, batchTxs, signer)
+			sortedTxs := types.NewTransactionsByPriceAndNonce(signer, txs, baseFee)
+			batchTxs, err = s.addTxsToBatch(ctx, sortedTxs, batchTxs)
 			if err != nil {
 				log.Crit("Failed to process txsCh event ", "err", err)
 			}
@@ -210,6 +254,18 @@ func (s *Sequencer) sequencingLoop(ctx context.Context) {
 		s.L1Client.FilterAssertionCreated,
 		s.L1Syncer.Latest.Number.Uint64(),
 	)
+	// Watch TxBatchAppended event
+	batchCtx, cancelBatchSub := context.WithCancel(ctx)
+	defer func() { cancelBatchSub() }()
+	batchAppendedCh := client.SubscribeHeaderMapped[*bindings.ISequencerInboxTxBatchAppended](
+		batchCtx,
+		s.L1Syncer.LatestHeaderBroker,
+		s.L1Client.FilterTxBatchAppendedEvents,
+		s.L1Syncer.Latest.Number.Uint64(),
+	)
+	// Watch L1 reorgs, which may drop sequenced batches
+	reorgCh := s.L1Syncer.ReorgBroker.Subscribe()
+	defer s.L1Syncer.ReorgBroker.Unsubscribe(reorgCh)
 
 	// Last validated assertion, initalize it to genesis
 	// TODO: change name to lastValidatedAssertion since "confirmed" may imply L1-confirmed.
@@ -222,67 +278,274 @@ func (s *Sequencer) sequencingLoop(ctx context.Context) {
 	// Assertion to be created on L1 Rollup
 	queuedAssertion := confirmedAssertion.Copy()
 
-	// Create assertion on L1 Rollup
+	// Set if the pending assertion failed to be created, to commit it again on the next tick
+	retryAssertion := false
+	// Outcome of creating the pending assertion.
+	// Buffered, so that the sending goroutine never blocks after the loop returns.
+	assertionSentCh := make(chan error, 1)
+
+	// Create assertion on L1 Rollup, in the background (sends block until final).
+	// The assertion is picked up from its `AssertionCreated` event.
 	commitAssertion := func() {
 		pendingAssertion = queuedAssertion.Copy()
 		queuedAssertion.StartBlock = queuedAssertion.EndBlock + 1
-		_, err = s.L1Client.CreateAssertion(pendingAssertion.VmHash, pendingAssertion.InboxSize)
-		if errors.Is(err, core.ErrInsufficientFunds) {
-			log.Crit("Insufficient Funds to send Tx", "error", err)
-		}
-		if err != nil {
-			log.Error("Can not create DA", "error", err)
+		retryAssertion = false
+		assertion := pendingAssertion.Copy()
+		s.Wg.Add(1)
+		go func() {
+			defer s.Wg.Done()
+			_, err := s.L1Client.CreateAssertion(assertion.VmHash, assertion.InboxSize)
+			if err == nil {
+				log.Info(
+					"Created assertion",
+					"vmHash", assertion.VmHash,
+					"start block", assertion.StartBlock,
+					"end block", assertion.EndBlock,
+				)
+			}
+			assertionSentCh <- err
+		}()
+	}
+
+	// Blocks sequenced before a restart are not covered by any assertion yet; queue them.
+	if head := s.Chain().CurrentBlock().NumberU64(); head > queuedAssertion.EndBlock {
+		for i := queuedAssertion.EndBlock + 1; i <= head; i++ {
+			block := s.Chain().GetBlockByNumber(i)
+			queuedAssertion.InboxSize.Add(queuedAssertion.InboxSize, new(big.Int).SetUint64(s.NumInboxTxs(block)))
+			queuedAssertion.VmHash = block.Root()
 		}
-		log.Info(
-			"Created assertion",
-			"id", pendingAssertion.ID,
-			"vmHash", pendingAssertion.VmHash,
-			"start block", pendingAssertion.StartBlock,
-			"end block", pendingAssertion.EndBlock,
-		)
+		queuedAssertion.EndBlock = head
+		log.Info("Queued blocks sequenced before restart", "start block", queuedAssertion.StartBlock, "end block", head)
+		commitAssertion()
 	}
 
 	// Blocks from the batchingLoop that will be sent to the inbox in the next tick
 	var batchBlocks types.Blocks
+	// Batches split from `batchBlocks`, to be sent to the inbox in order
+	var batchQueue []*rollupTypes.TxBatch
+	// Batches sent to the inbox but not yet seen in a `TxBatchAppended` event, by L1 tx hash.
+	// Only one batch is in flight at a time, so that batches land in order.
+	sentBatches := make(map[common.Hash]*sentBatch)
+	// Set while a batch is being sent in the background, until its result is received on `batchSentCh`
+	sendingBatch := false
+	// Buffered, so that the sending goroutine never blocks after the loop returns
+	batchSentCh := make(chan *batchSendResult, 1)
+	// `TxBatchAppended` events received while a batch is being sent, by L1 tx hash:
+	// the batch lands before its tx reaches the required number of confirmations.
+	earlyBatchEvents := make(map[common.Hash]*bindings.ISequencerInboxTxBatchAppended)
+
+	// Sends the next queued batch to the inbox in the background, if none is in flight.
+	// Not tracked by `Wg`: L1 txs are sent with the L1 client's own context, so stopping does not abort them.
+	sendNextBatch := func() {
+		if sendingBatch || len(sentBatches) > 0 || len(batchQueue) == 0 {
+			return
+		}
+		batch := batchQueue[0]
+		contexts, txLengths, txs, err := batch.SerializeToArgs()
+		if err != nil {
+			log.Error("Can not serialize batch", "error", err)
+			return
+		}
+		batchQueue = batchQueue[1:]
+		sendingBatch = true
+		go func() {
+			result, err := s.L1Client.AppendTxBatch(contexts, txLengths, txs)
+			batchSentCh <- &batchSendResult{batch, result, err}
+		}()
+	}
+	// Splits pending blocks into batches and sends the first one
+	appendBatches := func() {
+		if len(batchBlocks) > 0 {
+			batches, err := rollupTypes.NewTxBatches(batchBlocks, s.Config.MaxBatchSize, s.Config.MaxBatchGas)
+			if err != nil {
+				log.Error("Can not create batches", "error", err)
+				return
+			}
+			if len(batches) > 1 {
+				log.Info("Split pending blocks into batches", "blocks", len(batchBlocks), "batches", len(batches))
+			}
+			batchQueue = append(batchQueue, batches...)
+			batchBlocks = nil
+		}
+		sendNextBatch()
+	}
+	// Re-filters `TxBatchAppended` events from L1 block `start`
+	resubscribeBatches := func(start uint64) {
+		cancelBatchSub()
+		batchCtx, cancelBatchSub = context.WithCancel(ctx)
+		batchAppendedCh = client.SubscribeHeaderMapped[*bindings.ISequencerInboxTxBatchAppended](
+			batchCtx, s.L1Syncer.LatestHeaderBroker, s.L1Client.FilterTxBatchAppendedEvents, start,
+		)
+	}
+	// Re-checks the L1 txs of batches in flight for longer than `batchLandingTimeout`.
+	// Batches whose tx is no longer included (e.g. dropped by an L1 reorg) are re-sent;
+	// otherwise the event was missed, and is re-filtered from the tx's block.
+	recheckSentBatches := func() {
+		var dropped []*rollupTypes.TxBatch
+		for txHash, sent := range sentBatches {
+			if time.Since(sent.sentAt) < batchLandingTimeout {
+				continue
+			}
+			receipt, err := s.L1Client.TransactionReceipt(ctx, txHash)
+			if err != nil && !errors.Is(err, ethereum.NotFound) {
+				log.Warn("Can not get batch receipt", "tx", txHash, "error", err)
+				continue
+			}
+			if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+				log.Warn("Batch tx not included, re-sending", "tx", txHash, "start block", sent.batch.FirstBlockNumber())
+				delete(sentBatches, txHash)
+				dropped = append(dropped, sent.batch)
+				continue
+			}
+			log.Warn("Batch event not seen, re-filtering", "tx", txHash, "l1 block", receipt.BlockNumber)
+			sent.sentAt = time.Now()
+			resubscribeBatches(receipt.BlockNumber.Uint64())
+		}
+		sort.Slice(dropped, func(i, j int) bool { return dropped[i].FirstBlockNumber() < dropped[j].FirstBlockNumber() })
+		batchQueue = append(dropped, batchQueue...)
+	}
+	// Reorg that failed to be handled, retried on the next tick
+	var pendingReorg *client.L1Reorg
+	// Batches sequenced in reorged-out L1 blocks are in flight again, until seen on the new chain
+	handleReorg := func(reorg *client.L1Reorg) {
+		pendingReorg = client.DeeperReorg(pendingReorg, reorg)
+		if pendingReorg == nil {
+			return
+		}
+		ancestor, err := s.ReorgAncestor(ctx, pendingReorg)
+		if err != nil {
+			log.Error("Failed to handle L1 reorg, retrying", "err", err)
+			return
+		}
+		ranges, origins := s.BatchesAfterL1Block(ancestor)
+		batches := make([]*rollupTypes.TxBatch, len(ranges))
+		for i, blockRange := range ranges {
+			batches[i], err = s.localBatch(blockRange)
+			if err != nil {
+				log.Error("Failed to rebuild reorged-out batch, retrying", "batch", origins[i].BatchNumber, "err", err)
+				return
+			}
+		}
+		if err := s.UnindexAfterL1Block(ancestor); err != nil {
+			log.Error("Failed to unindex batches after L1 reorg, retrying", "err", err)
+			return
+		}
+		pendingReorg = nil
+		for i, batch := range batches {
+			sentBatches[origins[i].L1TxHash] = &sentBatch{batch, time.Now()}
+		}
+		log.Warn("Batches reorged out of L1", "common ancestor", ancestor, "batches", len(ranges))
+		// Re-filter events from the first L1 block after the common ancestor.
+		resubscribeBatches(ancestor + 1)
+	}
+	// Indexes the blocks of a sent batch appended to the L1 inbox, and queues them for assertion
+	onBatchAppended := func(ev *bindings.ISequencerInboxTxBatchAppended) {
+		sent, ok := sentBatches[ev.Raw.TxHash]
+		if !ok {
+			return
+		}
+		batch := sent.batch
+		delete(sentBatches, ev.Raw.TxHash)
+		s.IndexBatch(ev, batch.FirstBlockNumber(), batch.LastBlockNumber())
+		s.statusLock.Lock()
+		s.status.lastBatch = newBatchStatus(ev, batch)
+		s.statusLock.Unlock()
+		log.Info("Indexed sequenced batch", "batch", ev.BatchNumber, "l1 block", ev.Raw.BlockNumber)
+		// Update queued assertion to the landed batch (which may land again after an L1 reorg)
+		queuedAssertion.VmHash = batch.LastBlockRoot()
+		queuedAssertion.InboxSize = new(big.Int).Set(ev.EndTxNumber)
+		queuedAssertion.EndBlock = batch.LastBlockNumber()
+		// If no assertion is pending, commit it
+		if pendingAssertion == nil {
+			commitAssertion()
+		}
+		// Send the next batch, unless paused
+		if !s.batchingPaused() {
+			sendNextBatch()
+		}
+	}
+	// Number of blocks not yet sent to the inbox
+	numPendingBlocks := func() int {
+		n := len(batchBlocks)
+		for _, batch := range batchQueue {
+			n += len(batch.Blocks)
+		}
+		return n
+	}
 
 	for {
+		numSent := len(sentBatches)
+		if sendingBatch {
+			numSent++
+		}
+		s.reportSequencingStatus(numPendingBlocks(), numSent, queuedAssertion, pendingAssertion, confirmedAssertion)
 		select {
 		case <-ticker.C:
-			if len(batchBlocks) == 0 {
-				continue
+			handleReorg(nil)
+			if retryAssertion && pendingAssertion == nil {
+				commitAssertion()
 			}
-			batch := rollupTypes.NewTxBatch(batchBlocks, 0) // TODO: handle max batch size
-			contexts, txLengths, txs, err := batch.SerializeToArgs()
-			if err != nil {
-				log.Error("Can not serialize batch", "error", err)
+			recheckSentBatches()
+			if s.batchingPaused() {
 				continue
 			}
-			_, err = s.L1Client.AppendTxBatch(contexts, txLengths, txs)
+			appendBatches()
+		case <-s.flushCh:
+			log.Info("Flushing pending blocks", "blocks", numPendingBlocks())
+			appendBatches()
+		case blocks := <-s.blockCh:
+			// Add blocks
+			batchBlocks = append(batchBlocks, blocks...)
+		case reorg := <-reorgCh:
+			handleReorg(reorg)
+		case err := <-assertionSentCh:
 			if errors.Is(err, core.ErrInsufficientFunds) {
 				log.Crit("Insufficient Funds to send Tx", "error", err)
 			}
 			if err != nil {
-				log.Error("Can not sequence batch", "error", err)
+				// Retried on the next tick, together with the blocks queued meanwhile.
+				log.Error("Can not create assertion", "error", err)
+				queuedAssertion.StartBlock = pendingAssertion.StartBlock
+				pendingAssertion = nil
+				retryAssertion = true
+			}
+		case sent := <-batchSentCh:
+			sendingBatch = false
+			early := earlyBatchEvents
+			earlyBatchEvents = make(map[common.Hash]*bindings.ISequencerInboxTxBatchAppended)
+			if errors.Is(sent.err, core.ErrInsufficientFunds) {
+				log.Crit("Insufficient Funds to send Tx", "error", sent.err)
+			}
+			if sent.err != nil {
+				// Retried on the next tick.
+				log.Error("Can not sequence batch", "error", sent.err)
+				batchQueue = append([]*rollupTypes.TxBatch{sent.batch}, batchQueue...)
 				continue
 			}
-			log.Info("Sequenced batch", "batch size", len(batch.Txs))
-			// Update queued assertion to latest batch
-			// queuedAssertion.ID.Add(queuedAssertion.ID, big.NewInt(1))
-			queuedAssertion.VmHash = batch.LastBlockRoot()
-			queuedAssertion.InboxSize.Add(queuedAssertion.InboxSize, batch.InboxSize())
-			queuedAssertion.EndBlock = batch.LastBlockNumber()
-			// If no assertion is pending, commit it
-			if pendingAssertion == nil {
-				commitAssertion()
+			batch := sent.batch
+			txHash := sent.result.Tx.Hash()
+			sentBatches[txHash] = &sentBatch{batch, time.Now()}
+			log.Info(
+				"Sequenced batch",
+				"batch size", len(batch.Txs),
+				"start block", batch.FirstBlockNumber(),
+				"end block", batch.LastBlockNumber(),
+				"calldata size", batch.Size(),
+				"estimated gas", batch.L1Gas(),
+			)
+			if ev, ok := early[txHash]; ok {
+				onBatchAppended(ev)
 			}
-			batchBlocks = nil
-		case blocks := <-s.blockCh:
-			// Add blocks
-			batchBlocks = append(batchBlocks, blocks...)
+		case ev := <-batchAppendedCh:
+			if _, ok := sentBatches[ev.Raw.TxHash]; !ok && sendingBatch {
+				earlyBatchEvents[ev.Raw.TxHash] = ev
+				continue
+			}
+			onBatchAppended(ev)
 		case ev := <-createdCh:
 			// New assertion created on L1 Rollup
 			log.Info("Received `AssertionCreated` event.", "assertion id", ev.AssertionID)
-			if common.Address(ev.AsserterAddr) == s.Config.Coinbase {
+			if common.Address(ev.AsserterAddr) == s.Config.Coinbase && pendingAssertion != nil {
 				if ev.VmHash == pendingAssertion.VmHash {
 					// If assertion is created by us, get ID and deadline
 					pendingAssertion.ID = ev.AssertionID
@@ -292,6 +555,7 @@ func (s *Sequencer) sequencingLoop(ctx context.Context) {
 						continue
 					}
 					pendingAssertion.Deadline = assertionFromRollup.Deadline
+					s.IndexAssertion(pendingAssertion)
 					// Send to confirmation goroutine to confirm it
 					s.pendingAssertionCh <- pendingAssertion
 				}
@@ -319,6 +583,19 @@ func (s *Sequencer) sequencingLoop(ctx context.Context) {
 	}
 }
 
+// Rebuilds the batch of local blocks in `blockRange`
+func (s *Sequencer) localBatch(blockRange *rollupTypes.BlockRange) (*rollupTypes.TxBatch, error) {
+	var blocks []*types.Block
+	for number := blockRange.StartBlock; number <= blockRange.EndBlock; number++ {
+		block := s.Chain().GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("Missing local block #%d", number)
+		}
+		blocks = append(blocks, block)
+	}
+	return rollupTypes.NewTxBatch(blocks)
+}
+
 // This goroutine tries to confirm created assertions
 func (s *Sequencer) confirmationLoop(ctx context.Context) {
 	defer s.Wg.Done()
@@ -336,6 +613,9 @@ func (s *Sequencer) confirmationLoop(ctx context.Context) {
 	var pendingAssertion *rollupTypes.Assertion
 	pendingConfirmationSent := true
 	pendingConfirmed := true
+	// Outcome of confirming the pending assertion.
+	// Buffered, so that the sending goroutine never blocks after the loop returns.
+	confirmationSentCh := make(chan error, 1)
 
 	for {
 		select {
@@ -345,19 +625,25 @@ func (s *Sequencer) confirmationLoop(ctx context.Context) {
 			if !pendingConfirmationSent && !pendingConfirmed {
 				if header.Number.Uint64() >= pendingAssertion.Deadline.Uint64() {
 					log.Info("We can now confirm", "pending assertion", pendingAssertion.Deadline.Uint64())
-					// Confirmation period has past, confirm it
-					_, err := s.L1Client.ConfirmFirstUnresolvedAssertion()
-					if errors.Is(err, core.ErrInsufficientFunds) {
-						log.Crit("Insufficient Funds to send Tx", "error", err)
-					}
-					if err != nil {
-						// log.Error("Failed to confirm DA", "error", err)
-						log.Crit("Failed to confirm DA", "err", err)
-						// TODO: wait some time before retry
-					}
+					// Confirmation period has past, confirm it in the background (sends block until final)
 					pendingConfirmationSent = true
+					s.Wg.Add(1)
+					go func() {
+						defer s.Wg.Done()
+						_, err := s.L1Client.ConfirmFirstUnresolvedAssertion()
+						confirmationSentCh <- err
+					}()
 				}
 			}
+		case err := <-confirmationSentCh:
+			if errors.Is(err, core.ErrInsufficientFunds) {
+				log.Crit("Insufficient Funds to send Tx", "error", err)
+			}
+			if err != nil {
+				// Retried on the next header.
+				log.Error("Failed to confirm DA", "err", err)
+				pendingConfirmationSent = false
+			}
 		case ev := <-confirmedCh:
 			log.Info("Received `AssertionConfirmed` event ", "assertion id", ev.AssertionID)
 			// New confirmed assertion
@@ -381,144 +667,29 @@ func (s *Sequencer) confirmationLoop(ctx context.Context) {
 			// New challenge raised
 			log.Info("Received `AssertionChallenged` event ", "assertion id", ev.AssertionID)
 			if ev.AssertionID.Cmp(pendingAssertion.ID) == 0 {
-				s.challengeCh <- &challengeCtx{
-					ev.ChallengeAddr,
-					pendingAssertion,
-				}
-				wait(ctx, s.challengeResoutionCh, "challenge resolution")
-			}
-		case <-ctx.Done():
-			log.Info("Aborting.")
-			return
-		}
-	}
-}
-
-func wait(ctx context.Context, ch <-chan struct{}, taskName string) {
-	log.Info("Waiting for %s...", taskName)
-	select {
-	case <-ch:
-		log.Info("%s notification received", taskName)
-		return
-	case <-ctx.Done():
-		log.Info("Aborting wait for %s", taskName)
-		return
-	}
-}
-
-func (s *Sequencer) challengeLoop(ctx context.Context) {
-	defer s.Wg.Done()
-	// Watch L1 blockchain for challenge timeout
-	headCh := make(chan *types.Header, 4096)
-	headSub, err := s.L1Client.ResubscribeErrNewHead(ctx, headCh)
-	if err != nil {
-		log.Crit("Failed to watch l1 chain head", "err", err)
-	}
-	defer headSub.Unsubscribe()
-	for {
-		select {
-		case chalCtx := <-s.challengeCh:
-			err := s.handleChallenge(ctx, chalCtx, headCh)
-			if err != nil {
-				log.Crit("Failed to handle challenge", "err", err)
-			}
-		case <-headCh:
-			continue // consume channel values
-		case <-ctx.Done():
-			log.Info("Aborting.")
-			return
-		}
-	}
-}
-
-func (s *Sequencer) handleChallenge(
-	ctx context.Context,
-	chalCtx *challengeCtx,
-	headCh chan *types.Header,
-) error {
-	err := s.L1Client.InitNewChallengeSession(ctx, chalCtx.challengeAddr)
-	if err != nil {
-		return fmt.Errorf("Failed to access ongoing challenge (address=%s), err: %w", chalCtx.challengeAddr, err)
-	}
-	states, err := proof.GenerateStates(
-		s.ProofBackend,
-		ctx,
-		chalCtx.assertion.StartBlock,
-		chalCtx.assertion.EndBlock+1,
-		nil,
-	)
-	if err != nil {
-		return fmt.Errorf("Failed to generate states, err: %w", err)
-	}
-	_, err = s.L1Client.InitializeChallengeLength(new(big.Int).SetUint64(uint64(len(states)) - 1))
-	if err != nil {
-		return fmt.Errorf("Failed to initialize challenge, err: %w", err)
-	}
-
-	subCtx, subCancel := context.WithCancel(ctx)
-	defer subCancel()
-	bisectedCh := client.SubscribeHeaderMapped[*bindings.ISymChallengeBisected](
-		subCtx, s.L1Syncer.LatestHeaderBroker, s.L1Client.FilterBisected, s.L1Syncer.Latest.Number.Uint64(),
-	)
-	challengeCompletedCh := client.SubscribeHeaderMapped[*bindings.ISymChallengeCompleted](
-		subCtx, s.L1Syncer.LatestHeaderBroker, s.L1Client.FilterChallengeCompleted, s.L1Syncer.Latest.Number.Uint64(),
-	)
-	log.Info("to generate state from", "start", chalCtx.assertion.StartBlock, "to", chalCtx.assertion.EndBlock)
-	log.Info("backend", "start", chalCtx.assertion.StartBlock, "to", chalCtx.assertion.EndBlock)
-	var opponentTimeoutBlock uint64
-	for {
-		select {
-		case ev := <-bisectedCh:
-			// case get bisection, if is our turn
-			//   if in single step, submit proof
-			//   if multiple step, track current segment, update
-			responder, err := s.L1Client.CurrentChallengeResponder()
-			if err != nil {
-				// TODO: error handling
-				log.Error("Cannot get current responder", "error", err)
-				continue
-			}
-			if responder == common.Address(s.Config.Coinbase) {
-				// If it's our turn
-				err := services.RespondBisection(ctx, s.ProofBackend, s.L1Client, ev, states, common.Hash{}, false)
-				if err != nil {
-					// TODO: error handling
-					log.Error("Cannot respond to bisection", "error", err)
-					continue
-				}
-			} else {
-				opponentTimeLeft, err := s.L1Client.CurrentChallengeResponderTimeLeft()
+				// Played in the background, so that assertions keep being confirmed meanwhile
+				err := s.challenges.StartChallenge(ctx, &rollupTypes.ChallengeInfo{
+					Address:       ev.ChallengeAddr,
+					L1BlockNumber: ev.Raw.BlockNumber,
+					StartBlock:    pendingAssertion.StartBlock,
+					EndBlock:      pendingAssertion.EndBlock,
+					InboxSize:     pendingAssertion.InboxSize,
+					VmHash:        pendingAssertion.VmHash,
+				})
 				if err != nil {
-					// TODO: error handling
-					log.Error("Cannot get current responder left time", "error", err)
-					continue
+					log.Error("Failed to start challenge", "address", ev.ChallengeAddr, "err", err)
 				}
-				log.Info("Opponent time left", "time", opponentTimeLeft)
-				opponentTimeoutBlock = ev.Raw.BlockNumber + opponentTimeLeft.Uint64()
 			}
-		case header := <-headCh:
-			if opponentTimeoutBlock == 0 {
+		case result := <-s.challenges.Results():
+			if result.Err != nil {
+				log.Error("Challenge could not be played", "address", result.Info.Address, "err", result.Err)
 				continue
 			}
-			// TODO: can we use >= here?
-			if header.Number.Uint64() > opponentTimeoutBlock {
-				_, err := s.L1Client.TimeoutChallenge()
-				if err != nil {
-					log.Error("Cannot timeout opponent", "error", err)
-					continue
-					// TODO: wait some time before retry
-					// TODO: fix race condition
-				}
-			}
-		case ev := <-challengeCompletedCh:
 			// TODO: handle if we are not winner --> state corrupted
-			log.Info("Challenge completed", "winner", ev.Winner)
-			states = []*proof.ExecutionState{}
-			s.challengeResoutionCh <- struct{}{}
-			return nil
+			log.Info("Challenge finished", "address", result.Info.Address, "winner", result.Winner)
 		case <-ctx.Done():
 			log.Info("Aborting.")
-			return nil
+			return
 		}
 	}
 }
@@ -532,22 +703,61 @@ func (s *Sequencer) Start() error {
 	if err := s.Stake(ctx); err != nil {
 		return fmt.Errorf("Failed to start sequencer: %w", err)
 	}
-	_, err = s.SyncL2ChainToL1Head(ctx, s.Config.L1RollupGenesisBlock)
+	_, err = s.SyncL2ChainToL1Head(ctx, s.L1SyncStart)
 	if err != nil {
 		return fmt.Errorf("Failed to start sequencer: %w", err)
 	}
+	// Resume the challenges we were taking part in before a restart
+	if _, err := s.challenges.ResumeChallenges(ctx); err != nil {
+		return fmt.Errorf("Failed to start sequencer: %w", err)
+	}
 	// We assume a single sequencer (us) for now, so we don't
 	// need to sync transactions sequenced up.
-	s.Wg.Add(4)
+	s.Wg.Add(3)
 	go s.batchingLoop(ctx)
 	go s.sequencingLoop(ctx)
 	go s.confirmationLoop(ctx)
-	go s.challengeLoop(ctx)
 	log.Info("Sequencer started")
 	return nil
 }
 
+// Reports the state held by the sequencing goroutine.
+func (s *Sequencer) reportSequencingStatus(
+	pendingBlocks int,
+	sentBatches int,
+	queuedAssertion *rollupTypes.Assertion,
+	pendingAssertion *rollupTypes.Assertion,
+	confirmedAssertion *rollupTypes.Assertion,
+) {
+	s.statusLock.Lock()
+	defer s.statusLock.Unlock()
+	s.status.pendingBlocks = pendingBlocks
+	s.status.sentBatches = sentBatches
+	s.status.queuedAssertion = copyAssertion(queuedAssertion)
+	s.status.pendingAssertion = copyAssertion(pendingAssertion)
+	s.status.confirmedAssertion = copyAssertion(confirmedAssertion)
+}
+
+func (s *Sequencer) batchingPaused() bool {
+	s.statusLock.RLock()
+	defer s.statusLock.RUnlock()
+	return s.status.paused
+}
+
+func copyAssertion(assertion *rollupTypes.Assertion) *rollupTypes.Assertion {
+	if assertion == nil {
+		return nil
+	}
+	return assertion.Copy()
+}
+
 func (s *Sequencer) APIs() []rpc.API {
-	// TODO: sequencer APIs
-	return []rpc.API{}
+	return []rpc.API{
+		{
+			Namespace: "sequencer",
+			Version:   "1.0",
+			Service:   &SequencerAPI{s},
+			Public:    false,
+		},
+	}
 }
@@ -6,10 +6,12 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/specularl2/specular/clients/geth/specular/bindings"
 	"github.com/specularl2/specular/clients/geth/specular/proof"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
 	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
 )
 
 func NewAssertionFrom(
@@ -33,42 +35,104 @@ func LogBlockChainInfo(backend Backend, start, end uint64) {
 	}
 }
 
+// TxInclusionProver proves the inclusion of L2 txs in the sequencer inbox.
+type TxInclusionProver interface {
+	TxInclusionProof(state *proof.ExecutionState) (*proof.TxInclusionProof, error)
+}
+
+// Builds the one-step proof of the step from `state`, with the verification context and the inclusion proof
+// of the transaction it executes.
+func buildOneStepProof(
+	ctx context.Context,
+	proofBackend proof.Backend,
+	inclusionProver TxInclusionProver,
+	state *proof.ExecutionState,
+) ([]byte, []byte, bindings.VerificationContextLibRawContext, error) {
+	osp, err := proof.GenerateProof(proofBackend, ctx, state, nil)
+	if err != nil {
+		return nil, nil, bindings.VerificationContextLibRawContext{}, fmt.Errorf("Failed to generate one-step proof, err: %w", err)
+	}
+	txState, err := contextState(ctx, proofBackend, state)
+	if err != nil {
+		return nil, nil, bindings.VerificationContextLibRawContext{}, fmt.Errorf("Failed to find transaction of step, err: %w", err)
+	}
+	rawCtx, err := proof.NewRawContext(txState)
+	if err != nil {
+		return nil, nil, rawCtx, fmt.Errorf("Failed to build verification context, err: %w", err)
+	}
+	inclusionProof, err := inclusionProver.TxInclusionProof(txState)
+	if err != nil {
+		return nil, nil, rawCtx, fmt.Errorf("Failed to build tx inclusion proof, err: %w", err)
+	}
+	return osp.Encode(), inclusionProof.Encode(), rawCtx, nil
+}
+
 func SubmitOneStepProof(
 	ctx context.Context,
 	proofBackend proof.Backend,
-	l1Client client.L1BridgeClient,
+	chalClient client.SymChallengeClient,
+	inclusionProver TxInclusionProver,
 	state *proof.ExecutionState,
 	challengedStepIndex *big.Int,
 	prevBisection [][32]byte,
 	prevChallengedSegmentStart *big.Int,
 	prevChallengedSegmentLength *big.Int,
 ) error {
-	osp, err := proof.GenerateProof(proofBackend, ctx, state, nil)
+	osp, inclusionProof, rawCtx, err := buildOneStepProof(ctx, proofBackend, inclusionProver, state)
 	if err != nil {
-		log.Crit("UNHANDLED: osp generation failed", "err", err)
+		return err
 	}
-	_, err = l1Client.VerifyOneStepProof(
-		osp.Encode(),
-		[]byte{}, // TODO: fix
-		bindings.VerificationContextLibRawContext{}, // TODO: fix
+	_, err = chalClient.VerifyOneStepProof(
+		osp,
+		inclusionProof,
+		rawCtx,
 		challengedStepIndex,
 		prevBisection,
 		prevChallengedSegmentStart,
 		prevChallengedSegmentLength,
 	)
-	log.Info("OSP submitted")
 	if err != nil {
-		log.Error("OSP verification failed")
+		return fmt.Errorf("OSP verification failed, err: %w", err)
+	}
+	log.Info("OSP submitted")
+	return nil
+}
+
+// Gets the state whose transaction the verification context and the inclusion proof of the step from `state` refer to.
+// The end state of a trace is past the last transaction of its block, so the last transaction before it is used.
+func contextState(ctx context.Context, proofBackend proof.Backend, state *proof.ExecutionState) (*proof.ExecutionState, error) {
+	if state.TransactionIdx < uint64(len(state.Block.Transactions())) {
+		return state, nil
+	}
+	block := state.Block
+	for len(block.Transactions()) == 0 {
+		if block.NumberU64() == 0 {
+			return nil, fmt.Errorf("no transaction before state %s", state.VMHash)
+		}
+		parent, err := proofBackend.BlockByNumber(ctx, rpc.BlockNumber(block.NumberU64()-1))
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, fmt.Errorf("block #%d not found", block.NumberU64()-1)
+		}
+		block = parent
 	}
-	return err
+	return &proof.ExecutionState{
+		VMHash:         state.VMHash,
+		Block:          block,
+		TransactionIdx: uint64(len(block.Transactions()) - 1),
+	}, nil
 }
 
 func RespondBisection(
 	ctx context.Context,
 	proofBackend proof.Backend,
 	l1Client client.L1BridgeClient,
+	chalClient client.SymChallengeClient,
+	inclusionProver TxInclusionProver,
 	ev *bindings.ISymChallengeBisected,
-	states []*proof.ExecutionState,
+	states proof.ExecutionTrace,
 	opponentEndStateHash common.Hash,
 	isDefender bool,
 ) error {
@@ -78,11 +142,9 @@ func RespondBisection(
 	// Get previous bisections from call data
 	tx, _, err := l1Client.TransactionByHash(ctx, ev.Raw.TxHash)
 	if err != nil {
-		// TODO: error handling
-		log.Error("Failed to get challenge data", "error", err)
-		return nil
+		return fmt.Errorf("Failed to get challenge data, err: %w", err)
 	}
-	decoded, err := l1Client.DecodeBisectExecutionInput(tx)
+	decoded, err := chalClient.DecodeBisectExecutionInput(tx)
 	if err != nil {
 		if isDefender {
 			// Defender always starts first
@@ -92,11 +154,16 @@ func RespondBisection(
 		// We are in the first round when the defender calls initializeChallengeLength
 		// Get initialized challenge length from event
 		steps := segLen
-		if steps != uint64(len(states)-1) {
-			log.Crit("UNHANDLED: currently not support diverge on steps")
+		if steps != states.Len()-1 {
+			// Traces of a different length must be adapted with `proof.PadTrace`
+			return fmt.Errorf("Trace of %d steps does not match challenge length %d", states.Len()-1, steps)
+		}
+		firstState, err := states.State(ctx, 0)
+		if err != nil {
+			return fmt.Errorf("Failed to get execution state, err: %w", err)
 		}
 		prevBisection := [][32]byte{
-			states[0].Hash(),
+			firstState.Hash(),
 			opponentEndStateHash,
 		}
 		if segLen == 1 {
@@ -104,60 +171,78 @@ func RespondBisection(
 			err = SubmitOneStepProof(
 				ctx,
 				proofBackend,
-				l1Client,
-				states[0],
+				chalClient,
+				inclusionProver,
+				firstState,
 				common.Big1,
 				prevBisection,
 				ev.ChallengedSegmentStart,
 				ev.ChallengedSegmentLength,
 			)
 			if err != nil {
-				log.Crit("UNHANDLED: osp failed")
+				return err
 			}
 		} else {
 			// This assertion has multiple steps
-			startState := states[0].Hash()
-			midState := states[steps/2+steps%2].Hash()
-			endState := states[steps].Hash()
+			startState := firstState.Hash()
+			midState, err := states.Hash(ctx, steps/2+steps%2)
+			if err != nil {
+				return fmt.Errorf("Failed to get execution state, err: %w", err)
+			}
+			endState, err := states.Hash(ctx, steps)
+			if err != nil {
+				return fmt.Errorf("Failed to get execution state, err: %w", err)
+			}
 			bisection := [][32]byte{
 				startState,
 				midState,
 				endState,
 			}
-			_, err := l1Client.BisectExecution(
+			_, err := chalClient.BisectExecution(
 				bisection,
 				common.Big1,
 				prevBisection,
 				ev.ChallengedSegmentStart,
 				ev.ChallengedSegmentLength,
 			)
-			log.Info("BisectExecution", "bisection", bisection, "cidx", common.Big1, "psegStart", segStart, "psegLen", segLen, "prev", prevBisection)
 			if err != nil {
-				log.Crit("UNHANDLED: bisection excution failed", "err", err)
+				return fmt.Errorf("Failed to bisect execution, err: %w", err)
 			}
+			log.Info("BisectExecution", "bisection", bisection, "cidx", common.Big1, "psegStart", segStart, "psegLen", segLen, "prev", prevBisection)
 		}
 		return nil
 	}
 	prevBisection := decoded[0].([][32]byte)
-	startState := states[segStart].Hash()
-	midState := states[segStart+segLen/2+segLen%2].Hash()
-	endState := states[segStart+segLen].Hash()
+	segStartState, err := states.State(ctx, segStart)
+	if err != nil {
+		return fmt.Errorf("Failed to get execution state, err: %w", err)
+	}
 	if segLen == 1 {
 		// We've reached one step
 		err = SubmitOneStepProof(
 			ctx,
 			proofBackend,
-			l1Client,
-			states[segStart],
+			chalClient,
+			inclusionProver,
+			segStartState,
 			common.Big1,
 			prevBisection,
 			ev.ChallengedSegmentStart,
 			ev.ChallengedSegmentLength,
 		)
 		if err != nil {
-			log.Crit("UNHANDLED: osp failed")
+			return err
 		}
 	} else {
+		startState := segStartState.Hash()
+		midState, err := states.Hash(ctx, segStart+segLen/2+segLen%2)
+		if err != nil {
+			return fmt.Errorf("Failed to get execution state, err: %w", err)
+		}
+		endState, err := states.Hash(ctx, segStart+segLen)
+		if err != nil {
+			return fmt.Errorf("Failed to get execution state, err: %w", err)
+		}
 		challengeIdx := uint64(1)
 		if prevBisection[1] == midState {
 			challengeIdx = 2
@@ -168,49 +253,173 @@ func RespondBisection(
 			if challengeIdx != 1 {
 				stateIndex = segStart + segLen/2
 			}
+			state, err := states.State(ctx, stateIndex)
+			if err != nil {
+				return fmt.Errorf("Failed to get execution state, err: %w", err)
+			}
 			err = SubmitOneStepProof(
 				ctx,
 				proofBackend,
-				l1Client,
-				states[stateIndex],
+				chalClient,
+				inclusionProver,
+				state,
 				new(big.Int).SetUint64(challengeIdx),
 				prevBisection,
 				ev.ChallengedSegmentStart,
 				ev.ChallengedSegmentLength,
 			)
 			if err != nil {
-				log.Crit("UNHANDLED: osp failed")
+				return err
 			}
 		} else {
 			var newLen uint64 // New segment length
 			var bisection [][32]byte
 			if challengeIdx == 1 {
 				newLen = segLen/2 + segLen%2
+				newMidState, err := states.Hash(ctx, segStart+newLen/2+newLen%2)
+				if err != nil {
+					return fmt.Errorf("Failed to get execution state, err: %w", err)
+				}
 				bisection = [][32]byte{
 					startState,
-					states[segStart+newLen/2+newLen%2].Hash(),
+					newMidState,
 					midState,
 				}
 			} else {
 				newLen = segLen / 2
+				newMidState, err := states.Hash(ctx, segStart+segLen/2+segLen%2+newLen/2+newLen%2)
+				if err != nil {
+					return fmt.Errorf("Failed to get execution state, err: %w", err)
+				}
 				bisection = [][32]byte{
 					midState,
-					states[segStart+segLen/2+segLen%2+newLen/2+newLen%2].Hash(),
+					newMidState,
 					endState,
 				}
 			}
-			_, err := l1Client.BisectExecution(
+			_, err := chalClient.BisectExecution(
 				bisection,
 				new(big.Int).SetUint64(challengeIdx),
 				prevBisection,
 				ev.ChallengedSegmentStart,
 				ev.ChallengedSegmentLength,
 			)
-			log.Info("BisectExecution", "bisection", bisection, "cidx", challengeIdx, "psegStart", segStart, "psegLen", segLen, "prev", prevBisection)
 			if err != nil {
-				log.Crit("UNHANDLED: bisection excution failed", "err", err)
+				return fmt.Errorf("Failed to bisect execution, err: %w", err)
 			}
+			log.Info("BisectExecution", "bisection", bisection, "cidx", challengeIdx, "psegStart", segStart, "psegLen", segLen, "prev", prevBisection)
 		}
 	}
 	return nil
 }
+
+// Bisects the segment of `states` selected by the challenger
+// (asymmetric protocol, defender's move, see `IAsymChallenge.bisectExecution`).
+func BisectAsymSegment(
+	ctx context.Context,
+	chalClient client.AsymChallengeClient,
+	states proof.ExecutionTrace,
+	segStart, segLen uint64,
+) error {
+	startState, err := states.Hash(ctx, segStart)
+	if err != nil {
+		return fmt.Errorf("Failed to get execution state, err: %w", err)
+	}
+	midState, err := states.Hash(ctx, segStart+segLen/2+segLen%2)
+	if err != nil {
+		return fmt.Errorf("Failed to get execution state, err: %w", err)
+	}
+	endState, err := states.Hash(ctx, segStart+segLen)
+	if err != nil {
+		return fmt.Errorf("Failed to get execution state, err: %w", err)
+	}
+	bisection := [][32]byte{
+		startState,
+		midState,
+		endState,
+	}
+	_, err = chalClient.BisectExecution(bisection)
+	log.Info("BisectExecution", "bisection", bisection, "segStart", segStart, "segLen", segLen)
+	if err != nil {
+		return fmt.Errorf("Failed to bisect execution, err: %w", err)
+	}
+	return nil
+}
+
+// Responds to the defender's bisection `ev` (asymmetric protocol, challenger's move):
+// selects the first segment whose end state differs from `states` (see `IAsymChallenge.challengeExecution`),
+// or proves it if it is a single step (see `IAsymChallenge.verifyOneStepProof`).
+func RespondAsymBisection(
+	ctx context.Context,
+	proofBackend proof.Backend,
+	l1Client client.L1BridgeClient,
+	chalClient client.AsymChallengeClient,
+	inclusionProver TxInclusionProver,
+	ev *bindings.IAsymChallengeBisected,
+	states proof.ExecutionTrace,
+	opponentEndStateHash common.Hash,
+) error {
+	segStart := ev.ChallengedSegmentStart.Uint64()
+	segLen := ev.ChallengedSegmentLength.Uint64()
+	// Get the bisection from call data
+	tx, _, err := l1Client.TransactionByHash(ctx, ev.Raw.TxHash)
+	if err != nil {
+		return fmt.Errorf("Failed to get challenge data, err: %w", err)
+	}
+	var prevBisection [][32]byte
+	var offsets []uint64 // Steps preceding each state of `prevBisection`
+	decoded, err := chalClient.DecodeBisectExecutionInput(tx)
+	if err != nil {
+		// We are in the first round when the defender calls initializeChallengeLength
+		if segLen != states.Len()-1 {
+			// Traces of a different length must be adapted with `proof.PadTrace`
+			return fmt.Errorf("Trace of %d steps does not match challenge length %d", states.Len()-1, segLen)
+		}
+		startState, err := states.Hash(ctx, 0)
+		if err != nil {
+			return fmt.Errorf("Failed to get execution state, err: %w", err)
+		}
+		prevBisection = [][32]byte{startState, opponentEndStateHash}
+		offsets = []uint64{0, segLen}
+	} else {
+		prevBisection = decoded[0].([][32]byte)
+		offsets = []uint64{segStart, segStart + segLen/2 + segLen%2, segStart + segLen}
+		if len(prevBisection) != len(offsets) {
+			return fmt.Errorf("Unexpected bisection of %d states", len(prevBisection))
+		}
+	}
+	for i := 1; i < len(prevBisection); i++ {
+		hash, err := states.Hash(ctx, offsets[i])
+		if err != nil {
+			return fmt.Errorf("Failed to get execution state, err: %w", err)
+		}
+		if hash == prevBisection[i] {
+			continue
+		}
+		challengeIdx := big.NewInt(int64(i))
+		if offsets[i]-offsets[i-1] == 1 {
+			// The challenged segment is a single step
+			state, err := states.State(ctx, offsets[i-1])
+			if err != nil {
+				return fmt.Errorf("Failed to get execution state, err: %w", err)
+			}
+			osp, inclusionProof, rawCtx, err := buildOneStepProof(ctx, proofBackend, inclusionProver, state)
+			if err != nil {
+				return err
+			}
+			_, err = chalClient.VerifyOneStepProof(osp, inclusionProof, rawCtx, challengeIdx, prevBisection)
+			log.Info("OSP submitted")
+			if err != nil {
+				return fmt.Errorf("OSP verification failed, err: %w", err)
+			}
+			return nil
+		}
+		_, err = chalClient.ChallengeExecution(challengeIdx, prevBisection)
+		log.Info("ChallengeExecution", "cidx", i, "segStart", offsets[i-1], "segLen", offsets[i]-offsets[i-1], "prev", prevBisection)
+		if err != nil {
+			return fmt.Errorf("Failed to challenge execution, err: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("Bisection agrees with local trace, nothing to challenge")
+}
@@ -0,0 +1,246 @@
+package validator
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/services"
+	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
+)
+
+// Number of `AdvanceStake` transactions kept in the stake history.
+const stakeHistoryLimit = 256
+
+// Validator state reported through the validator API.
+// Written by the service goroutines, guarded by `Validator.statusLock`.
+type status struct {
+	lastValidatedAssertion *rollupTypes.Assertion
+	currentAssertion       *rollupTypes.Assertion
+	challenges             []*challengeCtx // Oldest first
+	stakeHistory           []*StakeAdvance
+}
+
+// StakeAdvance describes an `AdvanceStake` transaction sent by the validator.
+type StakeAdvance struct {
+	AssertionID *hexutil.Big   `json:"assertionID"`
+	TxHash      common.Hash    `json:"txHash"`
+	Time        hexutil.Uint64 `json:"time"`
+}
+
+// CurrentAssertionStatus describes the assertion the validator is waiting on.
+type CurrentAssertionStatus struct {
+	Assertion *services.RPCAssertion `json:"assertion"`
+	// Local L2 chain height.
+	LocalHeight hexutil.Uint64 `json:"localHeight"`
+	// Local L2 height needed to validate the assertion, or nil if the local
+	// chain does not cover the assertion's inbox size yet.
+	RequiredHeight *hexutil.Uint64 `json:"requiredHeight"`
+}
+
+// ValidatorStatus describes the assertions tracked by the validator.
+type ValidatorStatus struct {
+	LastValidatedAssertion *services.RPCAssertion  `json:"lastValidatedAssertion"`
+	CurrentAssertion       *CurrentAssertionStatus `json:"currentAssertion"`
+	InChallenge            bool                    `json:"inChallenge"`
+}
+
+// ChallengeStatus describes a challenge in progress.
+type ChallengeStatus struct {
+	ChallengeAddr          common.Address         `json:"challengeAddr"`
+	OpponentAssertion      *services.RPCAssertion `json:"opponentAssertion"`
+	OurAssertion           *services.RPCAssertion `json:"ourAssertion"`
+	LastValidatedAssertion *services.RPCAssertion `json:"lastValidatedAssertion"`
+}
+
+// StakerStatus is the RPC representation of the validator's staker record on L1.
+type StakerStatus struct {
+	IsStaked         bool           `json:"isStaked"`
+	AmountStaked     *hexutil.Big   `json:"amountStaked"`
+	AssertionID      *hexutil.Big   `json:"assertionID"`
+	CurrentChallenge common.Address `json:"currentChallenge"`
+}
+
+// ValidationResult is the result of re-validating an assertion against the local chain.
+type ValidationResult struct {
+	AssertionID *hexutil.Big   `json:"assertionID"`
+	StartBlock  hexutil.Uint64 `json:"startBlock"`
+	EndBlock    hexutil.Uint64 `json:"endBlock"`
+	VmHash      common.Hash    `json:"vmHash"`
+	LocalVmHash common.Hash    `json:"localVmHash"`
+	Match       bool           `json:"match"`
+}
+
+// ValidatorAPI is the collection of validator status and control APIs.
+type ValidatorAPI struct {
+	v *Validator
+}
+
+// Status returns the last validated assertion and the assertion currently being validated.
+func (api *ValidatorAPI) Status() *ValidatorStatus {
+	api.v.statusLock.RLock()
+	defer api.v.statusLock.RUnlock()
+	st := api.v.status
+	result := &ValidatorStatus{
+		LastValidatedAssertion: services.NewRPCAssertion(st.lastValidatedAssertion),
+		InChallenge:            len(st.challenges) > 0,
+	}
+	if st.currentAssertion != nil && st.lastValidatedAssertion != nil {
+		current := &CurrentAssertionStatus{
+			Assertion:   services.NewRPCAssertion(st.currentAssertion),
+			LocalHeight: hexutil.Uint64(api.v.Chain().CurrentBlock().NumberU64()),
+		}
+		prevInboxSize := st.lastValidatedAssertion.InboxSize
+		height, err := api.v.FindInboxSizeEnd(st.currentAssertion.StartBlock, prevInboxSize, st.currentAssertion.InboxSize)
+		if err == nil {
+			current.RequiredHeight = (*hexutil.Uint64)(&height)
+		}
+		result.CurrentAssertion = current
+	}
+	return result
+}
+
+// Challenge returns the oldest challenge in progress, or nil if there is none.
+func (api *ValidatorAPI) Challenge() *ChallengeStatus {
+	challenges := api.Challenges()
+	if len(challenges) == 0 {
+		return nil
+	}
+	return challenges[0]
+}
+
+// Challenges returns the challenges in progress, oldest first.
+func (api *ValidatorAPI) Challenges() []*ChallengeStatus {
+	api.v.statusLock.RLock()
+	defer api.v.statusLock.RUnlock()
+	challenges := make([]*ChallengeStatus, 0, len(api.v.status.challenges))
+	for _, chalCtx := range api.v.status.challenges {
+		challenges = append(challenges, &ChallengeStatus{
+			ChallengeAddr:          chalCtx.info.Address,
+			OpponentAssertion:      services.NewRPCAssertion(chalCtx.opponentAssertion),
+			OurAssertion:           services.NewRPCAssertion(chalCtx.ourAssertion),
+			LastValidatedAssertion: services.NewRPCAssertion(chalCtx.lastValidatedAssertion),
+		})
+	}
+	return challenges
+}
+
+// Staker returns the validator's staker record from the L1 Rollup contract.
+func (api *ValidatorAPI) Staker() (*StakerStatus, error) {
+	staker, err := api.v.L1Client.GetStaker()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get staker, err: %w", err)
+	}
+	return &StakerStatus{
+		IsStaked:         staker.IsStaked,
+		AmountStaked:     (*hexutil.Big)(staker.AmountStaked),
+		AssertionID:      (*hexutil.Big)(staker.AssertionID),
+		CurrentChallenge: staker.CurrentChallenge,
+	}, nil
+}
+
+// StakeHistory returns the most recent `AdvanceStake` transactions, oldest first.
+func (api *ValidatorAPI) StakeHistory() []*StakeAdvance {
+	api.v.statusLock.RLock()
+	defer api.v.statusLock.RUnlock()
+	history := make([]*StakeAdvance, len(api.v.status.stakeHistory))
+	copy(history, api.v.status.stakeHistory)
+	return history
+}
+
+// ValidateAssertion re-validates an assertion against the local chain.
+// Nothing is submitted to L1.
+func (api *ValidatorAPI) ValidateAssertion(ctx context.Context, id *hexutil.Big) (*ValidationResult, error) {
+	assertionID := (*big.Int)(id)
+	assertion, err := api.v.L1Client.GetAssertion(assertionID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get assertion, err: %w", err)
+	}
+	if assertionID.Sign() != 0 && assertion.StateHash == [32]byte{} {
+		return nil, fmt.Errorf("Assertion %v not found", assertionID)
+	}
+	result := &ValidationResult{
+		AssertionID: id,
+		VmHash:      assertion.StateHash,
+	}
+	// Find the assertion's block range, from the index if available.
+	if blockRange := api.v.AssertionBlockRange(assertionID); blockRange != nil {
+		result.StartBlock = hexutil.Uint64(blockRange.StartBlock)
+		result.EndBlock = hexutil.Uint64(blockRange.EndBlock)
+	} else if assertionID.Sign() != 0 {
+		parent, err := api.v.L1Client.GetAssertion(assertion.Parent)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get parent assertion, err: %w", err)
+		}
+		var start uint64
+		if parentRange := api.v.AssertionBlockRange(assertion.Parent); parentRange != nil {
+			start = parentRange.EndBlock + 1
+		} else {
+			parentEnd, err := api.v.FindInboxSizeEnd(1, common.Big0, parent.InboxSize)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to find parent assertion blocks, err: %w", err)
+			}
+			start = parentEnd + 1
+		}
+		end, err := api.v.FindInboxSizeEnd(start, parent.InboxSize, assertion.InboxSize)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to find assertion blocks, err: %w", err)
+		}
+		result.StartBlock = hexutil.Uint64(start)
+		result.EndBlock = hexutil.Uint64(end)
+	}
+	block := api.v.Chain().GetBlockByNumber(uint64(result.EndBlock))
+	if block == nil {
+		return nil, fmt.Errorf("Missing local block #%d", result.EndBlock)
+	}
+	result.LocalVmHash = block.Root()
+	result.Match = result.LocalVmHash == result.VmHash
+	return result, nil
+}
+
+// Reports the state held by the validation goroutine.
+func (v *Validator) reportValidationStatus(lastValidatedAssertion, currentAssertion *rollupTypes.Assertion) {
+	v.statusLock.Lock()
+	defer v.statusLock.Unlock()
+	v.status.lastValidatedAssertion = copyAssertion(lastValidatedAssertion)
+	v.status.currentAssertion = copyAssertion(currentAssertion)
+}
+
+func copyAssertion(assertion *rollupTypes.Assertion) *rollupTypes.Assertion {
+	if assertion == nil {
+		return nil
+	}
+	return assertion.Copy()
+}
+
+// Reports the challenges in progress, by challenge address.
+func (v *Validator) reportChallenges(playing map[common.Address]*challengeCtx) {
+	challenges := make([]*challengeCtx, 0, len(playing))
+	for _, chalCtx := range playing {
+		challenges = append(challenges, chalCtx)
+	}
+	sort.Slice(challenges, func(i, j int) bool {
+		return challenges[i].info.L1BlockNumber < challenges[j].info.L1BlockNumber
+	})
+	v.statusLock.Lock()
+	defer v.statusLock.Unlock()
+	v.status.challenges = challenges
+}
+
+// Records an `AdvanceStake` transaction in the stake history.
+func (v *Validator) recordStakeAdvance(assertionID *big.Int, txHash common.Hash) {
+	v.statusLock.Lock()
+	defer v.statusLock.Unlock()
+	v.status.stakeHistory = append(v.status.stakeHistory, &StakeAdvance{
+		AssertionID: (*hexutil.Big)(new(big.Int).Set(assertionID)),
+		TxHash:      txHash,
+		Time:        hexutil.Uint64(time.Now().Unix()),
+	})
+	if len(v.status.stakeHistory) > stakeHistoryLimit {
+		v.status.stakeHistory = v.status.stakeHistory[1:]
+	}
+}
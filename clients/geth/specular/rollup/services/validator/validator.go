@@ -4,17 +4,19 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/specularl2/specular/clients/geth/specular/bindings"
 	"github.com/specularl2/specular/clients/geth/specular/proof"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/client"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/services"
+	"github.com/specularl2/specular/clients/geth/specular/rollup/services/challenge"
 	rollupTypes "github.com/specularl2/specular/clients/geth/specular/rollup/types"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/fmt"
 	"github.com/specularl2/specular/clients/geth/specular/rollup/utils/log"
@@ -24,17 +26,27 @@ type challengeCtx struct {
 	opponentAssertion      *rollupTypes.Assertion
 	ourAssertion           *rollupTypes.Assertion
 	lastValidatedAssertion *rollupTypes.Assertion
+	info                   *rollupTypes.ChallengeInfo // Set once the challenge is created on L1
 }
 
 var errAssertionOverflowedLocalInbox = fmt.Errorf("assertion overflowed inbox")
 var errValidationFailed = fmt.Errorf("validation failed")
 
+// Delay before retrying a failed L1 tx
+var retryDelay = 2 * time.Second
+
 type Validator struct {
 	*services.BaseService
 
 	newBatchCh            chan struct{}
 	challengeCh           chan *challengeCtx
 	challengeResolutionCh chan *rollupTypes.Assertion
+
+	challenges *challenge.ChallengeManager
+
+	// State reported through the validator API
+	statusLock sync.RWMutex
+	status     status
 }
 
 // TODO: this shares a lot of code with sequencer
@@ -46,8 +58,9 @@ func New(eth services.Backend, proofBackend proof.Backend, l1Client client.L1Bri
 	v := &Validator{
 		BaseService:           base,
 		newBatchCh:            make(chan struct{}, 4096),
-		challengeCh:           make(chan *challengeCtx),
-		challengeResolutionCh: make(chan *rollupTypes.Assertion),
+		challengeCh:           make(chan *challengeCtx, 4096),
+		challengeResolutionCh: make(chan *rollupTypes.Assertion, 4096),
+		challenges:            challenge.NewChallengeManager(base, challenge.BisectionStrategy{}),
 	}
 	return v, nil
 }
@@ -67,7 +80,7 @@ func (v *Validator) tryValidateAssertion(lastValidatedAssertion, assertion *roll
 		if block == nil {
 			return errAssertionOverflowedLocalInbox
 		}
-		numTxs := uint64(len(block.Transactions()))
+		numTxs := v.NumInboxTxs(block)
 		if numTxs > inboxSizeDiff.Uint64() {
 			return fmt.Errorf("UNHANDLED: Assertion created in the middle of block, validator state corrupted!")
 		}
@@ -84,24 +97,31 @@ func (v *Validator) tryValidateAssertion(lastValidatedAssertion, assertion *roll
 			StartBlock: assertion.StartBlock,
 			EndBlock:   assertion.EndBlock,
 		}
-		v.challengeCh <- &challengeCtx{assertion, ourAssertion, lastValidatedAssertion}
+		v.challengeCh <- &challengeCtx{
+			opponentAssertion:      assertion,
+			ourAssertion:           ourAssertion,
+			lastValidatedAssertion: lastValidatedAssertion,
+		}
 		return errValidationFailed
 	}
+	v.IndexAssertion(assertion)
 	// Validation succeeded, confirm assertion and advance stake
 	// if assertion.ID
-	_, err := v.L1Client.AdvanceStake(assertion.ID)
+	result, err := v.L1Client.AdvanceStake(assertion.ID)
 	if errors.Is(err, core.ErrInsufficientFunds) {
 		return fmt.Errorf("Insufficient Funds to send Tx, err: %w", err)
 	}
 	if err != nil {
 		return fmt.Errorf("UNHANDLED: Can't advance stake, validator state corrupted, err: %w", err)
 	}
+	v.recordStakeAdvance(assertion.ID, result.Tx.Hash())
 	return nil
 }
 
 // This function runs as a goroutine. It listens for and validates assertions posted to the L1 Rollup contract,
 // advances its stake if validated, and challenges if not.
-func (v *Validator) validationLoop(ctx context.Context) {
+// Validation is paused while challenges are in progress, starting with the `numChallenges` resumed ones.
+func (v *Validator) validationLoop(ctx context.Context, numChallenges int) {
 	defer v.Wg.Done()
 
 	lastValidatedAssertion, err := v.GetLastValidatedAssertion(ctx)
@@ -120,8 +140,6 @@ func (v *Validator) validationLoop(ctx context.Context) {
 	// The next assertion to be validated
 	var currentAssertion *rollupTypes.Assertion
 
-	isInChallenge := false
-
 	validateCurrentAssertion := func() error {
 		// Validate current assertion
 		err := v.tryValidateAssertion(lastValidatedAssertion, currentAssertion)
@@ -129,7 +147,7 @@ func (v *Validator) validationLoop(ctx context.Context) {
 			switch {
 			case errors.Is(err, errValidationFailed):
 				// Validation failed, challenge
-				isInChallenge = true
+				numChallenges++
 				return nil
 			case errors.Is(err, errAssertionOverflowedLocalInbox):
 				// Assertion overflowed local inbox, wait for next batch event
@@ -146,13 +164,16 @@ func (v *Validator) validationLoop(ctx context.Context) {
 	}
 
 	for {
-		if isInChallenge {
-			// Wait for the challenge resolution
+		v.reportValidationStatus(lastValidatedAssertion, currentAssertion)
+		if numChallenges > 0 {
+			// Wait for the challenge resolutions
 			select {
 			case ourAssertion := <-v.challengeResolutionCh:
 				log.Info("challenge finished")
-				isInChallenge = false
-				lastValidatedAssertion = ourAssertion
+				numChallenges--
+				if ourAssertion.InboxSize.Cmp(lastValidatedAssertion.InboxSize) >= 0 {
+					lastValidatedAssertion = ourAssertion
+				}
 				currentAssertion = nil
 			case <-ctx.Done():
 				return
@@ -202,7 +223,9 @@ func (v *Validator) validationLoop(ctx context.Context) {
 	}
 }
 
-func (v *Validator) challengeLoop(ctx context.Context) {
+// This function runs as a goroutine. It plays the challenges requested by `validationLoop`, concurrently with
+// the `resumed` ones (persisted before a restart), and reports each resolution back to `validationLoop`.
+func (v *Validator) challengeLoop(ctx context.Context, resumed []*rollupTypes.ChallengeInfo) {
 	defer v.Wg.Done()
 
 	// Watch AssertionCreated event
@@ -220,154 +243,134 @@ func (v *Validator) challengeLoop(ctx context.Context) {
 	}
 	defer challengedSub.Unsubscribe()
 
-	// Watch L1 blockchain for challenge timeout
-	headCh := make(chan *types.Header, 4096)
-	headSub, err := v.L1Client.ResubscribeErrNewHead(ctx, headCh)
-	if err != nil {
-		log.Crit("Failed to watch l1 chain head", "err", err)
+	var (
+		// Challenges whose assertion (ours) is being created on L1
+		creating []*challengeCtx
+		// Challenges being created on L1
+		challenging []*challengeCtx
+		// Challenges being played, by challenge address
+		playing = make(map[common.Address]*challengeCtx)
+	)
+	for _, info := range resumed {
+		playing[info.Address] = &challengeCtx{
+			opponentAssertion: &rollupTypes.Assertion{
+				VmHash:     info.OpponentVmHash,
+				InboxSize:  info.InboxSize,
+				StartBlock: info.StartBlock,
+				EndBlock:   info.EndBlock,
+			},
+			ourAssertion: &rollupTypes.Assertion{
+				VmHash:     info.VmHash,
+				InboxSize:  info.InboxSize,
+				StartBlock: info.StartBlock,
+				EndBlock:   info.EndBlock,
+			},
+			info: info,
+		}
 	}
-	defer headSub.Unsubscribe()
-
-	var states []*proof.ExecutionState
-
-	var bisectedCh chan *bindings.ISymChallengeBisected
-	var bisectedSub event.Subscription
-	var challengeCompletedCh chan *bindings.ISymChallengeCompleted
-	var challengeCompletedSub event.Subscription
-
-	inChallenge := false
-	var chalCtx *challengeCtx
-	var opponentTimeoutBlock uint64
+	v.reportChallenges(playing)
 
 	for {
-		if inChallenge {
-			select {
-			case ev := <-bisectedCh:
-				// case get bisection, if is our turn
-				//   if in single step, submit proof
-				//   if multiple step, track current segment, update
-				responder, err := v.L1Client.CurrentChallengeResponder()
-				if err != nil {
-					// TODO: error handling
-					log.Error("Can not get current responder", "error", err)
+		select {
+		case chalCtx := <-v.challengeCh:
+			creating = append(creating, chalCtx)
+			ourAssertion := chalCtx.ourAssertion
+			v.sendInBackground(ctx, "create assertion for challenge", func() error {
+				_, err := v.L1Client.CreateAssertion(ourAssertion.VmHash, ourAssertion.InboxSize)
+				return err
+			})
+		case ev := <-createdCh:
+			if common.Address(ev.AsserterAddr) != v.Config.Coinbase {
+				continue
+			}
+			for i, chalCtx := range creating {
+				if ev.VmHash != chalCtx.ourAssertion.VmHash {
 					continue
 				}
-				// If it's our turn
-				if responder == common.Address(v.Config.Coinbase) {
-					err := services.RespondBisection(ctx, v.ProofBackend, v.L1Client, ev, states, chalCtx.opponentAssertion.VmHash, false)
-					if err != nil {
-						// TODO: error handling
-						log.Error("Can not respond to bisection", "error", err)
-						continue
-					}
-				} else {
-					opponentTimeLeft, err := v.L1Client.CurrentChallengeResponderTimeLeft()
-					if err != nil {
-						// TODO: error handling
-						log.Error("Can not get current responder left time", "error", err)
-						continue
-					}
-					log.Info("Opponent time left", "time", opponentTimeLeft)
-					opponentTimeoutBlock = ev.Raw.BlockNumber + opponentTimeLeft.Uint64()
-				}
-			case header := <-headCh:
-				if opponentTimeoutBlock == 0 {
+				creating = append(creating[:i], creating[i+1:]...)
+				challenging = append(challenging, chalCtx)
+				players := [2]common.Address{common.Address(v.Config.SequencerAddr), common.Address(v.Config.Coinbase)}
+				assertionIDs := [2]*big.Int{chalCtx.opponentAssertion.ID, ev.AssertionID}
+				v.sendInBackground(ctx, "start challenge", func() error {
+					_, err := v.L1Client.ChallengeAssertion(players, assertionIDs)
+					return err
+				})
+				break
+			}
+		case ev := <-challengedCh:
+			for i, chalCtx := range challenging {
+				if ev.AssertionID.Cmp(chalCtx.opponentAssertion.ID) != 0 {
 					continue
 				}
-				// TODO: can we use >= here?
-				if header.Number.Uint64() > opponentTimeoutBlock {
-					_, err := v.L1Client.TimeoutChallenge()
-					if err != nil {
-						log.Error("Can not timeout opponent", "error", err)
-						continue
-						// TODO: wait some time before retry
-						// TODO: fix race condition
-					}
+				log.Info("validator saw challenge", "assertion id", ev.AssertionID, "block", ev.Raw.BlockNumber)
+				challenging = append(challenging[:i], challenging[i+1:]...)
+				chalCtx.info = &rollupTypes.ChallengeInfo{
+					Address:        ev.ChallengeAddr,
+					L1BlockNumber:  ev.Raw.BlockNumber,
+					StartBlock:     chalCtx.opponentAssertion.StartBlock,
+					EndBlock:       chalCtx.opponentAssertion.EndBlock,
+					InboxSize:      chalCtx.opponentAssertion.InboxSize,
+					VmHash:         chalCtx.ourAssertion.VmHash,
+					OpponentVmHash: chalCtx.opponentAssertion.VmHash,
+				}
+				err := v.challenges.StartChallenge(ctx, chalCtx.info)
+				if err != nil && !errors.Is(err, challenge.ErrChallengeInProgress) {
+					// Cannot be played, resume validation
+					log.Error("Failed to start challenge", "address", ev.ChallengeAddr, "err", err)
+					v.challengeResolutionCh <- chalCtx.ourAssertion
+					break
 				}
-			case ev := <-challengeCompletedCh:
+				// If already in progress (e.g. resumed), its session reports the result
+				playing[ev.ChallengeAddr] = chalCtx
+				v.reportChallenges(playing)
+				break
+			}
+		case result := <-v.challenges.Results():
+			chalCtx, ok := playing[result.Info.Address]
+			if !ok {
+				continue
+			}
+			if result.Err != nil {
+				log.Error("Challenge could not be played", "address", result.Info.Address, "err", result.Err)
+			} else {
 				// TODO: handle if we are not winner --> state corrupted
-				log.Info("Challenge completed", "winner", ev.Winner)
-				bisectedSub.Unsubscribe()
-				challengeCompletedSub.Unsubscribe()
-				states = []*proof.ExecutionState{}
-				inChallenge = false
-				v.challengeResolutionCh <- chalCtx.ourAssertion
-			case <-ctx.Done():
-				bisectedSub.Unsubscribe()
-				challengeCompletedSub.Unsubscribe()
+				log.Info("Challenge finished", "address", result.Info.Address, "winner", result.Winner)
+			}
+			delete(playing, result.Info.Address)
+			v.reportChallenges(playing)
+			v.challengeResolutionCh <- chalCtx.ourAssertion
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sends an L1 tx with `send` in the background, so that `challengeLoop` keeps handling events meanwhile.
+// The tx is re-sent after `retryDelay` if it fails, unless it reverted.
+func (v *Validator) sendInBackground(ctx context.Context, desc string, send func() error) {
+	v.Wg.Add(1)
+	go func() {
+		defer v.Wg.Done()
+		for {
+			err := send()
+			if err == nil {
 				return
 			}
-		} else {
+			if errors.Is(err, core.ErrInsufficientFunds) {
+				log.Crit("Insufficient Funds to send Tx", "error", err)
+			}
+			var revertErr *client.RevertError
+			if errors.As(err, &revertErr) {
+				log.Crit("UNHANDLED: Can't "+desc+", validator state corrupted", "err", err)
+			}
+			log.Warn("Failed to "+desc+", retrying", "err", err)
 			select {
-			case chalCtx = <-v.challengeCh:
-				_, err = v.L1Client.CreateAssertion(chalCtx.ourAssertion.VmHash, chalCtx.ourAssertion.InboxSize)
-				if errors.Is(err, core.ErrInsufficientFunds) {
-					log.Crit("Insufficient Funds to send Tx", "error", err)
-				}
-				if err != nil {
-					log.Crit("UNHANDLED: Can't create assertion for challenge, validator state corrupted", "err", err)
-				}
-			case ev := <-createdCh:
-				if common.Address(ev.AsserterAddr) == v.Config.Coinbase {
-					if ev.VmHash == chalCtx.ourAssertion.VmHash {
-						_, err := v.L1Client.ChallengeAssertion(
-							[2]common.Address{
-								common.Address(v.Config.SequencerAddr),
-								common.Address(v.Config.Coinbase),
-							},
-							[2]*big.Int{
-								chalCtx.opponentAssertion.ID,
-								ev.AssertionID,
-							},
-						)
-						if errors.Is(err, core.ErrInsufficientFunds) {
-							log.Crit("Insufficient Funds to send Tx", "error", err)
-						}
-						if err != nil {
-							log.Crit("UNHANDLED: Can't start challenge, validator state corrupted", "err", err)
-						}
-					}
-				}
-			case ev := <-challengedCh:
-				if chalCtx == nil {
-					continue
-				}
-				log.Info("validator saw challenge", "assertion id", ev.AssertionID, "expected id", chalCtx.opponentAssertion.ID, "block", ev.Raw.BlockNumber)
-				if ev.AssertionID.Cmp(chalCtx.opponentAssertion.ID) == 0 {
-					// start := ev.Raw.BlockNumber - 2
-					err := v.L1Client.InitNewChallengeSession(context.Background(), ev.ChallengeAddr)
-					if err != nil {
-						log.Crit("Failed to access ongoing challenge", "address", ev.ChallengeAddr, "err", err)
-					}
-					bisectedCh = make(chan *bindings.ISymChallengeBisected, 4096)
-					bisectedSub, err = v.L1Client.WatchBisected(&bind.WatchOpts{Context: ctx}, bisectedCh)
-					if err != nil {
-						log.Crit("Failed to watch challenge event", "err", err)
-					}
-					challengeCompletedCh = make(chan *bindings.ISymChallengeCompleted, 4096)
-					challengeCompletedSub, err = v.L1Client.WatchChallengeCompleted(&bind.WatchOpts{Context: ctx}, challengeCompletedCh)
-					if err != nil {
-						log.Crit("Failed to watch challenge event", "err", err)
-					}
-					states, err = proof.GenerateStates(
-						v.ProofBackend,
-						ctx,
-						chalCtx.opponentAssertion.StartBlock,
-						chalCtx.opponentAssertion.EndBlock+1,
-						nil,
-					)
-					if err != nil {
-						log.Crit("Failed to generate states", "err", err)
-					}
-					inChallenge = true
-				}
-			case <-headCh:
-				continue // consume channel values
+			case <-time.After(retryDelay):
 			case <-ctx.Done():
 				return
 			}
 		}
-	}
+	}()
 }
 
 func (v *Validator) Start() error {
@@ -381,19 +384,30 @@ func (v *Validator) Start() error {
 	}
 	// This is necessary despite `SyncLoop`, since we need an up-to-date L2 chain
 	// before we resolve all information about the last validated assertion.
-	end, err := v.SyncL2ChainToL1Head(ctx, v.Config.L1RollupGenesisBlock)
+	end, err := v.SyncL2ChainToL1Head(ctx, v.L1SyncStart)
 	if err != nil {
 		return fmt.Errorf("Failed to start sequencer: %w", err)
 	}
+	// Resume the challenges we were taking part in before a restart
+	resumed, err := v.challenges.ResumeChallenges(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to start validator: %w", err)
+	}
 	v.Wg.Add(3)
 	go v.SyncLoop(ctx, end+1, v.newBatchCh)
-	go v.validationLoop(ctx)
-	go v.challengeLoop(ctx)
+	go v.validationLoop(ctx, len(resumed))
+	go v.challengeLoop(ctx, resumed)
 	log.Info("Validator started.")
 	return nil
 }
 
 func (v *Validator) APIs() []rpc.API {
-	// TODO: validator APIs
-	return []rpc.API{}
+	return []rpc.API{
+		{
+			Namespace: "validator",
+			Version:   "1.0",
+			Service:   &ValidatorAPI{v},
+			Public:    false,
+		},
+	}
 }
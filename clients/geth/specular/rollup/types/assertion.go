@@ -18,11 +18,19 @@ type Assertion struct {
 
 func (a *Assertion) Copy() *Assertion {
 	return &Assertion{
-		ID:         new(big.Int).Set(a.ID),
+		ID:         copyBig(a.ID),
 		VmHash:     a.VmHash,
-		InboxSize:  new(big.Int).Set(a.InboxSize),
-		Deadline:   new(big.Int).Set(a.Deadline),
+		InboxSize:  copyBig(a.InboxSize),
+		Deadline:   copyBig(a.Deadline),
 		StartBlock: a.StartBlock,
 		EndBlock:   a.EndBlock,
 	}
 }
+
+// Fields of assertions not yet created on L1 (e.g. `ID`, `Deadline`) may be nil.
+func copyBig(x *big.Int) *big.Int {
+	if x == nil {
+		return nil
+	}
+	return new(big.Int).Set(x)
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AssertionStatus is the L1 settlement status of an assertion
+type AssertionStatus uint8
+
+const (
+	AssertionPending AssertionStatus = iota
+	AssertionChallenged
+	AssertionConfirmed
+	AssertionRejected
+)
+
+func (s AssertionStatus) String() string {
+	switch s {
+	case AssertionPending:
+		return "pending"
+	case AssertionChallenged:
+		return "challenged"
+	case AssertionConfirmed:
+		return "confirmed"
+	case AssertionRejected:
+		return "rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// AssertionInfo is the indexed record of an assertion created on the L1 Rollup contract
+type AssertionInfo struct {
+	ID            *big.Int
+	Parent        *big.Int
+	VmHash        common.Hash
+	InboxSize     *big.Int
+	StartBlock    uint64
+	EndBlock      uint64
+	Status        AssertionStatus
+	L1BlockNumber uint64         // L1 block of the `AssertionCreated` event
+	L1TxHash      common.Hash    // L1 tx of the `AssertionCreated` event
+	ChallengeAddr common.Address // Set if the assertion was challenged
+	Invalid       bool           // Set if the assertion does not end at a local block boundary, so it covers no blocks
+}
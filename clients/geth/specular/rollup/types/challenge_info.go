@@ -0,0 +1,40 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ChallengeInfo is the locally persisted record of a challenge the node takes part in,
+// used to resume the challenge after a restart
+type ChallengeInfo struct {
+	Address        common.Address // Address of the challenge contract
+	L1BlockNumber  uint64         // L1 block of the `AssertionChallenged` event
+	StartBlock     uint64         // First L2 block of the disputed assertion
+	EndBlock       uint64         // Last L2 block of the disputed assertion
+	InboxSize      *big.Int       // Inbox size of the disputed assertion
+	VmHash         common.Hash    // Our end state
+	OpponentVmHash common.Hash    // End state claimed by the opponent (zero if we are the defender)
+}
+
+// Whether we are the defender of the challenge (i.e. the asserter of the challenged assertion).
+func (c *ChallengeInfo) IsDefender() bool {
+	return c.OpponentVmHash == (common.Hash{})
+}
+
+// TraceCheckpoint points at the inter-state before transaction `TransactionIdx` in block `BlockNumber`,
+// which is the `Index`-th execution state of a trace.
+type TraceCheckpoint struct {
+	Index          uint64
+	BlockNumber    uint64
+	TransactionIdx uint64
+}
+
+// TraceIndex is the checkpointed index of an execution trace (see `proof.StateIndex`),
+// as persisted in the database for challenge traces.
+type TraceIndex struct {
+	Length      uint64 // Number of execution states
+	Interval    uint64 // Minimum number of steps between two checkpoints
+	Checkpoints []TraceCheckpoint
+}
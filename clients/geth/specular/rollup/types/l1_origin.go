@@ -0,0 +1,23 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// L1Origin identifies the `TxBatchAppended` event (i.e. the L1 batch) that sequenced an L2 block
+type L1Origin struct {
+	BatchNumber   uint64
+	L1BlockNumber uint64
+	L1TxHash      common.Hash
+	// Number of txs in the inbox before the batch. Not set for blocks indexed by older versions.
+	StartTxNumber uint64 `rlp:"optional"`
+	// Block number of the block's sequence context in the batch, which may differ from its local number
+	// (see `commitBlocks`). Not set for blocks numbered as sequenced.
+	SequencedBlockNumber *uint64 `rlp:"optional"`
+}
+
+// BlockRange is a range of L2 blocks (both ends inclusive), e.g. sequenced by a batch or covered by an assertion
+type BlockRange struct {
+	StartBlock uint64
+	EndBlock   uint64
+}
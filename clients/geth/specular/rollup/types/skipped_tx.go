@@ -0,0 +1,23 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SkippedTx is a tx sequenced in an L2 block that failed to execute (e.g. with a
+// stale nonce or insufficient funds) and was left out of the block.
+type SkippedTx struct {
+	// Position of the tx among the txs sequenced in the block
+	Index  uint64
+	Tx     *types.Transaction
+	Reason string
+}
+
+// SkippedBatch is a batch appended to the L1 inbox that could not be derived into L2 blocks
+// (e.g. because it fails to decode). It is derived as a single empty L2 block instead.
+type SkippedBatch struct {
+	BatchNumber uint64
+	// Number of inbox txs of the batch, counted towards the inbox size of its block
+	NumTxs uint64
+	Reason string
+}
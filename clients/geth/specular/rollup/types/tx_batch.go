@@ -18,6 +18,8 @@ type TxBatch struct {
 	Contexts []SequenceContext
 	Txs      types.Transactions
 	GasUsed  *big.Int
+
+	dataLen uint64 // Total length of encoded txs, only set for batches created from blocks
 }
 
 // SequenceBlock represents a block sequenced to L1 sequencer inbox
@@ -32,6 +34,9 @@ type SequenceContext struct {
 	NumTxs      uint64
 	BlockNumber uint64
 	Timestamp   uint64
+	// Not encoded in batches: blocks are sequenced by the inbox's sequencer,
+	// which the inbox commits to in each context hash (see `SetSequencer`).
+	Coinbase common.Address
 }
 
 type DecodeTxBatchError struct{ msg string }
@@ -40,23 +45,113 @@ func (e *DecodeTxBatchError) Error() string {
 	return fmt.Sprintf("Failed to create TxBatch from decoded tx data - %s", e.msg)
 }
 
-func NewTxBatch(blocks []*types.Block, maxBatchSize uint64) *TxBatch {
-	// TODO: handle maxBatchSize constraint
-	var contexts []SequenceContext
-	var txs []*types.Transaction
-	gasUsed := new(big.Int)
+// Conservative estimates of the L1 gas used by `SequencerInbox.appendTxBatch`, excluding calldata
+const (
+	batchBaseL1Gas = 21000 + 60000 // Intrinsic gas, storage writes (inbox size, accumulator) and event
+	contextL1Gas   = 500           // Per block context: context hash
+	txL1Gas        = 1000          // Per tx: tx data hash, accumulator hash
+	calldataL1Gas  = 16            // Per calldata byte, assuming all non-zero
+)
+
+// NewTxBatch creates a batch of all the given blocks, regardless of its size.
+func NewTxBatch(blocks []*types.Block) (*TxBatch, error) {
+	batch := &TxBatch{GasUsed: new(big.Int)}
 	for _, block := range blocks {
-		blockTxs := block.Transactions()
-		ctx := SequenceContext{
-			NumTxs:      uint64(len(blockTxs)),
-			BlockNumber: block.Number().Uint64(), // TODO just use bigint
-			Timestamp:   block.Time(),
+		if err := batch.addBlock(block); err != nil {
+			return nil, err
+		}
+	}
+	return batch, nil
+}
+
+// NewTxBatches splits blocks into batches, on block boundaries, such that the calldata size of
+// each batch is at most `maxBatchSize` bytes and its estimated L1 gas at most `maxBatchGas`.
+// A limit of 0 means no limit. Each batch contains at least one tx, so a single block exceeding
+// the limits (or a run of empty blocks before it) is returned as a batch of its own.
+func NewTxBatches(blocks []*types.Block, maxBatchSize, maxBatchGas uint64) ([]*TxBatch, error) {
+	var batches []*TxBatch
+	batch := &TxBatch{GasUsed: new(big.Int)}
+	for _, block := range blocks {
+		if len(batch.Txs) > 0 {
+			blockDataLen, err := encodedLen(block.Transactions())
+			if err != nil {
+				return nil, err
+			}
+			numContexts := len(batch.Contexts) + 1
+			numTxs := len(batch.Txs) + len(block.Transactions())
+			size := calldataSize(numContexts, numTxs, batch.dataLen+blockDataLen)
+			gas := l1Gas(numContexts, numTxs, size)
+			if (maxBatchSize > 0 && size > maxBatchSize) || (maxBatchGas > 0 && gas > maxBatchGas) {
+				batches = append(batches, batch)
+				batch = &TxBatch{GasUsed: new(big.Int)}
+			}
+		}
+		if err := batch.addBlock(block); err != nil {
+			return nil, err
+		}
+	}
+	if len(batch.Blocks) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+func (b *TxBatch) addBlock(block *types.Block) error {
+	blockTxs := block.Transactions()
+	blockDataLen, err := encodedLen(blockTxs)
+	if err != nil {
+		return err
+	}
+	ctx := SequenceContext{
+		NumTxs:      uint64(len(blockTxs)),
+		BlockNumber: block.Number().Uint64(), // TODO just use bigint
+		Timestamp:   block.Time(),
+		Coinbase:    block.Coinbase(),
+	}
+	b.Blocks = append(b.Blocks, block)
+	b.Contexts = append(b.Contexts, ctx)
+	b.Txs = append(b.Txs, blockTxs...)
+	b.GasUsed.Add(b.GasUsed, new(big.Int).SetUint64(block.GasUsed()))
+	b.dataLen += blockDataLen
+	return nil
+}
+
+// Size returns the calldata size in bytes of the `appendTxBatch` call for this batch.
+func (b *TxBatch) Size() uint64 {
+	return calldataSize(len(b.Contexts), len(b.Txs), b.dataLen)
+}
+
+// L1Gas returns a conservative estimate of the L1 gas used by the `appendTxBatch` call for this batch.
+func (b *TxBatch) L1Gas() uint64 {
+	return l1Gas(len(b.Contexts), len(b.Txs), b.Size())
+}
+
+// Total length of the txs, as encoded in the batch.
+func encodedLen(txs types.Transactions) (uint64, error) {
+	buf := new(bytes.Buffer)
+	for _, tx := range txs {
+		if err := writeTx(buf, tx); err != nil {
+			return 0, err
 		}
-		contexts = append(contexts, ctx)
-		txs = append(txs, blockTxs...)
-		gasUsed.Add(gasUsed, new(big.Int).SetUint64(block.GasUsed()))
 	}
-	return &TxBatch{blocks, contexts, txs, gasUsed}
+	return uint64(buf.Len()), nil
+}
+
+// ABI-encoded size of `appendTxBatch(uint256[] contexts, uint256[] txLengths, bytes txBatch)`.
+func calldataSize(numContexts, numTxs int, dataLen uint64) uint64 {
+	size := uint64(4 + 3*32)              // Selector and offsets
+	size += 32 + 3*32*uint64(numContexts) // contexts
+	size += 32 + 32*uint64(numTxs)        // txLengths
+	size += 32 + (dataLen+31)/32*32       // txBatch
+	return size
+}
+
+func l1Gas(numContexts, numTxs int, calldataSize uint64) uint64 {
+	return batchBaseL1Gas + contextL1Gas*uint64(numContexts) + txL1Gas*uint64(numTxs) + calldataL1Gas*calldataSize
+}
+
+func (b *TxBatch) FirstBlockNumber() uint64 {
+	return b.Contexts[0].BlockNumber
 }
 
 func (b *TxBatch) LastBlockNumber() uint64 {
@@ -90,6 +185,14 @@ func (b *TxBatch) SerializeToArgs() ([]*big.Int, []*big.Int, []byte, error) {
 	return contexts, txLengths, buf.Bytes(), nil
 }
 
+// SetSequencer sets the coinbase of all blocks in a decoded batch to the
+// sequencer that appended it to the inbox.
+func (b *TxBatch) SetSequencer(sequencer common.Address) {
+	for i := range b.Contexts {
+		b.Contexts[i].Coinbase = sequencer
+	}
+}
+
 // Splits batch into blocks
 func (b *TxBatch) SplitToBlocks() []*SequenceBlock {
 	txNum := 0
@@ -154,7 +257,14 @@ func TxBatchFromDecoded(decoded []interface{}) (*TxBatch, error) {
 		}
 		ctxs = append(ctxs, ctx)
 		for j := uint64(0); j < ctx.NumTxs; j++ {
-			raw := txBatch[batchOffset : batchOffset+txLengths[numTxs].Uint64()]
+			if numTxs >= uint64(len(txLengths)) {
+				return nil, &DecodeTxBatchError{fmt.Sprintf("missing length of tx %d", numTxs)}
+			}
+			txLen := txLengths[numTxs]
+			if !txLen.IsUint64() || txLen.Uint64() > uint64(len(txBatch))-batchOffset {
+				return nil, &DecodeTxBatchError{fmt.Sprintf("tx length %v overflows batch", txLen)}
+			}
+			raw := txBatch[batchOffset : batchOffset+txLen.Uint64()]
 			var tx types.Transaction
 			err := rlp.DecodeBytes(raw, &tx)
 			if err != nil {
@@ -0,0 +1,290 @@
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/specularl2/specular/clients/geth/specular/bindings"
+)
+
+var (
+	testChainID = big.NewInt(13527)
+	testSigner  = types.LatestSignerForChainID(testChainID)
+	testToken   = common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	testKeys    = []string{
+		"b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291",
+		"8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a",
+		"49a7b37aa6f6645917e7b807e9d1c00d4fa71f18343b0d4122a4d2df64dd6fee",
+	}
+)
+
+// Generates txs resembling L2 traffic: ERC20 transfers, ETH transfers and larger contract calls.
+type txGenerator struct {
+	keys   []*ecdsa.PrivateKey
+	nonces []uint64
+	count  int
+}
+
+func newTxGenerator(t *testing.T) *txGenerator {
+	g := &txGenerator{nonces: make([]uint64, len(testKeys))}
+	for _, hex := range testKeys {
+		key, err := crypto.HexToECDSA(hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.keys = append(g.keys, key)
+	}
+	return g
+}
+
+func (g *txGenerator) next(t *testing.T) *types.Transaction {
+	sender := g.count % len(g.keys)
+	nonce := g.nonces[sender]
+	g.nonces[sender]++
+	g.count++
+	recipient := common.BigToAddress(big.NewInt(int64(0x1000 + g.count%7)))
+	var inner types.TxData
+	switch g.count % 4 {
+	case 0:
+		inner = &types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(params.GWei),
+			Gas:      21000,
+			To:       &recipient,
+			Value:    big.NewInt(int64(g.count) * params.GWei),
+		}
+	case 3:
+		// Contract call with larger, partly repetitive calldata.
+		data := make([]byte, 4+32*10)
+		copy(data, []byte{0x38, 0xed, 0x17, 0x39})
+		for i := 0; i < 10; i++ {
+			new(big.Int).SetUint64(uint64(g.count*1000 + i)).FillBytes(data[4+32*i : 4+32*(i+1)])
+		}
+		inner = &types.DynamicFeeTx{
+			ChainID:   testChainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(params.GWei),
+			GasFeeCap: big.NewInt(2 * params.GWei),
+			Gas:       250000,
+			To:        &testToken,
+			Data:      data,
+		}
+	default:
+		// ERC20 transfer(address,uint256)
+		data := make([]byte, 4+32*2)
+		copy(data, []byte{0xa9, 0x05, 0x9c, 0xbb})
+		copy(data[4+12:4+32], recipient.Bytes())
+		big.NewInt(int64(g.count) * 1e15).FillBytes(data[4+32:])
+		inner = &types.DynamicFeeTx{
+			ChainID:   testChainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(params.GWei),
+			GasFeeCap: big.NewInt(2 * params.GWei),
+			Gas:       60000,
+			To:        &testToken,
+			Data:      data,
+		}
+	}
+	tx, err := types.SignNewTx(g.keys[sender], testSigner, inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+// Creates `numBlocks` consecutive blocks with `txsPerBlock` txs each.
+func makeBlocks(t *testing.T, g *txGenerator, numBlocks, txsPerBlock int) []*types.Block {
+	var blocks []*types.Block
+	for i := 0; i < numBlocks; i++ {
+		header := &types.Header{
+			Number:   big.NewInt(int64(100 + i)),
+			Time:     uint64(1670000000 + 2*i),
+			GasLimit: 30000000,
+		}
+		var txs []*types.Transaction
+		for j := 0; j < txsPerBlock; j++ {
+			txs = append(txs, g.next(t))
+		}
+		blocks = append(blocks, types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil)))
+	}
+	return blocks
+}
+
+func inboxABI(t *testing.T) *abi.ABI {
+	inboxAbi, err := bindings.ISequencerInboxMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inboxAbi
+}
+
+// Packs batch arguments into `appendTxBatch` calldata, then decodes them back.
+func roundTripCalldata(t *testing.T, contexts, txLengths []*big.Int, data []byte) (*TxBatch, int) {
+	method := inboxABI(t).Methods["appendTxBatch"]
+	packed, err := method.Inputs.Pack(contexts, txLengths, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := method.Inputs.Unpack(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch, err := TxBatchFromDecoded(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return batch, len(method.ID) + len(packed)
+}
+
+func checkDecodedBatch(t *testing.T, want, got *TxBatch) {
+	t.Helper()
+	if len(got.Contexts) != len(want.Contexts) {
+		t.Fatalf("contexts length mismatch: have %d, want %d", len(got.Contexts), len(want.Contexts))
+	}
+	for i := range want.Contexts {
+		if got.Contexts[i] != want.Contexts[i] {
+			t.Errorf("context %d mismatch: have %+v, want %+v", i, got.Contexts[i], want.Contexts[i])
+		}
+	}
+	if len(got.Txs) != len(want.Txs) {
+		t.Fatalf("txs length mismatch: have %d, want %d", len(got.Txs), len(want.Txs))
+	}
+	for i := range want.Txs {
+		if got.Txs[i].Hash() != want.Txs[i].Hash() {
+			t.Errorf("tx %d mismatch: have %s, want %s", i, got.Txs[i].Hash(), want.Txs[i].Hash())
+		}
+	}
+}
+
+func TestTxBatchRoundTrip(t *testing.T) {
+	tests := []struct {
+		name        string
+		numBlocks   int
+		txsPerBlock int
+	}{
+		{"single tx", 1, 1},
+		{"single block", 1, 20},
+		{"many blocks", 50, 3},
+		{"large", 20, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTxGenerator(t)
+			batch, err := NewTxBatch(makeBlocks(t, g, tt.numBlocks, tt.txsPerBlock))
+			if err != nil {
+				t.Fatal(err)
+			}
+			contexts, txLengths, data, err := batch.SerializeToArgs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, size := roundTripCalldata(t, contexts, txLengths, data)
+			checkDecodedBatch(t, batch, decoded)
+			if uint64(size) != batch.Size() {
+				t.Errorf("size estimate mismatch: have %d, want %d", batch.Size(), size)
+			}
+		})
+	}
+}
+
+func TestNewTxBatchesSplitsOnBlockBoundaries(t *testing.T) {
+	g := newTxGenerator(t)
+	blocks := makeBlocks(t, g, 40, 5)
+	whole, err := NewTxBatch(blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxSize := whole.Size() / 4
+	maxGas := whole.L1Gas() / 3
+	batches, err := NewTxBatches(blocks, maxSize, maxGas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) < 4 {
+		t.Fatalf("expected at least 4 batches, have %d", len(batches))
+	}
+	var next int
+	for i, batch := range batches {
+		if batch.Size() > maxSize {
+			t.Errorf("batch %d exceeds size limit: %d > %d", i, batch.Size(), maxSize)
+		}
+		if batch.L1Gas() > maxGas {
+			t.Errorf("batch %d exceeds gas limit: %d > %d", i, batch.L1Gas(), maxGas)
+		}
+		for _, block := range batch.Blocks {
+			if block.Hash() != blocks[next].Hash() {
+				t.Fatalf("batch %d: block %d out of order", i, block.NumberU64())
+			}
+			next++
+		}
+	}
+	if next != len(blocks) {
+		t.Errorf("batches cover %d blocks, want %d", next, len(blocks))
+	}
+}
+
+func TestNewTxBatchesOversizedBlock(t *testing.T) {
+	g := newTxGenerator(t)
+	blocks := makeBlocks(t, g, 3, 10)
+	// No block fits the limit; each is sent on its own.
+	batches, err := NewTxBatches(blocks, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != len(blocks) {
+		t.Fatalf("expected %d batches, have %d", len(blocks), len(batches))
+	}
+	// Without limits, all blocks are sent in one batch.
+	batches, err = NewTxBatches(blocks, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 1 {
+		t.Fatalf("expected 1 batch, have %d", len(batches))
+	}
+}
+
+func TestDecodeLegacyTxBatchErrors(t *testing.T) {
+	g := newTxGenerator(t)
+	batch, err := NewTxBatch(makeBlocks(t, g, 2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contexts, txLengths, data, err := batch.SerializeToArgs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	overflowing := append([]*big.Int{}, txLengths...)
+	overflowing[len(overflowing)-1] = big.NewInt(int64(len(data)))
+	tests := []struct {
+		name      string
+		contexts  []*big.Int
+		txLengths []*big.Int
+		data      []byte
+	}{
+		{"invalid contexts length", contexts[:len(contexts)-1], txLengths, data},
+		{"missing tx lengths", contexts, txLengths[:len(txLengths)-1], data},
+		{"tx length overflow", contexts, overflowing, data},
+		{"truncated data", contexts, txLengths, data[:len(data)-1]},
+		{"invalid tx", contexts, txLengths, bytes.Repeat([]byte{0xff}, len(data))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := TxBatchFromDecoded([]interface{}{tt.contexts, tt.txLengths, tt.data})
+			if err == nil {
+				t.Fatal("expected decoding error")
+			}
+			if _, ok := err.(*DecodeTxBatchError); !ok {
+				t.Errorf("unexpected error type %T: %v", err, err)
+			}
+		})
+	}
+}
@@ -22,6 +22,8 @@
 
 pragma solidity ^0.8.0;
 
+import "./challenge/IChallenge.sol";
+
 interface IRollup {
     event AssertionCreated(uint256 assertionID, address asserterAddr, bytes32 vmHash);
 
@@ -270,4 +272,11 @@ interface IRollup {
      *
      */
     function setBaseStakeAmount(uint256 newAmount) external;
+
+    /**
+     * @notice Sets the protocol of the challenges created from now on
+     * @param newType New challenge type, symmetric by default
+     *
+     */
+    function setChallengeType(IChallenge.ChallengeType newType) external;
 }
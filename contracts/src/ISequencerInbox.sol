@@ -39,6 +39,12 @@ interface ISequencerInbox is IDAProvider {
     /// @dev Thrown when overflow occurs reading txBatch (likely due to malformed txLengths)
     error TxBatchDataOverflow();
 
+    /**
+     * @notice Gets the accumulator of all transactions up to the end of a batch.
+     * @param batchNumber Number of the batch.
+     */
+    function accumulators(uint256 batchNumber) external view returns (bytes32);
+
     /**
      * @notice Appends a batch of transactions (stored in calldata) and emits a TxBatchAppended event.
      * @param contexts Array of contexts, where each context is represented by a uint256 3-tuple:
@@ -28,6 +28,7 @@ import "@openzeppelin/contracts-upgradeable/access/OwnableUpgradeable.sol";
 
 import "./challenge/IChallenge.sol";
 import "./challenge/SymChallenge.sol";
+import "./challenge/AsymChallenge.sol";
 import "./challenge/ChallengeLib.sol";
 import "./libraries/Errors.sol";
 import "./IDAProvider.sol";
@@ -115,6 +116,9 @@ contract Rollup is RollupBase {
     mapping(address => uint256) public withdrawableFunds; // mapping from addresses to withdrawable funds (won in challenge)
     Zombie[] public zombies; // stores stakers that lost a challenge
 
+    // Config parameters (appended to keep the storage layout of upgrades)
+    IChallenge.ChallengeType public challengeType; // protocol of new challenges
+
     function initialize(
         address _vault,
         address _daProvider,
@@ -318,23 +322,21 @@ contract Rollup is RollupBase {
         // TODO: Calculate upper limit for allowed node proposal time.
 
         // Initialize challenge.
-        SymChallenge challenge = new SymChallenge();
-        address challengeAddr = address(challenge);
+        address challengeAddr = createChallenge(
+            defender, challenger, assertions[parentID].stateHash, assertions[defenderAssertionID].stateHash
+        );
         stakers[challenger].currentChallenge = challengeAddr;
         stakers[defender].currentChallenge = challengeAddr;
         emit AssertionChallenged(defenderAssertionID, challengeAddr);
-        challenge.initialize(
-            defender,
-            challenger,
-            verifier,
-            daProvider,
-            IChallengeResultReceiver(address(this)),
-            assertions[parentID].stateHash,
-            assertions[defenderAssertionID].stateHash
-        );
         return challengeAddr;
     }
 
+    /// @inheritdoc IRollup
+    function setChallengeType(IChallenge.ChallengeType newType) external override onlyOwner {
+        challengeType = newType;
+        emit ConfigurationChanged();
+    }
+
     /// @inheritdoc IRollup
     function confirmFirstUnresolvedAssertion() external override {
         if (lastResolvedAssertionID >= lastCreatedAssertionID) {
@@ -501,6 +503,31 @@ contract Rollup is RollupBase {
         );
     }
 
+    /**
+     * @notice Deploys and initializes a challenge of type `challengeType`.
+     * @param defender Defending staker
+     * @param challenger Challenging staker
+     * @param startStateHash State hash of the parent of the challenged assertions
+     * @param endStateHash State hash of the defender's assertion
+     * @return Address of the challenge
+     */
+    function createChallenge(address defender, address challenger, bytes32 startStateHash, bytes32 endStateHash)
+        private
+        returns (address)
+    {
+        IChallengeResultReceiver receiver = IChallengeResultReceiver(address(this));
+        if (challengeType == IChallenge.ChallengeType.ASYMMETRIC) {
+            AsymChallenge asymChallenge = new AsymChallenge();
+            asymChallenge.initialize(
+                defender, challenger, verifier, daProvider, receiver, startStateHash, endStateHash
+            );
+            return address(asymChallenge);
+        }
+        SymChallenge challenge = new SymChallenge();
+        challenge.initialize(defender, challenger, verifier, daProvider, receiver, startStateHash, endStateHash);
+        return address(challenge);
+    }
+
     /**
      * @notice Deletes the staker from global state. Does not touch assertion staker state.
      * @param stakerAddress Address of the staker to delete
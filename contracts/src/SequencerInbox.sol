@@ -33,7 +33,7 @@ contract SequencerInbox is ISequencerInbox, Initializable, UUPSUpgradeable, Owna
     // Total number of transactions
     uint256 private inboxSize;
     // accumulators[i] is an accumulator of transactions in txBatch i.
-    bytes32[] public accumulators;
+    bytes32[] public override accumulators;
 
     address public sequencerAddress;
 
@@ -23,38 +23,48 @@
 pragma solidity ^0.8.0;
 
 import "./IChallenge.sol";
-import "./verifier/IVerifier.sol";
 import "./ChallengeBase.sol";
+import "./ChallengeLib.sol";
+import "./verifier/IVerifier.sol";
 import "../IDAProvider.sol";
+import "../libraries/DeserializationLib.sol";
 import "../libraries/Errors.sol";
 
 contract AsymChallenge is ChallengeBase, IAsymChallenge {
-    // See `ChallengeLib.computeBisectionHash` for the format of this commitment.
-    bytes32 public bisectionHash;
+    // Previous state consistency could not be verified against `bisectionHash`.
+    error PreviousStateInconsistent();
+    // Tx context consistency could not be verified against ground truth.
+    error TxContextInconsistent();
 
-    // uint256 private constant MAX_MULTISECTION_DEGREE = 2;
+    uint256 private constant MAX_BISECTION_DEGREE = 2;
 
-    modifier onlyDefender() {
-        if (msg.sender != challenger) {
-            revert NotYourTurn();
-        }
-        _;
-    }
-
-    modifier onlyChallenger() {
-        if (msg.sender != challenger) {
-            revert NotYourTurn();
-        }
-        _;
-    }
+    // See `ChallengeLib.computeBisectionHash` for the format of this commitment.
+    bytes32 public bisectionHash;
+    // Segment bisected by the defender (or selected by the challenger, until the defender bisects it).
+    uint256 public challengedSegmentStart;
+    uint256 public challengedSegmentLength;
+    // Start and end state hashes of the segment selected by the challenger.
+    bytes32 private challengedStartHash;
+    bytes32 private challengedEndHash;
 
+    /**
+     * @notice Initializes contract.
+     * @param _defender Defending party.
+     * @param _challenger Challenging party.
+     * @param _verifier Address of the verifier contract.
+     * @param _daProvider DA provider.
+     * @param _resultReceiver Address of contract that will receive the outcome (via callback `completeChallenge`).
+     * @param _startStateHash Bisection root being challenged.
+     * @param _endStateHash Bisection root being challenged.
+     */
     function initialize(
         address _defender,
         address _challenger,
-        bytes32 _bisectionHash,
         IVerifier _verifier,
         IDAProvider _daProvider,
-        IChallengeResultReceiver _resultReceiver
+        IChallengeResultReceiver _resultReceiver,
+        bytes32 _startStateHash,
+        bytes32 _endStateHash
     ) external {
         if (turn != Turn.NoChallenge) {
             revert AlreadyInitialized();
@@ -64,10 +74,11 @@ contract AsymChallenge is ChallengeBase, IAsymChallenge {
         }
         defender = _defender;
         challenger = _challenger;
-        bisectionHash = _bisectionHash;
         verifier = _verifier;
         daProvider = _daProvider;
         resultReceiver = _resultReceiver;
+        challengedStartHash = _startStateHash;
+        challengedEndHash = _endStateHash;
 
         turn = Turn.Defender;
         lastMoveBlock = block.number;
@@ -75,4 +86,112 @@ contract AsymChallenge is ChallengeBase, IAsymChallenge {
         defenderTimeLeft = 10;
         challengerTimeLeft = 10;
     }
+
+    function challengeType() external pure override returns (ChallengeType) {
+        return ChallengeType.ASYMMETRIC;
+    }
+
+    function initializeChallengeLength(uint256 _numSteps) external override onlyOnTurn {
+        if (bisectionHash != 0) {
+            revert AlreadyInitialized();
+        }
+        require(_numSteps > 0, "INVALID_NUM_STEPS");
+        challengedSegmentLength = _numSteps;
+        bisectionHash = ChallengeLib.initialBisectionHash(challengedStartHash, challengedEndHash, _numSteps);
+        emit Bisected(bisectionHash, 0, _numSteps);
+    }
+
+    function bisectExecution(bytes32[] calldata bisection) external override onlyOnTurn {
+        if (bisectionHash == 0) {
+            revert NotInitialized();
+        }
+        require(bisection[0] == challengedStartHash, "INVALID_START");
+        require(bisection[bisection.length - 1] == challengedEndHash, "INVALID_END");
+        // Require that bisection has the correct length. This is only ever less than BISECTION_DEGREE at the last bisection.
+        uint256 target = challengedSegmentLength < MAX_BISECTION_DEGREE ? challengedSegmentLength : MAX_BISECTION_DEGREE;
+        require(bisection.length == target + 1, "CUT_COUNT");
+
+        bisectionHash = ChallengeLib.computeBisectionHash(bisection, challengedSegmentStart, challengedSegmentLength);
+        emit Bisected(bisectionHash, challengedSegmentStart, challengedSegmentLength);
+    }
+
+    function challengeExecution(uint256 challengedSegmentIndex, bytes32[] calldata prevBisection)
+        external
+        override
+        onlyOnTurn
+    {
+        _verifyPrevBisection(challengedSegmentIndex, prevBisection);
+        (uint256 segmentStart, uint256 segmentLength) = _segment(challengedSegmentIndex, prevBisection.length);
+        require(segmentLength > 1, "TOO_SHORT");
+
+        challengedSegmentStart = segmentStart;
+        challengedSegmentLength = segmentLength;
+        challengedStartHash = prevBisection[challengedSegmentIndex - 1];
+        challengedEndHash = prevBisection[challengedSegmentIndex];
+        emit SegmentChallenged(segmentStart, segmentLength);
+    }
+
+    function verifyOneStepProof(
+        bytes calldata oneStepProof,
+        bytes calldata txInclusionProof,
+        VerificationContextLib.RawContext calldata ctx,
+        uint256 challengedStepIndex,
+        bytes32[] calldata prevBisection
+    ) external override onlyOnTurn {
+        _verifyPrevBisection(challengedStepIndex, prevBisection);
+        (, uint256 segmentLength) = _segment(challengedStepIndex, prevBisection.length);
+        // Require that the challenged segment is a single step.
+        require(segmentLength == 1, "BISECTION_INCOMPLETE");
+        {
+            // Verify tx inclusion.
+            daProvider.verifyTxInclusion(ctx.encodedTx, txInclusionProof);
+            // Verify tx context consistency.
+            // TODO: leaky abstraction (assumes `txInclusionProof` structure).
+            (, bytes32 txContextHash) = DeserializationLib.deserializeBytes32(txInclusionProof, 0);
+            if (VerificationContextLib.txContextHash(ctx) != txContextHash) {
+                revert TxContextInconsistent();
+            }
+        }
+        // Verify OSP.
+        bytes32 endHash = verifier.verifyOneStepProof(prevBisection[challengedStepIndex - 1], ctx, oneStepProof);
+        // The challenger wins iff the end state differs from the defender's.
+        if (endHash != prevBisection[challengedStepIndex]) {
+            _challengerWin(CompletionReason.OSP_VERIFIED);
+        } else {
+            _asserterWin(CompletionReason.OSP_VERIFIED);
+        }
+    }
+
+    /**
+     * @notice Verifies `prevBisection` against the last bisection committed by the defender.
+     */
+    function _verifyPrevBisection(uint256 challengedSegmentIndex, bytes32[] calldata prevBisection) private view {
+        bytes32 prevHash =
+            ChallengeLib.computeBisectionHash(prevBisection, challengedSegmentStart, challengedSegmentLength);
+        if (prevHash != bisectionHash) {
+            revert PreviousStateInconsistent();
+        }
+        require(challengedSegmentIndex > 0 && challengedSegmentIndex < prevBisection.length, "INVALID_INDEX");
+    }
+
+    /**
+     * @notice Computes the start/length of a segment of the last bisection.
+     */
+    function _segment(uint256 challengedSegmentIndex, uint256 bisectionLength)
+        private
+        view
+        returns (uint256 segmentStart, uint256 segmentLength)
+    {
+        segmentStart = challengedSegmentStart;
+        segmentLength = challengedSegmentLength;
+        if (bisectionLength > 2) {
+            // bisectionLength == 2 means first round
+            uint256 firstSegmentLength = ChallengeLib.firstSegmentLength(segmentLength, MAX_BISECTION_DEGREE);
+            uint256 otherSegmentLength = ChallengeLib.otherSegmentLength(segmentLength, MAX_BISECTION_DEGREE);
+            if (challengedSegmentIndex > 1) {
+                segmentStart += firstSegmentLength + otherSegmentLength * (challengedSegmentIndex - 2);
+            }
+            segmentLength = challengedSegmentIndex == 1 ? firstSegmentLength : otherSegmentLength;
+        }
+    }
 }
@@ -40,6 +40,11 @@ interface IChallenge {
         TIMEOUT // Loser timed out before completing their round.
     }
 
+    enum ChallengeType {
+        SYMMETRIC, // See `ISymChallenge`.
+        ASYMMETRIC // See `IAsymChallenge`.
+    }
+
     event Completed(address winner, address loser, CompletionReason reason);
 
     event Bisected(bytes32 challengeState, uint256 challengedSegmentStart, uint256 challengedSegmentLength);
@@ -63,6 +68,11 @@ interface IChallenge {
     function currentResponder() external view returns (address);
 
     function currentResponderTimeLeft() external view returns (uint256);
+
+    /**
+     * @notice Returns the challenge protocol implemented by this contract.
+     */
+    function challengeType() external pure returns (ChallengeType);
 }
 
 /**
@@ -121,7 +131,54 @@ interface ISymChallenge is IChallenge {
     ) external;
 }
 
-// Assymetric challenge protocol.
+/**
+ * Asymmetric challenge protocol.
+ * @notice Only the defender bisects; the challenger selects a segment of each bisection it disagrees with,
+ * until the selected segment is a single step, which the challenger proves.
+ * Protocol execution:
+ * `initialize` (challenger, via Rollup) ->
+ * `initializeChallengeLength` (defender) ->
+ * `challengeExecution` (challenger), `bisectExecution` (defender) -- alternating ->
+ * `verifyOneStepProof` (challenger) ->
+ * `IResultReceiver.completeChallenge`
+ */
 interface IAsymChallenge is IChallenge {
-// TODO.
+    event SegmentChallenged(uint256 challengedSegmentStart, uint256 challengedSegmentLength);
+
+    /**
+     * @notice Initializes the length of the challenge. Must be called by defender before bisection rounds begin.
+     * @param _numSteps Number of steps executed from the start of the assertion to its end.
+     * If this parameter is incorrect, the defender will be slashed (assuming successful execution of the protocol by the challenger).
+     */
+    function initializeChallengeLength(uint256 _numSteps) external;
+
+    /**
+     * @notice Bisects the segment selected by the challenger (see `SegmentChallenged`). Called by the defender.
+     * @param bisection Bisection of challenged segment. Each element is a state hash (see `ChallengeLib.stateHash`).
+     * The first and last elements must be the start and end state hashes of the challenged segment.
+     * Must be of length MAX_BISECTION_DEGREE + 1, unless the segment is shorter.
+     */
+    function bisectExecution(bytes32[] calldata bisection) external;
+
+    /**
+     * @notice Selects a segment of the last bisection to be bisected next. Called by the challenger.
+     * @param challengedSegmentIndex Index into `prevBisection`. Must be greater than 0 (since the first is agreed upon).
+     * The selected segment must be longer than a single step (single steps are proven with `verifyOneStepProof`).
+     * @param prevBisection Bisection in the preceding round.
+     */
+    function challengeExecution(uint256 challengedSegmentIndex, bytes32[] calldata prevBisection) external;
+
+    /**
+     * @notice Verifies one step proof of a single-step segment of the last bisection and completes challenge protocol.
+     * Called by the challenger. The challenger wins if the proven end state differs from the defender's.
+     * @param challengedStepIndex Index into `prevBisection`. Must be greater than 0 (since the first is agreed upon).
+     * @param prevBisection Bisection in the preceding round. The challenged segment must be a single step.
+     */
+    function verifyOneStepProof(
+        bytes calldata oneStepProof,
+        bytes calldata txInclusionProof,
+        VerificationContextLib.RawContext calldata ctx,
+        uint256 challengedStepIndex,
+        bytes32[] calldata prevBisection
+    ) external;
 }
@@ -94,6 +94,10 @@ contract SymChallenge is ChallengeBase, ISymChallenge {
         challengerTimeLeft = 10;
     }
 
+    function challengeType() external pure override returns (ChallengeType) {
+        return ChallengeType.SYMMETRIC;
+    }
+
     function initializeChallengeLength(uint256 _numSteps) external override onlyOnTurn {
         if (bisectionHash != 0) {
             revert AlreadyInitialized();
@@ -21,11 +21,73 @@ pragma solidity ^0.8.0;
 import "./VerificationContextLib.sol";
 
 interface IVerifier {
+    /// @dev Thrown when the verifier cannot simulate the step proven by a one-step proof.
+    error OneStepProofUnsupported();
+
     /**
      * @notice Simulates and verifies execution of a single EVM step.
      * @param startStateHash The state hash before the step.
      * @param ctx Associated transaction and its context (already verified to be consistent).
-     * @param encodedProof The one-step proof. TODO: describe format.
+     * @param encodedProof The one-step proof: the concatenation of the proofs below, all integers big-endian.
+     *
+     * Step 0 (start of the transaction, `startStateHash` is the world state root):
+     *   AccountProof(sender) || AccountProof(recipient or created contract) || CodeProof(recipient)
+     *
+     * Step k >= 1 (before the k-th executed instruction of the transaction):
+     *   IntraStateProof || DataProof(code, [pc, pc + 1 + immediate size)) || StackProof || op-specific proofs
+     *   || frame-end proofs (if the step halts the call frame, or faults)
+     * A faulting step has no op-specific proofs.
+     *
+     * End of the trace (`startStateHash` is the world state root after the last transaction of the assertion,
+     * `ctx` is that last transaction): empty proof. Only reached when the trace is padded to a longer challenge
+     * length; the step leaves the state unchanged.
+     *
+     * Op-specific proofs (stack items are numbered from the top, memory proofs are against the state's memory):
+     *   CALLDATALOAD:       DataProof(input, [s0, s0 + 32))
+     *   CALLDATACOPY, CODECOPY, RETURNDATACOPY:
+     *                       DataProof(source, [s1, s1 + s2)) || write DataProof(memory, [s0, s0 + s2))
+     *   EXTCODECOPY:        AccountProof(s0) || CodeProof(s0) || write DataProof(memory, [s1, s1 + s3))
+     *   MLOAD:              DataProof(memory, [s0, s0 + 32))
+     *   MSTORE, MSTORE8:    write DataProof(memory, [s0, s0 + 32 or 1))
+     *   KECCAK256, LOGn, RETURN, REVERT:
+     *                       DataProof(memory, [s0, s0 + s1))
+     *   SLOAD, SSTORE:      AccountProof(contract) || StorageProof(s0)
+     *   BALANCE, EXTCODEHASH:
+     *                       AccountProof(s0)
+     *   EXTCODESIZE:        AccountProof(s0) || CodeProof(s0)
+     *   SELFBALANCE:        AccountProof(contract)
+     *   CALL, CALLCODE, DELEGATECALL, STATICCALL:
+     *                       DataProof(memory, arguments) || AccountProof(contract) || AccountProof(s1) || CodeProof(s1)
+     *   CREATE, CREATE2:    DataProof(memory, [s1, s1 + s2)) || AccountProof(contract) || AccountProof(new contract)
+     *   SELFDESTRUCT:       AccountProof(contract) || AccountProof(s0)
+     *
+     * Frame-end proofs:
+     *   depth > 1: IntraStateProof(caller state at the call)
+     *              || write DataProof(caller memory, [out, out + min(outSize, output size))) (RETURN/REVERT with output)
+     *   depth 1:   AccountProof(origin) || AccountProof(coinbase)
+     *
+     * Encodings:
+     *   IntraStateProof: blockNumber (uint64) || txIndex (uint64) || depth (uint16) || gas (uint64) || refund (uint64)
+     *     || lastDepthHash (bytes32) || contract (address) || caller (address) || value (uint256) || callFlag (uint8)
+     *     || out (uint64) || outSize (uint64) || pc (uint64) || codeRoot (bytes32) || codeSize (uint64)
+     *     || stackSize (uint64) || stackHash (bytes32) || memorySize (uint64) || memoryRoot (bytes32)
+     *     || inputDataSize (uint64) || inputDataRoot (bytes32) || returnDataSize (uint64) || returnDataRoot (bytes32)
+     *     || stateRoot (bytes32). Its keccak256 is the state hash.
+     *     Data (code, memory, input, return data) is committed by a binary Merkle tree whose leaves are its 32-byte words
+     *     (zero-padded to a power of two) and whose nodes are keccak256(left || right); the root of empty data is 0.
+     *     The stack hash of the empty stack is 0, pushing `x` onto a stack with hash `h` gives keccak256(h || x).
+     *   DataProof: number of words (uint64) || (index (uint64) || word (bytes32) || number of siblings (uint8)
+     *     || siblings (bytes32 each, leaves up)) for each word within the data, in increasing index order.
+     *     Words of a write are each proven against the root after writing the previous ones.
+     *   StackProof: number of items (uint8) || hash of the stack below them (bytes32) || items (uint256 each, top first).
+     *   AccountProof: address (address) || nonce (uint64) || balance (uint256) || storageRoot (bytes32)
+     *     || codeHash (bytes32) || number of trie nodes (uint64) || (node size (uint64) || RLP node) for each node.
+     *   StorageProof: key (bytes32) || value (bytes32) || number of trie nodes (uint64) || (node size (uint64) || RLP node)...
+     *   CodeProof: code size (uint64) || code.
+     *
+     * This layout is the one produced by the off-chain prover (`proof/prover` in the Geth client). It has no
+     * on-chain decoder yet: `Verifier` reverts with `OneStepProofUnsupported` for every proof, so no one-step proof
+     * can currently be verified on L1 and a challenge reduced to a single step is resolved by its deadline.
      */
     function verifyOneStepProof(
         bytes32 startStateHash,
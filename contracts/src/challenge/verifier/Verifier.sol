@@ -36,12 +36,17 @@ contract Verifier is IVerifier, Initializable, UUPSUpgradeable, OwnableUpgradeab
 
     function _authorizeUpgrade(address) internal override onlyOwner {}
 
+    /**
+     * @notice Always reverts: proofs are not decoded on-chain yet (see `IVerifier.verifyOneStepProof` for their layout).
+     * Returning an arbitrary end state instead would let any party win a challenge by submitting a one-step proof,
+     * so single-step disputes are left to the challenge deadline until the proofs are simulated here.
+     */
     function verifyOneStepProof(bytes32, VerificationContextLib.RawContext calldata, bytes calldata)
         external
         pure
         override
-        returns (bytes32 endStateHash)
+        returns (bytes32)
     {
-        endStateHash = 0x0;
+        revert OneStepProofUnsupported();
     }
 }
@@ -0,0 +1,441 @@
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+ * Modifications Copyright 2022, Specular contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+pragma solidity ^0.8.13;
+
+import "forge-std/Test.sol";
+import "../src/libraries/Errors.sol";
+import "@openzeppelin/contracts/proxy/ERC1967/ERC1967Proxy.sol";
+import {IDAProvider} from "../src/IDAProvider.sol";
+import {Rollup} from "../src/Rollup.sol";
+import {AsymChallenge} from "../src/challenge/AsymChallenge.sol";
+import {IChallenge, IChallengeResultReceiver} from "../src/challenge/IChallenge.sol";
+import {IVerifier} from "../src/challenge/verifier/IVerifier.sol";
+import {VerificationContextLib} from "../src/challenge/verifier/VerificationContextLib.sol";
+import {ChallengeLib} from "../src/challenge/ChallengeLib.sol";
+
+// Verifier of a toy VM, whose step from state `s` ends in state `keccak256(s)`.
+contract MockVerifier is IVerifier {
+    function verifyOneStepProof(bytes32 startStateHash, VerificationContextLib.RawContext calldata, bytes calldata)
+        external
+        pure
+        override
+        returns (bytes32)
+    {
+        return keccak256(abi.encodePacked(startStateHash));
+    }
+}
+
+// DA provider including every transaction.
+contract MockDAProvider is IDAProvider {
+    function getInboxSize() external pure override returns (uint256) {
+        return type(uint256).max;
+    }
+
+    function verifyTxInclusion(bytes memory, bytes calldata) external pure override {}
+}
+
+contract AsymChallengeBaseSetup is Test {
+    event Bisected(bytes32 challengeState, uint256 challengedSegmentStart, uint256 challengedSegmentLength);
+    event SegmentChallenged(uint256 challengedSegmentStart, uint256 challengedSegmentLength);
+    event Completed(address winner, address loser, IChallenge.CompletionReason reason);
+
+    address internal defender = address(0xdef);
+    address internal challenger = address(0xc4a);
+    bytes32 internal startHash = keccak256("start");
+
+    MockVerifier internal verifier = new MockVerifier();
+    MockDAProvider internal daProvider = new MockDAProvider();
+
+    // Returns the `i`-th state of the toy VM trace starting at `startHash`.
+    function stateHash(uint256 i) internal view returns (bytes32 hash) {
+        hash = startHash;
+        for (; i > 0; i--) {
+            hash = keccak256(abi.encodePacked(hash));
+        }
+    }
+
+    function pair(bytes32 a, bytes32 b) internal pure returns (bytes32[] memory bisection) {
+        bisection = new bytes32[](2);
+        bisection[0] = a;
+        bisection[1] = b;
+    }
+
+    function triple(bytes32 a, bytes32 b, bytes32 c) internal pure returns (bytes32[] memory bisection) {
+        bisection = new bytes32[](3);
+        bisection[0] = a;
+        bisection[1] = b;
+        bisection[2] = c;
+    }
+
+    function txContext() internal pure returns (VerificationContextLib.RawContext memory ctx) {
+        ctx.encodedTx = hex"01";
+        ctx.l2BlockCoinbase = address(0xc01);
+        ctx.l2BlockNumber = 1;
+        ctx.l2BlockTimestamp = 10;
+    }
+
+    // Inclusion proof committing to the context of `ctx`.
+    function inclusionProof(VerificationContextLib.RawContext memory ctx) internal pure returns (bytes memory) {
+        return abi.encodePacked(
+            keccak256(abi.encodePacked(ctx.l2BlockCoinbase, ctx.l2BlockNumber, ctx.l2BlockTimestamp))
+        );
+    }
+}
+
+contract AsymChallengeTest is AsymChallengeBaseSetup, IChallengeResultReceiver {
+    AsymChallenge internal challenge;
+
+    address internal winner;
+    address internal loser;
+
+    function completeChallenge(address _winner, address _loser) external override {
+        winner = _winner;
+        loser = _loser;
+    }
+
+    // The defender claims a trace of 4 steps ending at `endHash`.
+    function setUpChallenge(bytes32 endHash) internal {
+        challenge = new AsymChallenge();
+        challenge.initialize(defender, challenger, verifier, daProvider, this, startHash, endHash);
+    }
+
+    function initializeLength(uint256 numSteps) internal {
+        vm.prank(defender);
+        challenge.initializeChallengeLength(numSteps);
+    }
+
+    // Plays the 4-step challenge down to the single step [3, 4], claimed to end at `endHash`.
+    // Returns the last bisection.
+    function playToLastStep(bytes32 endHash) internal returns (bytes32[] memory bisection) {
+        initializeLength(4);
+        vm.prank(challenger);
+        challenge.challengeExecution(1, pair(startHash, endHash));
+        bisection = triple(startHash, stateHash(2), endHash);
+        vm.prank(defender);
+        challenge.bisectExecution(bisection);
+        vm.prank(challenger);
+        challenge.challengeExecution(2, bisection);
+        bisection = triple(stateHash(2), stateHash(3), endHash);
+        vm.prank(defender);
+        challenge.bisectExecution(bisection);
+    }
+
+    function verifyLastStep(bytes32[] memory bisection) internal {
+        VerificationContextLib.RawContext memory ctx = txContext();
+        vm.prank(challenger);
+        challenge.verifyOneStepProof("", inclusionProof(ctx), ctx, 2, bisection);
+    }
+
+    function test_initialize() public {
+        setUpChallenge(stateHash(4));
+        assertEq(uint256(challenge.challengeType()), uint256(IChallenge.ChallengeType.ASYMMETRIC));
+        assertEq(challenge.defender(), defender);
+        assertEq(challenge.challenger(), challenger);
+        assertEq(challenge.currentResponder(), defender);
+
+        vm.expectRevert(IChallenge.AlreadyInitialized.selector);
+        challenge.initialize(defender, challenger, verifier, daProvider, this, startHash, stateHash(4));
+    }
+
+    function test_initializeRevertsIf_zeroAddress() public {
+        challenge = new AsymChallenge();
+        vm.expectRevert(ZeroAddress.selector);
+        challenge.initialize(address(0), challenger, verifier, daProvider, this, startHash, stateHash(4));
+    }
+
+    function test_initializeChallengeLength() public {
+        setUpChallenge(stateHash(4));
+        vm.expectEmit(false, false, false, true);
+        emit Bisected(ChallengeLib.initialBisectionHash(startHash, stateHash(4), 4), 0, 4);
+        initializeLength(4);
+        assertEq(challenge.bisectionHash(), ChallengeLib.initialBisectionHash(startHash, stateHash(4), 4));
+        assertEq(challenge.challengedSegmentStart(), 0);
+        assertEq(challenge.challengedSegmentLength(), 4);
+        assertEq(challenge.currentResponder(), challenger);
+
+        // The defender cannot change the length on its next turn.
+        vm.prank(challenger);
+        challenge.challengeExecution(1, pair(startHash, stateHash(4)));
+        vm.expectRevert(IChallenge.AlreadyInitialized.selector);
+        initializeLength(5);
+    }
+
+    function test_initializeChallengeLengthRevertsIf_zero() public {
+        setUpChallenge(stateHash(4));
+        vm.expectRevert("INVALID_NUM_STEPS");
+        initializeLength(0);
+    }
+
+    function test_movesRevertIf_notOnTurn() public {
+        setUpChallenge(stateHash(4));
+        vm.prank(challenger);
+        vm.expectRevert(IChallenge.NotYourTurn.selector);
+        challenge.initializeChallengeLength(4);
+
+        initializeLength(4);
+        vm.prank(defender);
+        vm.expectRevert(IChallenge.NotYourTurn.selector);
+        challenge.challengeExecution(1, pair(startHash, stateHash(4)));
+    }
+
+    function test_bisectExecutionRevertsIf_notInitialized() public {
+        setUpChallenge(stateHash(4));
+        vm.prank(defender);
+        vm.expectRevert(IChallenge.NotInitialized.selector);
+        challenge.bisectExecution(triple(startHash, stateHash(2), stateHash(4)));
+    }
+
+    function test_bisectExecution() public {
+        setUpChallenge(stateHash(4));
+        initializeLength(4);
+        vm.prank(challenger);
+        vm.expectEmit(false, false, false, true);
+        emit SegmentChallenged(0, 4);
+        challenge.challengeExecution(1, pair(startHash, stateHash(4)));
+        assertEq(challenge.currentResponder(), defender);
+
+        bytes32[] memory bisection = triple(startHash, stateHash(2), stateHash(4));
+        vm.prank(defender);
+        vm.expectEmit(false, false, false, true);
+        emit Bisected(ChallengeLib.computeBisectionHash(bisection, 0, 4), 0, 4);
+        challenge.bisectExecution(bisection);
+        assertEq(challenge.currentResponder(), challenger);
+
+        // The challenger selects the second half.
+        vm.prank(challenger);
+        vm.expectEmit(false, false, false, true);
+        emit SegmentChallenged(2, 2);
+        challenge.challengeExecution(2, bisection);
+        assertEq(challenge.challengedSegmentStart(), 2);
+        assertEq(challenge.challengedSegmentLength(), 2);
+    }
+
+    function test_bisectExecutionRevertsIf_invalidBisection() public {
+        setUpChallenge(stateHash(4));
+        initializeLength(4);
+        vm.prank(challenger);
+        challenge.challengeExecution(1, pair(startHash, stateHash(4)));
+
+        vm.startPrank(defender);
+        vm.expectRevert("INVALID_START");
+        challenge.bisectExecution(triple(stateHash(1), stateHash(2), stateHash(4)));
+        vm.expectRevert("INVALID_END");
+        challenge.bisectExecution(triple(startHash, stateHash(2), stateHash(3)));
+        vm.expectRevert("CUT_COUNT");
+        challenge.bisectExecution(pair(startHash, stateHash(4)));
+        vm.stopPrank();
+    }
+
+    function test_challengeExecutionRevertsIf_invalidSegment() public {
+        setUpChallenge(stateHash(4));
+        initializeLength(4);
+
+        vm.startPrank(challenger);
+        vm.expectRevert(AsymChallenge.PreviousStateInconsistent.selector);
+        challenge.challengeExecution(1, pair(startHash, stateHash(3)));
+        vm.expectRevert("INVALID_INDEX");
+        challenge.challengeExecution(0, pair(startHash, stateHash(4)));
+        vm.expectRevert("INVALID_INDEX");
+        challenge.challengeExecution(2, pair(startHash, stateHash(4)));
+        vm.stopPrank();
+    }
+
+    function test_challengeExecutionRevertsIf_singleStep() public {
+        setUpChallenge(stateHash(4));
+        bytes32[] memory bisection = playToLastStep(stateHash(4));
+        vm.prank(challenger);
+        vm.expectRevert("TOO_SHORT");
+        challenge.challengeExecution(2, bisection);
+    }
+
+    function test_verifyOneStepProofRevertsIf_bisectionIncomplete() public {
+        setUpChallenge(stateHash(4));
+        initializeLength(4);
+        VerificationContextLib.RawContext memory ctx = txContext();
+        vm.prank(challenger);
+        vm.expectRevert("BISECTION_INCOMPLETE");
+        challenge.verifyOneStepProof("", inclusionProof(ctx), ctx, 1, pair(startHash, stateHash(4)));
+    }
+
+    function test_verifyOneStepProofRevertsIf_txContextInconsistent() public {
+        setUpChallenge(stateHash(4));
+        bytes32[] memory bisection = playToLastStep(stateHash(4));
+        VerificationContextLib.RawContext memory ctx = txContext();
+        bytes memory proof = inclusionProof(ctx);
+        ctx.l2BlockNumber++;
+        vm.prank(challenger);
+        vm.expectRevert(AsymChallenge.TxContextInconsistent.selector);
+        challenge.verifyOneStepProof("", proof, ctx, 2, bisection);
+    }
+
+    function test_defenderWinsIf_stepVerified() public {
+        setUpChallenge(stateHash(4));
+        bytes32[] memory bisection = playToLastStep(stateHash(4));
+        vm.expectEmit(false, false, false, true);
+        emit Completed(defender, challenger, IChallenge.CompletionReason.OSP_VERIFIED);
+        verifyLastStep(bisection);
+        assertEq(winner, defender);
+        assertEq(loser, challenger);
+    }
+
+    function test_challengerWinsIf_stepDisproven() public {
+        bytes32 wrongEnd = keccak256("wrong end");
+        setUpChallenge(wrongEnd);
+        bytes32[] memory bisection = playToLastStep(wrongEnd);
+        vm.expectEmit(false, false, false, true);
+        emit Completed(challenger, defender, IChallenge.CompletionReason.OSP_VERIFIED);
+        verifyLastStep(bisection);
+        assertEq(winner, challenger);
+        assertEq(loser, defender);
+    }
+
+    // A single-step challenge is proven without bisection.
+    function test_singleStepChallenge() public {
+        setUpChallenge(keccak256("wrong end"));
+        initializeLength(1);
+        VerificationContextLib.RawContext memory ctx = txContext();
+        vm.prank(challenger);
+        challenge.verifyOneStepProof("", inclusionProof(ctx), ctx, 1, pair(startHash, keccak256("wrong end")));
+        assertEq(winner, challenger);
+    }
+
+    function test_challengerWinsIf_defenderTimesOut() public {
+        setUpChallenge(stateHash(4));
+        vm.expectRevert(IChallenge.DeadlineNotPassed.selector);
+        challenge.timeout();
+
+        vm.roll(block.number + challenge.defenderTimeLeft() + 1);
+        vm.prank(defender);
+        vm.expectRevert(IChallenge.DeadlineExpired.selector);
+        challenge.initializeChallengeLength(4);
+
+        vm.expectEmit(false, false, false, true);
+        emit Completed(challenger, defender, IChallenge.CompletionReason.TIMEOUT);
+        challenge.timeout();
+        assertEq(winner, challenger);
+        assertEq(loser, defender);
+    }
+
+    function test_defenderWinsIf_challengerTimesOut() public {
+        setUpChallenge(stateHash(4));
+        initializeLength(4);
+        vm.roll(block.number + challenge.challengerTimeLeft() + 1);
+        vm.prank(challenger);
+        vm.expectRevert(IChallenge.DeadlineExpired.selector);
+        challenge.challengeExecution(1, pair(startHash, stateHash(4)));
+
+        vm.expectEmit(false, false, false, true);
+        emit Completed(defender, challenger, IChallenge.CompletionReason.TIMEOUT);
+        challenge.timeout();
+        assertEq(winner, defender);
+        assertEq(loser, challenger);
+    }
+
+    // Time spent on a move is deducted from the mover's remaining time.
+    function test_moveDeductsTimeLeft() public {
+        setUpChallenge(stateHash(4));
+        uint256 timeLeft = challenge.defenderTimeLeft();
+        vm.roll(block.number + 3);
+        initializeLength(4);
+        assertEq(challenge.defenderTimeLeft(), timeLeft - 3);
+        assertEq(challenge.lastMoveBlock(), block.number);
+    }
+}
+
+contract RollupAsymChallengeTest is AsymChallengeBaseSetup {
+    event ConfigurationChanged();
+
+    Rollup internal rollup;
+    address internal owner = address(0x0a);
+
+    function setUp() public {
+        bytes memory initializingData = abi.encodeWithSelector(
+            Rollup.initialize.selector,
+            owner, // vault
+            address(daProvider),
+            address(verifier),
+            0, // confirmationPeriod
+            0, // challengePeriod
+            0, // minimumAssertionPeriod
+            1, // baseStakeAmount
+            0, // initialAssertionID
+            0, // initialInboxSize
+            startHash // initialVMhash
+        );
+        vm.startPrank(owner);
+        Rollup implementationRollup = new Rollup();
+        rollup = Rollup(address(new ERC1967Proxy(address(implementationRollup), initializingData)));
+        vm.stopPrank();
+
+        vm.deal(defender, 1 ether);
+        vm.deal(challenger, 1 ether);
+        vm.prank(defender);
+        rollup.stake{value: 1}();
+        vm.prank(challenger);
+        rollup.stake{value: 1}();
+    }
+
+    // The defender asserts `defenderHash` and the challenger a sibling, then the challenger challenges.
+    function challengeAssertion(bytes32 defenderHash) internal returns (address) {
+        vm.prank(defender);
+        rollup.createAssertion(defenderHash, 1);
+        vm.prank(challenger);
+        rollup.createAssertion(keccak256("sibling"), 1);
+        vm.prank(challenger);
+        return rollup.challengeAssertion([defender, challenger], [uint256(1), uint256(2)]);
+    }
+
+    function test_challengesAreSymmetricByDefault() public {
+        assertEq(uint256(rollup.challengeType()), uint256(IChallenge.ChallengeType.SYMMETRIC));
+        address challengeAddr = challengeAssertion(stateHash(1));
+        assertEq(uint256(IChallenge(challengeAddr).challengeType()), uint256(IChallenge.ChallengeType.SYMMETRIC));
+    }
+
+    function test_setChallengeTypeRevertsIf_callerIsNotOwner() public {
+        vm.prank(defender);
+        vm.expectRevert("Ownable: caller is not the owner");
+        rollup.setChallengeType(IChallenge.ChallengeType.ASYMMETRIC);
+        assertEq(uint256(rollup.challengeType()), uint256(IChallenge.ChallengeType.SYMMETRIC));
+    }
+
+    function test_asymChallengeCompletesThroughRollup() public {
+        vm.prank(owner);
+        vm.expectEmit(false, false, false, true);
+        emit ConfigurationChanged();
+        rollup.setChallengeType(IChallenge.ChallengeType.ASYMMETRIC);
+
+        address challengeAddr = challengeAssertion(stateHash(1));
+        AsymChallenge challenge = AsymChallenge(challengeAddr);
+        assertEq(uint256(challenge.challengeType()), uint256(IChallenge.ChallengeType.ASYMMETRIC));
+        assertEq(challenge.defender(), defender);
+        assertEq(challenge.challenger(), challenger);
+        assertEq(rollup.getStaker(defender).currentChallenge, challengeAddr);
+        assertEq(rollup.getStaker(challenger).currentChallenge, challengeAddr);
+
+        // The single step from the parent state is proven to end at the defender's state.
+        vm.prank(defender);
+        challenge.initializeChallengeLength(1);
+        VerificationContextLib.RawContext memory ctx = txContext();
+        vm.prank(challenger);
+        challenge.verifyOneStepProof("", inclusionProof(ctx), ctx, 1, pair(startHash, stateHash(1)));
+
+        assertEq(rollup.getStaker(defender).currentChallenge, address(0));
+        assertTrue(!rollup.getStaker(challenger).isStaked);
+    }
+}
@@ -280,4 +280,50 @@ contract SequencerInboxTest is SequencerBaseSetup {
         assertGt(inboxSizeFinal, inboxSizeInitial);
         assertEq(inboxSizeFinal, numTxnsPerBlock); // Since the timestamp and block.number were not included for the 2nd block, only 1st block's 3 txns are included.
     }
+
+    // Golden vectors shared with TestTxInclusionProof in clients/geth/specular/proof/tx_inclusion_test.go.
+    function test_verifyTxInclusion_goldenVector() public {
+        address goldenSequencer = address(uint160(0xa11ce));
+        bytes memory seqInInitData = abi.encodeWithSignature("initialize(address)", goldenSequencer);
+        SequencerInbox goldenInbox =
+            SequencerInbox(address(new ERC1967Proxy(address(implementationSequencer), seqInInitData)));
+
+        // Batch #0: txs 0x01, 0x0203 in block (1, 100).
+        uint256[] memory contexts = new uint256[](3);
+        (contexts[0], contexts[1], contexts[2]) = (2, 1, 100);
+        uint256[] memory txLengths = new uint256[](2);
+        (txLengths[0], txLengths[1]) = (1, 2);
+        vm.prank(goldenSequencer);
+        goldenInbox.appendTxBatch(contexts, txLengths, hex"010203");
+        assertEq(
+            goldenInbox.accumulators(0), bytes32(0xd7ac53ab717aa10baa8d028fb79ad48f36d3a232bf74411bbda6d1aa533d803c)
+        );
+
+        // Batch #1: tx 0xc0ffee in block (2, 200), txs 0xdeadbeef, 0xaa in block (3, 300).
+        contexts = new uint256[](6);
+        (contexts[0], contexts[1], contexts[2]) = (1, 2, 200);
+        (contexts[3], contexts[4], contexts[5]) = (2, 3, 300);
+        txLengths = new uint256[](3);
+        (txLengths[0], txLengths[1], txLengths[2]) = (3, 4, 1);
+        vm.prank(goldenSequencer);
+        goldenInbox.appendTxBatch(contexts, txLengths, hex"c0ffeedeadbeefaa");
+        assertEq(
+            goldenInbox.accumulators(1), bytes32(0x1a7be89f002b9690ba8013163943a14c63acbe15f26ba0cf1586d87afb7e8322)
+        );
+
+        // Proof of inclusion of 0xdeadbeef in batch #1.
+        bytes memory proof = bytes.concat(
+            hex"05c1ae3e8ccf719f7b0ce88b878969b50451d934da33d02de0697ef82fdf289c",
+            hex"0000000000000000000000000000000000000000000000000000000000000001",
+            hex"0000000000000000000000000000000000000000000000000000000000000003",
+            hex"0000000000000000000000000000000000000000000000000000000000000001",
+            hex"0f3a131e12848475fabb719e8608bb7a22b9bec0387b4e01f6f905abc1e93da9",
+            hex"05c1ae3e8ccf719f7b0ce88b878969b50451d934da33d02de0697ef82fdf289c",
+            hex"db81b4d58595fbbbb592d3661a34cdca14d7ab379441400cbfa1b78bc447c365"
+        );
+        goldenInbox.verifyTxInclusion(hex"deadbeef", proof);
+
+        vm.expectRevert(ISequencerInbox.ProofVerificationFailed.selector);
+        goldenInbox.verifyTxInclusion(hex"deadbeee", proof);
+    }
 }
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+ * Copyright 2022, Specular contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+pragma solidity ^0.8.13;
+
+import "forge-std/Test.sol";
+import {IVerifier} from "../src/challenge/verifier/IVerifier.sol";
+import {Verifier} from "../src/challenge/verifier/Verifier.sol";
+import {VerificationContextLib} from "../src/challenge/verifier/VerificationContextLib.sol";
+
+contract VerifierTest is Test {
+    Verifier internal verifier = new Verifier();
+
+    // Proofs are not decoded yet, so no end state may be returned for them (not even for an empty proof).
+    function test_verifyOneStepProofReverts(bytes32 startStateHash, bytes calldata proof) public {
+        VerificationContextLib.RawContext memory ctx;
+        vm.expectRevert(IVerifier.OneStepProofUnsupported.selector);
+        verifier.verifyOneStepProof(startStateHash, ctx, proof);
+    }
+}